@@ -1,24 +1,35 @@
 package todoist
 
 import (
+	"bytes"
 	"context"
-	"encoding/json"
-	"errors"
 	"io"
 	"net/http"
+	"strings"
 	"time"
 )
 
 const BaseUrl = "https://api.todoist.com/rest/v2/"
 
 type Todoist struct {
-	opts *Opts
+	opts    *Opts
+	handler Handler
 }
 
 type Opts struct {
-	Token   string
-	Client  *http.Client
-	Timeout time.Duration
+	Token         string
+	TokenSource   TokenSource
+	BaseUrl       string
+	SyncBaseUrl   string
+	Client        *http.Client
+	Timeout       time.Duration
+	Retry         *RetryPolicy
+	RateLimit     *RateLimit
+	RateLimiter   *RateLimiter
+	Middleware    []Middleware
+	Logger        Logger
+	RedactContent bool
+	Metrics       Metrics
 }
 
 //goland:noinspection GoUnusedExportedFunction
@@ -27,31 +38,150 @@ func New(opts *Opts) *Todoist {
 		opts.Timeout = 15 * time.Second
 	}
 
+	if opts.TokenSource == nil {
+		opts.TokenSource = StaticToken(opts.Token)
+	}
+
+	if opts.BaseUrl == "" {
+		opts.BaseUrl = BaseUrl
+	}
+
+	if opts.SyncBaseUrl == "" {
+		opts.SyncBaseUrl = SyncBaseUrl
+	}
+
 	if opts.Client == nil {
 		opts.Client = &http.Client{
 			Timeout: opts.Timeout,
 		}
 	}
 
-	return &Todoist{
+	if opts.RateLimiter == nil && opts.RateLimit != nil {
+		opts.RateLimiter = NewRateLimiter(*opts.RateLimit)
+	}
+
+	t := &Todoist{
 		opts: opts,
 	}
+
+	t.handler = t.send
+	for i := len(opts.Middleware) - 1; i >= 0; i-- {
+		t.handler = opts.Middleware[i](t.handler)
+	}
+
+	if opts.Logger != nil {
+		t.handler = logging(opts.Logger, opts.RedactContent)(t.handler)
+	}
+
+	return t
+}
+
+func (t *Todoist) request(ctx context.Context, operation string, method string, endpoint string, params map[string]string, payload []byte, data interface{}) (err error) {
+	return t.call(ctx, &Call{
+		Operation: operation,
+		Method:    method,
+		BaseUrl:   t.opts.BaseUrl,
+		Endpoint:  endpoint,
+		Params:    params,
+		Payload:   payload,
+		Header:    make(http.Header),
+	}, data)
+}
+
+func (t *Todoist) call(ctx context.Context, call *Call, data interface{}) (err error) {
+	var result *Result
+	if t.opts.Metrics != nil {
+		start := time.Now()
+		defer func() {
+			status := 0
+			if result != nil {
+				status = result.StatusCode
+			}
+			t.opts.Metrics.ObserveCall(call.Operation, status, time.Since(start), err)
+		}()
+	}
+
+	if result, err = t.handler(ctx, call); err != nil {
+		return
+	}
+
+	switch {
+	case result.StatusCode == http.StatusNoContent:
+		return
+	case result.StatusCode >= 200 && result.StatusCode < 300:
+		// 201 and 202 carry the object too; an empty body leaves data untouched.
+		return decode(call.Endpoint, result.Header, result.Body, data)
+	default:
+		// A middleware can answer with an error status and no error; report it like send does.
+		return newApiError(&http.Request{Method: call.Method, Header: call.Header}, result, call.Endpoint)
+	}
 }
 
-func (t *Todoist) request(ctx context.Context, method string, endpoint string, params map[string]string, payload io.Reader, data interface{}) (err error) {
+func (t *Todoist) send(ctx context.Context, call *Call) (result *Result, err error) {
+	attempts := 1
+	if t.opts.Retry != nil && isRetrySafe(ctx, call) {
+		attempts = t.opts.Retry.MaxAttempts
+	}
+
+	for attempt := 1; ; attempt++ {
+		if t.opts.RateLimiter != nil {
+			if err = t.opts.RateLimiter.Wait(ctx); err != nil {
+				return
+			}
+		}
+
+		result, err = t.do(ctx, call)
+		if result != nil {
+			result.Attempts = attempt
+		}
+
+		if err == nil || attempt >= attempts || !isRetryable(ctx, err) {
+			return
+		}
+
+		backoff, ok := t.opts.Retry.backoff(attempt, err)
+		if !ok {
+			return
+		}
+
+		if err = sleep(ctx, backoff); err != nil {
+			return
+		}
+	}
+}
+
+func (t *Todoist) do(ctx context.Context, call *Call) (result *Result, err error) {
+	var body io.Reader
+	if call.Payload != nil {
+		body = bytes.NewReader(call.Payload)
+	}
+
+	var token string
+	if token, err = t.opts.TokenSource.Token(ctx); err != nil {
+		return
+	}
+
 	var req *http.Request
-	if req, err = http.NewRequestWithContext(ctx, method, BaseUrl+endpoint, payload); err != nil {
+	if req, err = http.NewRequestWithContext(ctx, call.Method, joinUrl(call.BaseUrl, call.Endpoint), body); err != nil {
 		return
 	}
 
-	req.Header.Set("Authorization", "Bearer "+t.opts.Token)
-	if payload != nil {
+	req.Header.Set("Authorization", "Bearer "+token)
+	if call.Payload != nil {
 		req.Header.Set("Content-Type", "application/json")
 	}
 
-	if params != nil && len(params) != 0 {
+	if key := idempotencyKey(ctx); key != "" {
+		req.Header.Set("X-Request-Id", key)
+	}
+
+	for key, values := range call.Header {
+		req.Header[key] = values
+	}
+
+	if call.Params != nil && len(call.Params) != 0 {
 		query := req.URL.Query()
-		for key, value := range params {
+		for key, value := range call.Params {
 			query.Set(key, value)
 		}
 		req.URL.RawQuery = query.Encode()
@@ -64,20 +194,23 @@ func (t *Todoist) request(ctx context.Context, method string, endpoint string, p
 	//goland:noinspection GoUnhandledErrorResult
 	defer res.Body.Close()
 
-	switch res.StatusCode {
-	case http.StatusNoContent:
-		return
-	case http.StatusOK:
-		if res.Header.Get("Content-Type") != "application/json" {
-			return errors.New("invalid response content type")
-		}
-
-		if err = json.NewDecoder(res.Body).Decode(data); err != nil {
-			return
-		}
+	result = &Result{
+		StatusCode: res.StatusCode,
+		Status:     res.Status,
+		Header:     res.Header,
+	}
 
+	if result.Body, err = io.ReadAll(res.Body); err != nil {
 		return
-	default:
-		return errors.New(res.Status)
 	}
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		err = newApiError(req, result, call.Endpoint)
+	}
+
+	return
+}
+
+func joinUrl(base string, endpoint string) string {
+	return strings.TrimRight(base, "/") + "/" + strings.TrimLeft(endpoint, "/")
 }
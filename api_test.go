@@ -0,0 +1,243 @@
+package todoist_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	todoist "github.com/temoon/todoist-api"
+	"github.com/temoon/todoist-api/todoisttest"
+)
+
+// stub answers each request with the next queued fault, if any is left, and with the fallback otherwise.
+// While it is down, every connection is dropped.
+type stub struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	faults   []http.HandlerFunc
+	requests int
+	down     bool
+}
+
+func newStub(t *testing.T, fallback http.Handler, faults ...http.HandlerFunc) *stub {
+	s := &stub{faults: faults}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.requests++
+		var fault http.HandlerFunc
+		if s.down {
+			fault = hangUp
+		} else if len(s.faults) != 0 {
+			fault, s.faults = s.faults[0], s.faults[1:]
+		}
+		s.mu.Unlock()
+
+		if fault != nil {
+			fault(w, r)
+			return
+		}
+
+		fallback.ServeHTTP(w, r)
+	}))
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+// fail queues faults for the next requests.
+func (s *stub) fail(faults ...http.HandlerFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.faults = append(s.faults, faults...)
+}
+
+func (s *stub) setDown(down bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.down = down
+}
+
+func (s *stub) count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.requests
+}
+
+// client sends every request to the stub, whatever its host.
+func (s *stub) client() *http.Client {
+	client := s.Client()
+	client.Transport = redirect{host: s.Listener.Addr().String(), next: client.Transport}
+
+	return client
+}
+
+// opts returns the options of the fake server with the stub in front of it.
+func (s *stub) opts(srv *todoisttest.Server) *todoist.Opts {
+	opts := srv.Opts()
+	opts.Client = s.client()
+
+	return opts
+}
+
+type redirect struct {
+	host string
+	next http.RoundTripper
+}
+
+func (r redirect) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.URL.Scheme = "http"
+	req.URL.Host = r.host
+
+	return r.next.RoundTrip(req)
+}
+
+func status(code int, header ...string) http.HandlerFunc {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		for i := 0; i+1 < len(header); i += 2 {
+			w.Header().Set(header[i], header[i+1])
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(code)
+		_, _ = w.Write([]byte(`{"error": "injected", "error_tag": "INJECTED"}`))
+	}
+}
+
+func body(contentType string, data string) http.HandlerFunc {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", contentType)
+		_, _ = w.Write([]byte(data))
+	}
+}
+
+func TestApiErrorSentinels(t *testing.T) {
+	tests := []struct {
+		name   string
+		fault  http.HandlerFunc
+		want   error
+		others []error
+	}{
+		{name: "401", fault: status(http.StatusUnauthorized), want: todoist.ErrUnauthorized, others: []error{todoist.ErrForbidden, todoist.ErrServer}},
+		{name: "403", fault: status(http.StatusForbidden), want: todoist.ErrForbidden, others: []error{todoist.ErrUnauthorized}},
+		{name: "404", fault: status(http.StatusNotFound), want: todoist.ErrNotFound, others: []error{todoist.ErrServer}},
+		{name: "429", fault: status(http.StatusTooManyRequests), want: todoist.ErrRateLimited, others: []error{todoist.ErrServer}},
+		{name: "500", fault: status(http.StatusInternalServerError), want: todoist.ErrServer, others: []error{todoist.ErrNotFound}},
+		{name: "503", fault: status(http.StatusServiceUnavailable), want: todoist.ErrServer, others: []error{todoist.ErrRateLimited}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newStub(t, body("application/json", `[]`), tt.fault)
+
+			_, err := todoist.New(&todoist.Opts{Token: "token", Client: s.client()}).GetProjects(context.Background())
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("GetProjects() error = %v, want %v", err, tt.want)
+			}
+
+			for _, other := range tt.others {
+				if errors.Is(err, other) {
+					t.Errorf("GetProjects() error matches %v", other)
+				}
+			}
+
+			var apiErr *todoist.ApiError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("GetProjects() error = %T, want *ApiError", err)
+			}
+			if apiErr.Tag != "INJECTED" || apiErr.Message != "injected" || apiErr.Method != http.MethodGet {
+				t.Errorf("ApiError = %+v", apiErr)
+			}
+			if apiErr.Endpoint != todoist.ProjectsEndpoint {
+				t.Errorf("Endpoint = %q, want %q", apiErr.Endpoint, todoist.ProjectsEndpoint)
+			}
+		})
+	}
+}
+
+func TestApiErrorPlainBody(t *testing.T) {
+	s := newStub(t, nil, func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("X-Request-Id", "req-1")
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte("Invalid argument\nmore details"))
+	})
+
+	_, err := todoist.New(&todoist.Opts{Token: "token", Client: s.client()}).GetProjects(context.Background())
+
+	var apiErr *todoist.ApiError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("GetProjects() error = %v, want *ApiError", err)
+	}
+	if apiErr.StatusCode != http.StatusBadRequest || apiErr.Tag != "" || apiErr.Message != "Invalid argument" || apiErr.RequestId != "req-1" {
+		t.Errorf("ApiError = %+v", apiErr)
+	}
+	if s.count() != 1 {
+		t.Errorf("requests = %d, want 1", s.count())
+	}
+}
+
+func TestBaseUrl(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+	}{
+		{name: "with trailing slash", path: "/custom/v2/"},
+		{name: "without trailing slash", path: "/custom/v2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			s := newStub(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r.URL.Path
+				body("application/json", `[]`)(w, r)
+			}))
+
+			_, err := todoist.New(&todoist.Opts{Token: "token", BaseUrl: s.URL + tt.path, Client: s.Client()}).GetProjects(context.Background())
+			if err != nil {
+				t.Fatalf("GetProjects() error = %v", err)
+			}
+			if got != "/custom/v2/projects" {
+				t.Errorf("path = %q, want /custom/v2/projects", got)
+			}
+		})
+	}
+}
+
+func TestSuccessStatuses(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		want    int
+	}{
+		{name: "200", handler: body("application/json", `[{"id": "7"}]`), want: 1},
+		{name: "203", handler: func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusNonAuthoritativeInfo)
+			_, _ = w.Write([]byte(`[{"id": "7"}, {"id": "8"}]`))
+		}, want: 2},
+		{name: "202 empty", handler: func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusAccepted)
+		}, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newStub(t, tt.handler)
+
+			projects, err := todoist.New(&todoist.Opts{Token: "token", Client: s.client()}).GetProjects(context.Background())
+			if err != nil {
+				t.Fatalf("GetProjects() error = %v", err)
+			}
+			if len(projects) != tt.want {
+				t.Errorf("len(projects) = %d, want %d", len(projects), tt.want)
+			}
+		})
+	}
+}
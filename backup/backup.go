@@ -0,0 +1,147 @@
+// Package backup saves a Todoist account to a portable JSON archive and restores it.
+package backup
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	todoist "github.com/temoon/todoist-api"
+	"github.com/temoon/todoist-api/internal/ordering"
+)
+
+const Version = 1
+
+// Archive is a snapshot of the active objects of an account. The REST API doesn't list completed tasks,
+// so they are not part of it. Collaborators of shared projects are kept for reference, keyed by project id.
+type Archive struct {
+	Version       int                               `json:"version"`
+	CreatedAt     time.Time                         `json:"created_at"`
+	Projects      []todoist.Project                 `json:"projects"`
+	Sections      []todoist.Section                 `json:"sections"`
+	Tasks         []todoist.Task                    `json:"tasks"`
+	Labels        []todoist.Label                   `json:"labels"`
+	Comments      []todoist.Comment                 `json:"comments"`
+	Collaborators map[string][]todoist.Collaborator `json:"collaborators"`
+}
+
+// Create reads the whole account. Comments are only requested for projects and tasks that have some.
+func Create(ctx context.Context, t *todoist.Todoist) (archive *Archive, err error) {
+	archive = &Archive{
+		Version:       Version,
+		CreatedAt:     time.Now().UTC(),
+		Comments:      make([]todoist.Comment, 0),
+		Collaborators: make(map[string][]todoist.Collaborator),
+	}
+
+	if archive.Projects, err = t.GetProjects(ctx); err != nil {
+		return nil, err
+	}
+
+	if archive.Sections, err = t.GetSections(ctx, todoist.MakeGetSectionsParams()); err != nil {
+		return nil, err
+	}
+
+	if archive.Tasks, err = t.GetTasks(ctx, todoist.MakeGetTasksParams()); err != nil {
+		return nil, err
+	}
+
+	if archive.Labels, err = t.GetLabels(ctx); err != nil {
+		return nil, err
+	}
+
+	for _, project := range archive.Projects {
+		if project.CommentCount != 0 {
+			var comments []todoist.Comment
+			if comments, err = t.GetComments(ctx, todoist.MakeGetCommentsParams().WithProjectId(project.Id)); err != nil {
+				return nil, err
+			}
+			archive.Comments = append(archive.Comments, comments...)
+		}
+
+		if project.IsShared {
+			var collaborators []todoist.Collaborator
+			if collaborators, err = t.GetCollaborators(ctx, project.Id); err != nil {
+				return nil, err
+			}
+			archive.Collaborators[project.Id] = collaborators
+		}
+	}
+
+	for _, task := range archive.Tasks {
+		if task.CommentCount != 0 {
+			var comments []todoist.Comment
+			if comments, err = t.GetComments(ctx, todoist.MakeGetCommentsParams().WithTaskId(task.Id)); err != nil {
+				return nil, err
+			}
+			archive.Comments = append(archive.Comments, comments...)
+		}
+	}
+
+	archive.sort()
+
+	return
+}
+
+// Write encodes the archive as indented JSON.
+func Write(w io.Writer, archive *Archive) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(archive)
+}
+
+func Read(r io.Reader) (archive *Archive, err error) {
+	archive = new(Archive)
+	if err = json.NewDecoder(r).Decode(archive); err != nil {
+		return nil, err
+	}
+
+	if archive.Version != Version {
+		return nil, fmt.Errorf("unsupported archive version %d", archive.Version)
+	}
+
+	return
+}
+
+// sorted returns a copy of the archive with its objects ordered by sort.
+func (a *Archive) sorted() *Archive {
+	sorted := *a
+	sorted.Projects = append([]todoist.Project(nil), a.Projects...)
+	sorted.Sections = append([]todoist.Section(nil), a.Sections...)
+	sorted.Tasks = append([]todoist.Task(nil), a.Tasks...)
+	sorted.Labels = append([]todoist.Label(nil), a.Labels...)
+	sorted.Comments = append([]todoist.Comment(nil), a.Comments...)
+	sorted.sort()
+
+	return &sorted
+}
+
+// sort orders objects the way they are restored: by order within their parent, then by id.
+func (a *Archive) sort() {
+	sort.SliceStable(a.Projects, func(i, j int) bool {
+		return ordering.LessOrder(a.Projects[i].Order, a.Projects[i].Id, a.Projects[j].Order, a.Projects[j].Id)
+	})
+
+	sort.SliceStable(a.Sections, func(i, j int) bool {
+		return ordering.LessOrder(a.Sections[i].Order, a.Sections[i].Id, a.Sections[j].Order, a.Sections[j].Id)
+	})
+
+	sort.SliceStable(a.Tasks, func(i, j int) bool {
+		return ordering.LessOrder(a.Tasks[i].Order, a.Tasks[i].Id, a.Tasks[j].Order, a.Tasks[j].Id)
+	})
+
+	sort.SliceStable(a.Labels, func(i, j int) bool {
+		return ordering.LessOrder(a.Labels[i].Order, a.Labels[i].Id, a.Labels[j].Order, a.Labels[j].Id)
+	})
+
+	sort.SliceStable(a.Comments, func(i, j int) bool {
+		if a.Comments[i].PostedAt != a.Comments[j].PostedAt {
+			return a.Comments[i].PostedAt < a.Comments[j].PostedAt
+		}
+		return ordering.LessId(a.Comments[i].Id, a.Comments[j].Id)
+	})
+}
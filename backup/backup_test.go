@@ -0,0 +1,309 @@
+package backup_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	todoist "github.com/temoon/todoist-api"
+	"github.com/temoon/todoist-api/backup"
+	"github.com/temoon/todoist-api/todoisttest"
+)
+
+func TestRoundTrip(t *testing.T) {
+	ctx := context.Background()
+
+	source := todoisttest.NewServer()
+	defer source.Close()
+
+	populate(t, source.Todoist())
+
+	archive, err := backup.Create(ctx, source.Todoist())
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err = backup.Write(&buf, archive); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	if archive, err = backup.Read(&buf); err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+
+	want := outline(archive, "")
+
+	tests := []struct {
+		name string
+		opts *backup.RestoreOpts
+		root string
+	}{
+		{name: "top level"},
+		{name: "prefix", opts: &backup.RestoreOpts{Prefix: "Restored"}, root: "Restored"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := todoisttest.NewServer()
+			defer target.Close()
+
+			report, err := backup.Restore(ctx, target.Todoist(), archive, tt.opts)
+			if err != nil {
+				t.Fatalf("Restore() error = %v", err)
+			}
+			if len(report.Failures) != 0 {
+				t.Fatalf("Restore() failures = %+v", report.Failures)
+			}
+
+			restored, err := backup.Create(ctx, target.Todoist())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := outline(restored, tt.root)
+			if strings.Join(got, "\n") != strings.Join(want, "\n") {
+				t.Errorf("restored account:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+			}
+		})
+	}
+}
+
+func TestRestoreFailures(t *testing.T) {
+	archive := &backup.Archive{
+		Version: backup.Version,
+		Projects: []todoist.Project{
+			{Id: "p3", Name: "Child", ParentId: "p2", Order: 1},
+			{Id: "p2", Name: "Loop", ParentId: "p1", Order: 2},
+			{Id: "p1", Name: "Parent", ParentId: "p2", Order: 3},
+		},
+		Tasks: []todoist.Task{
+			{Id: "t3", ProjectId: "p3", Content: "Subtask", ParentId: "t2", Order: 1, Priority: 1},
+			{Id: "t2", ProjectId: "missing", Content: "Orphan", Order: 2, Priority: 1},
+			{Id: "t1", ProjectId: "p1", Content: "Task", Order: 3, Priority: 1},
+		},
+		Comments: []todoist.Comment{
+			{Id: "c2", TaskId: "t2", Content: "On the orphan"},
+			{Id: "c1", TaskId: "t1", Content: "On the task"},
+		},
+	}
+
+	before := copyArchive(t, archive)
+
+	srv := todoisttest.NewServer()
+	defer srv.Close()
+
+	report, err := backup.Restore(context.Background(), srv.Todoist(), archive, nil)
+	if err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+
+	if !reflect.DeepEqual(archive, before) {
+		t.Errorf("Restore() changed the archive")
+	}
+
+	tests := []struct {
+		kind string
+		id   string
+		err  error
+	}{
+		{kind: "task", id: "t2", err: backup.ErrProjectNotRestored},
+		{kind: "comment", id: "c2", err: backup.ErrTargetNotRestored},
+	}
+
+	if len(report.Failures) != len(tests) {
+		t.Fatalf("Failures = %+v, want %d", report.Failures, len(tests))
+	}
+	for i, tt := range tests {
+		failure := report.Failures[i]
+		if failure.Kind != tt.kind || failure.Id != tt.id || !errors.Is(failure.Err, tt.err) {
+			t.Errorf("failure %d = %+v, want %s %s %v", i, failure, tt.kind, tt.id, tt.err)
+		}
+	}
+
+	for _, id := range []string{"p1", "p2", "p3", "t1", "t3", "c1"} {
+		if report.Ids[id] == "" {
+			t.Errorf("%s was not restored", id)
+		}
+	}
+
+	subtask, err := srv.Todoist().GetTask(context.Background(), report.Ids["t3"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if subtask.ParentId != "" || subtask.ProjectId != report.Ids["p3"] {
+		t.Errorf("subtask of a failed task = %+v, want it at the top of its project", subtask)
+	}
+}
+
+func TestRead(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr bool
+	}{
+		{name: "current version", data: `{"version": 1, "projects": [{"id": "1", "name": "Inbox"}]}`},
+		{name: "other version", data: `{"version": 2}`, wantErr: true},
+		{name: "no version", data: `{}`, wantErr: true},
+		{name: "not json", data: `version: 1`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			archive, err := backup.Read(strings.NewReader(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Read() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && len(archive.Projects) != 1 {
+				t.Errorf("Read() projects = %+v", archive.Projects)
+			}
+		})
+	}
+}
+
+func populate(t *testing.T, client *todoist.Todoist) {
+	t.Helper()
+
+	ctx := context.Background()
+	must := func(err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	_, err := client.AddLabel(ctx, todoist.MakeAddLabelParams().WithName("errand").WithColor("red"))
+	must(err)
+
+	work, err := client.AddProject(ctx, todoist.MakeAddProjectParams().WithName("Work").WithColor("blue"))
+	must(err)
+	office, err := client.AddProject(ctx, todoist.MakeAddProjectParams().WithName("Office").WithParentId(work.Id))
+	must(err)
+	_, err = client.AddProject(ctx, todoist.MakeAddProjectParams().WithName("Home").WithFavorite(true))
+	must(err)
+
+	drafts, err := client.AddSection(ctx, todoist.MakeAddSectionParams().WithName("Drafts").WithProjectId(office.Id))
+	must(err)
+
+	report, err := client.AddTask(ctx, todoist.MakeAddTaskParams().WithContent("Write report").WithProjectId(office.Id).
+		WithSectionId(drafts.Id).WithPriority(4).WithDueDate("2024-03-15"))
+	must(err)
+	_, err = client.AddTask(ctx, todoist.MakeAddTaskParams().WithContent("Collect numbers").WithParentId(report.Id).
+		WithDueDatetime("2024-03-14T09:00:00Z"))
+	must(err)
+	_, err = client.AddTask(ctx, todoist.MakeAddTaskParams().WithContent("Buy milk").WithLabels([]string{"errand"}).
+		WithDueString("every mon"))
+	must(err)
+
+	_, err = client.AddComment(ctx, todoist.MakeAddCommentParams().WithTaskId(report.Id).WithContent("Use the new template"))
+	must(err)
+	_, err = client.AddComment(ctx, todoist.MakeAddCommentParams().WithProjectId(work.Id).WithContent("Quarterly goals"))
+	must(err)
+}
+
+// outline describes an archive by names, which survive a restore, unlike ids. With a root, only the
+// objects under the top level project of that name are described, relative to it.
+func outline(archive *backup.Archive, root string) (lines []string) {
+	names := make(map[string]string)
+	for _, project := range archive.Projects {
+		names[project.Id] = project.Name
+	}
+	for _, section := range archive.Sections {
+		names[section.Id] = section.Name
+	}
+	for _, task := range archive.Tasks {
+		names[task.Id] = task.Content
+	}
+
+	path := func(projectId string) (string, bool) {
+		var parts []string
+		for projectId != "" {
+			project := findProject(archive.Projects, projectId)
+			parts = append([]string{project.Name}, parts...)
+			projectId = project.ParentId
+		}
+
+		if root == "" {
+			return strings.Join(parts, "/"), true
+		}
+		if len(parts) < 2 || parts[0] != root {
+			return "", false
+		}
+
+		return strings.Join(parts[1:], "/"), true
+	}
+
+	for _, project := range archive.Projects {
+		if path, ok := path(project.Id); ok {
+			lines = append(lines, fmt.Sprintf("project %s %s favorite=%t", path, project.Color, project.IsFavorite))
+		}
+	}
+
+	for _, section := range archive.Sections {
+		if path, ok := path(section.ProjectId); ok {
+			lines = append(lines, fmt.Sprintf("section %s/%s", path, section.Name))
+		}
+	}
+
+	for _, task := range archive.Tasks {
+		path, ok := path(task.ProjectId)
+		if !ok {
+			continue
+		}
+
+		due := ""
+		if task.Due != nil {
+			due = task.Due.String + "|" + task.Due.Date + "|" + task.Due.Datetime
+			if task.Due.IsRecurring {
+				// The date of a recurring due is computed from the clock of the server.
+				due = task.Due.String
+			}
+		}
+
+		lines = append(lines, fmt.Sprintf("task %s/%s/%s/%s p%d %s %s",
+			path, names[task.SectionId], names[task.ParentId], task.Content, task.Priority, strings.Join(task.Labels, ","), due))
+	}
+
+	for _, comment := range archive.Comments {
+		target := "project " + names[comment.ProjectId]
+		if comment.TaskId != "" {
+			target = "task " + names[comment.TaskId]
+		}
+		lines = append(lines, "comment "+target+": "+comment.Content)
+	}
+
+	sort.Strings(lines)
+
+	return
+}
+
+func findProject(projects []todoist.Project, id string) todoist.Project {
+	for _, project := range projects {
+		if project.Id == id {
+			return project
+		}
+	}
+
+	return todoist.Project{}
+}
+
+func copyArchive(t *testing.T, archive *backup.Archive) *backup.Archive {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := backup.Write(&buf, archive); err != nil {
+		t.Fatal(err)
+	}
+
+	copied, err := backup.Read(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return copied
+}
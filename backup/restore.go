@@ -0,0 +1,396 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	todoist "github.com/temoon/todoist-api"
+)
+
+var (
+	ErrProjectNotRestored = errors.New("project was not restored")
+	ErrTargetNotRestored  = errors.New("task or project of the comment was not restored")
+)
+
+type RestoreOpts struct {
+	// Prefix is the name of a project created to hold the restored projects. Without it,
+	// projects are restored at the top level and the inbox is merged into the existing one.
+	Prefix string
+}
+
+type Failure struct {
+	Kind string
+	Id   string
+	Name string
+	Err  error
+}
+
+// RestoreReport maps the ids of the archive to the ids of the restored objects and lists the objects
+// that couldn't be restored. Collaborators and assignees are never restored, they need invitations.
+type RestoreReport struct {
+	Ids      map[string]string
+	Failures []Failure
+}
+
+type restorer struct {
+	t      *todoist.Todoist
+	report *RestoreReport
+
+	projects map[string]todoist.Project
+	tasks    map[string]todoist.Task
+	failed   map[string]bool
+	visiting map[string]bool
+}
+
+// Restore recreates the archive, parents before children and siblings in their order. Subprojects and
+// subtasks of objects that failed are attached to their nearest restored ancestor instead, or to the top
+// level when there is none. It only fails as a whole when ctx is done or the account can't be read;
+// other errors end up in the report. The archive is left unchanged.
+func Restore(ctx context.Context, t *todoist.Todoist, archive *Archive, opts *RestoreOpts) (report *RestoreReport, err error) {
+	if opts == nil {
+		opts = &RestoreOpts{}
+	}
+
+	archive = archive.sorted()
+
+	r := &restorer{
+		t: t,
+		report: &RestoreReport{
+			Ids: make(map[string]string),
+		},
+		projects: make(map[string]todoist.Project),
+		tasks:    make(map[string]todoist.Task),
+		failed:   make(map[string]bool),
+		visiting: make(map[string]bool),
+	}
+
+	if err = r.labels(ctx, archive.Labels); err != nil {
+		return
+	}
+
+	if err = r.projectsUnder(ctx, archive.Projects, opts.Prefix); err != nil {
+		return
+	}
+
+	if err = r.sections(ctx, archive.Sections); err != nil {
+		return
+	}
+
+	if err = r.tasksInOrder(ctx, archive.Tasks); err != nil {
+		return
+	}
+
+	if err = r.comments(ctx, archive.Comments); err != nil {
+		return
+	}
+
+	return r.report, nil
+}
+
+// labels reuses labels that already exist with the same name.
+func (r *restorer) labels(ctx context.Context, labels []todoist.Label) (err error) {
+	var existing []todoist.Label
+	if existing, err = r.t.GetLabels(ctx); err != nil {
+		return
+	}
+
+	names := make(map[string]string)
+	for _, label := range existing {
+		names[label.Name] = label.Id
+	}
+
+	for _, label := range labels {
+		if id, ok := names[label.Name]; ok {
+			r.report.Ids[label.Id] = id
+			continue
+		}
+
+		params := todoist.MakeAddLabelParams().
+			WithName(label.Name).
+			WithColor(label.Color).
+			WithOrder(label.Order).
+			WithFavorite(label.IsFavorite)
+
+		var created *todoist.Label
+		if created, err = r.t.AddLabel(ctx, params); err != nil {
+			if err = r.fail(ctx, "label", label.Id, label.Name, err); err != nil {
+				return
+			}
+			continue
+		}
+
+		r.report.Ids[label.Id] = created.Id
+	}
+
+	return
+}
+
+func (r *restorer) projectsUnder(ctx context.Context, projects []todoist.Project, prefix string) (err error) {
+	var existing []todoist.Project
+	if existing, err = r.t.GetProjects(ctx); err != nil {
+		return
+	}
+
+	var root string
+	if prefix != "" {
+		var created *todoist.Project
+		if created, err = r.t.AddProject(ctx, todoist.MakeAddProjectParams().WithName(prefix)); err != nil {
+			return
+		}
+		root = created.Id
+	}
+
+	for _, project := range projects {
+		r.projects[project.Id] = project
+	}
+
+	for _, project := range projects {
+		if root == "" && (project.IsInboxProject || project.IsTeamInbox) {
+			for _, inbox := range existing {
+				if inbox.IsInboxProject == project.IsInboxProject && inbox.IsTeamInbox == project.IsTeamInbox {
+					r.report.Ids[project.Id] = inbox.Id
+				}
+			}
+		}
+	}
+
+	for _, project := range projects {
+		if err = r.project(ctx, project, root); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// project restores the parent first, so that children can refer to it. A cycle of parents
+// in a damaged archive is broken at the project that closes it.
+func (r *restorer) project(ctx context.Context, project todoist.Project, root string) (err error) {
+	if r.report.Ids[project.Id] != "" || r.failed[project.Id] || r.visiting[project.Id] {
+		return
+	}
+
+	r.visiting[project.Id] = true
+	defer delete(r.visiting, project.Id)
+
+	parentId := root
+	if parent, ok := r.projects[project.ParentId]; ok {
+		if err = r.project(ctx, parent, root); err != nil {
+			return
+		}
+		if id := r.restoredProject(parent.Id); id != "" {
+			parentId = id
+		}
+	}
+
+	params := todoist.MakeAddProjectParams().
+		WithName(project.Name).
+		WithParentId(parentId).
+		WithColor(project.Color).
+		WithFavorite(project.IsFavorite)
+
+	var created *todoist.Project
+	if created, err = r.t.AddProject(ctx, params); err != nil {
+		return r.fail(ctx, "project", project.Id, project.Name, err)
+	}
+
+	r.report.Ids[project.Id] = created.Id
+
+	return
+}
+
+func (r *restorer) sections(ctx context.Context, sections []todoist.Section) (err error) {
+	for _, section := range sections {
+		projectId := r.report.Ids[section.ProjectId]
+		if projectId == "" {
+			if err = r.fail(ctx, "section", section.Id, section.Name, ErrProjectNotRestored); err != nil {
+				return
+			}
+			continue
+		}
+
+		params := todoist.MakeAddSectionParams().
+			WithName(section.Name).
+			WithProjectId(projectId).
+			WithOrder(section.Order)
+
+		var created *todoist.Section
+		if created, err = r.t.AddSection(ctx, params); err != nil {
+			if err = r.fail(ctx, "section", section.Id, section.Name, err); err != nil {
+				return
+			}
+			continue
+		}
+
+		r.report.Ids[section.Id] = created.Id
+	}
+
+	return
+}
+
+func (r *restorer) tasksInOrder(ctx context.Context, tasks []todoist.Task) (err error) {
+	for _, task := range tasks {
+		r.tasks[task.Id] = task
+	}
+
+	for _, task := range tasks {
+		if err = r.task(ctx, task); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+func (r *restorer) task(ctx context.Context, task todoist.Task) (err error) {
+	if r.report.Ids[task.Id] != "" || r.failed[task.Id] || r.visiting[task.Id] {
+		return
+	}
+
+	r.visiting[task.Id] = true
+	defer delete(r.visiting, task.Id)
+
+	projectId := r.report.Ids[task.ProjectId]
+	if projectId == "" {
+		return r.fail(ctx, "task", task.Id, task.Content, ErrProjectNotRestored)
+	}
+
+	params := todoist.MakeAddTaskParams().
+		WithContent(task.Content).
+		WithDescription(task.Description).
+		WithProjectId(projectId).
+		WithSectionId(r.report.Ids[task.SectionId]).
+		WithOrder(task.Order).
+		WithPriority(task.Priority)
+
+	if parent, ok := r.tasks[task.ParentId]; ok {
+		if err = r.task(ctx, parent); err != nil {
+			return
+		}
+		params.WithParentId(r.restoredTask(parent.Id))
+	}
+
+	if len(task.Labels) != 0 {
+		params.WithLabels(task.Labels)
+	}
+
+	withDue(params, task.Due)
+
+	var created *todoist.Task
+	if created, err = r.t.AddTask(ctx, params); err != nil {
+		return r.fail(ctx, "task", task.Id, task.Content, err)
+	}
+
+	r.report.Ids[task.Id] = created.Id
+
+	return
+}
+
+// restoredProject returns the new id of the project or of its nearest restored ancestor.
+func (r *restorer) restoredProject(id string) string {
+	for i := 0; i <= len(r.projects); i++ {
+		if restored := r.report.Ids[id]; restored != "" {
+			return restored
+		}
+
+		project, ok := r.projects[id]
+		if !ok {
+			break
+		}
+		id = project.ParentId
+	}
+
+	return ""
+}
+
+// restoredTask returns the new id of the task or of its nearest restored ancestor in the same project.
+func (r *restorer) restoredTask(id string) string {
+	projectId := r.tasks[id].ProjectId
+	for i := 0; i <= len(r.tasks); i++ {
+		task, ok := r.tasks[id]
+		if !ok || task.ProjectId != projectId {
+			break
+		}
+
+		if restored := r.report.Ids[id]; restored != "" {
+			return restored
+		}
+		id = task.ParentId
+	}
+
+	return ""
+}
+
+func (r *restorer) comments(ctx context.Context, comments []todoist.Comment) (err error) {
+	for _, comment := range comments {
+		params := todoist.MakeAddCommentParams().
+			WithContent(comment.Content)
+
+		if comment.Attachment != nil {
+			params.WithAttachment(comment.Attachment)
+		}
+
+		if comment.TaskId != "" {
+			params.WithTaskId(r.report.Ids[comment.TaskId])
+		} else {
+			params.WithProjectId(r.report.Ids[comment.ProjectId])
+		}
+
+		if (*params)["task_id"] == nil && (*params)["project_id"] == nil {
+			if err = r.fail(ctx, "comment", comment.Id, "", ErrTargetNotRestored); err != nil {
+				return
+			}
+			continue
+		}
+
+		var created *todoist.Comment
+		if created, err = r.t.AddComment(ctx, params); err != nil {
+			if err = r.fail(ctx, "comment", comment.Id, "", err); err != nil {
+				return
+			}
+			continue
+		}
+
+		r.report.Ids[comment.Id] = created.Id
+	}
+
+	return
+}
+
+// fail records a failure. It returns an error only when ctx is done, which stops the restore.
+func (r *restorer) fail(ctx context.Context, kind string, id string, name string, err error) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	r.failed[id] = true
+	r.report.Failures = append(r.report.Failures, Failure{
+		Kind: kind,
+		Id:   id,
+		Name: name,
+		Err:  err,
+	})
+
+	return nil
+}
+
+// withDue keeps recurring dues recurring and fixed datetimes at their instant. Floating datetimes
+// have no REST parameter and are sent as due strings.
+func withDue(params *todoist.AddTaskParams, due *todoist.Due) {
+	switch {
+	case due == nil:
+	case due.IsRecurring:
+		params.WithDueString(due.String)
+	case due.IsAllDay():
+		params.WithDueDate(due.Date)
+	case due.IsFloating():
+		if t, err := due.Time(time.UTC); err == nil {
+			params.WithDueString(t.Format("2006-01-02 15:04"))
+		}
+	default:
+		if t, err := due.Time(time.UTC); err == nil {
+			params.WithDueDatetime(t.Format(time.RFC3339))
+		}
+	}
+}
@@ -0,0 +1,398 @@
+package todoist
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+const MaxCommandsPerRequest = 100
+
+var ErrNoCommandStatus = errors.New("sync response has no status for the command")
+
+type Command struct {
+	Type   string                 `json:"type"`
+	Uuid   string                 `json:"uuid"`
+	TempId string                 `json:"temp_id,omitempty"`
+	Args   map[string]interface{} `json:"args"`
+}
+
+type CommandError struct {
+	Uuid     string                 `json:"-"`
+	Type     string                 `json:"-"`
+	Code     int                    `json:"error_code"`
+	Tag      string                 `json:"error_tag"`
+	Message  string                 `json:"error"`
+	HttpCode int                    `json:"http_code"`
+	Extra    map[string]interface{} `json:"error_extra"`
+
+	err error
+}
+
+func (e *CommandError) Error() string {
+	msg := e.Type + " " + e.Uuid + ": " + e.Message
+	if e.Tag != "" {
+		msg += " (" + e.Tag + ")"
+	}
+
+	return msg
+}
+
+func (e *CommandError) Is(target error) bool {
+	return (&ApiError{StatusCode: e.HttpCode}).Is(target)
+}
+
+func (e *CommandError) Unwrap() error {
+	return e.err
+}
+
+type CommandResult struct {
+	Uuid   string
+	Type   string
+	TempId string
+	Id     string
+	Err    *CommandError
+}
+
+type CommitResult struct {
+	SyncToken     string
+	TempIdMapping map[string]string
+	Results       []CommandResult
+}
+
+// Id returns the real id for a temp id, or the argument itself if it isn't a known temp id.
+func (r *CommitResult) Id(tempId string) string {
+	if id, ok := r.TempIdMapping[tempId]; ok {
+		return id
+	}
+
+	return tempId
+}
+
+func (r *CommitResult) Errors() (errs []*CommandError) {
+	for _, result := range r.Results {
+		if result.Err != nil {
+			errs = append(errs, result.Err)
+		}
+	}
+
+	return
+}
+
+// CommandBatch queues Sync API commands. Methods that create objects return a temp id,
+// which later commands in the same batch may use in place of a real id.
+type CommandBatch struct {
+	commands []Command
+}
+
+//goland:noinspection GoUnusedExportedFunction
+func NewCommandBatch() *CommandBatch {
+	return &CommandBatch{}
+}
+
+func (b *CommandBatch) Len() int {
+	return len(b.commands)
+}
+
+func (b *CommandBatch) Commands() []Command {
+	return b.commands
+}
+
+// Add queues a raw command and returns its uuid. Pass a non-empty temp id for commands that create objects.
+func (b *CommandBatch) Add(commandType string, tempId string, args map[string]interface{}) string {
+	if args == nil {
+		args = make(map[string]interface{})
+	}
+
+	command := Command{
+		Type:   commandType,
+		Uuid:   NewUuid(),
+		TempId: tempId,
+		Args:   args,
+	}
+	b.commands = append(b.commands, command)
+
+	return command.Uuid
+}
+
+// region Projects
+
+func (b *CommandBatch) AddProject(params *AddProjectParams) (tempId string) {
+	tempId = NewUuid()
+	b.Add("project_add", tempId, copyArgs(*params, nil))
+
+	return
+}
+
+func (b *CommandBatch) UpdateProject(projectId string, params *UpdateProjectParams) string {
+	return b.Add("project_update", "", withId(projectId, copyArgs(*params, nil)))
+}
+
+func (b *CommandBatch) DeleteProject(projectId string) string {
+	return b.Add("project_delete", "", withId(projectId, nil))
+}
+
+// endregion
+
+// region Sections
+
+func (b *CommandBatch) AddSection(params *AddSectionParams) (tempId string) {
+	tempId = NewUuid()
+	b.Add("section_add", tempId, copyArgs(*params, map[string]string{"order": "section_order"}))
+
+	return
+}
+
+func (b *CommandBatch) UpdateSection(sectionId string, params *UpdateSectionParams) string {
+	return b.Add("section_update", "", withId(sectionId, copyArgs(*params, nil)))
+}
+
+func (b *CommandBatch) DeleteSection(sectionId string) string {
+	return b.Add("section_delete", "", withId(sectionId, nil))
+}
+
+// endregion
+
+// region Tasks
+
+var taskArgs = map[string]string{
+	"order":       "child_order",
+	"assignee_id": "responsible_uid",
+	"label_ids":   "labels",
+}
+
+func (b *CommandBatch) AddTask(params *AddTaskParams) (tempId string) {
+	tempId = NewUuid()
+	b.Add("item_add", tempId, dueArgs(copyArgs(*params, taskArgs)))
+
+	return
+}
+
+func (b *CommandBatch) UpdateTask(taskId string, params *UpdateTaskParams) string {
+	return b.Add("item_update", "", withId(taskId, dueArgs(copyArgs(*params, taskArgs))))
+}
+
+func (b *CommandBatch) CloseTask(taskId string) string {
+	return b.Add("item_close", "", withId(taskId, nil))
+}
+
+func (b *CommandBatch) ReopenTask(taskId string) string {
+	return b.Add("item_uncomplete", "", withId(taskId, nil))
+}
+
+func (b *CommandBatch) DeleteTask(taskId string) string {
+	return b.Add("item_delete", "", withId(taskId, nil))
+}
+
+// endregion
+
+// region Labels
+
+func (b *CommandBatch) AddLabel(params *AddLabelParams) (tempId string) {
+	tempId = NewUuid()
+	b.Add("label_add", tempId, copyArgs(*params, map[string]string{"order": "item_order"}))
+
+	return
+}
+
+func (b *CommandBatch) UpdateLabel(labelId string, params *UpdateLabelParams) string {
+	return b.Add("label_update", "", withId(labelId, copyArgs(*params, map[string]string{"order": "item_order"})))
+}
+
+func (b *CommandBatch) DeleteLabel(labelId string) string {
+	return b.Add("label_delete", "", withId(labelId, nil))
+}
+
+// endregion
+
+// region Comments
+
+func (b *CommandBatch) AddComment(params *AddCommentParams) (tempId string) {
+	tempId = NewUuid()
+	b.Add("note_add", tempId, copyArgs(*params, map[string]string{"task_id": "item_id", "attachment": "file_attachment"}))
+
+	return
+}
+
+func (b *CommandBatch) UpdateComment(commentId string, params *UpdateCommentParams) string {
+	return b.Add("note_update", "", withId(commentId, copyArgs(*params, nil)))
+}
+
+func (b *CommandBatch) DeleteComment(commentId string) string {
+	return b.Add("note_delete", "", withId(commentId, nil))
+}
+
+// endregion
+
+// region Commit
+
+func (p *SyncParams) WithCommands(commands []Command) *SyncParams {
+	if commands != nil && len(commands) != 0 {
+		encoded, _ := json.Marshal(commands)
+		(*p)["commands"] = string(encoded)
+	}
+
+	return p
+}
+
+// Commit sends the batch in chunks of MaxCommandsPerRequest commands. Temp ids created in earlier
+// chunks are replaced with real ids in later ones. Command failures are reported in the result,
+// the returned error is only set when a request fails as a whole.
+func (t *Todoist) Commit(ctx context.Context, batch *CommandBatch) (res *CommitResult, err error) {
+	res = &CommitResult{
+		TempIdMapping: make(map[string]string),
+	}
+
+	for start := 0; start < len(batch.commands); start += MaxCommandsPerRequest {
+		end := start + MaxCommandsPerRequest
+		if end > len(batch.commands) {
+			end = len(batch.commands)
+		}
+
+		chunk := make([]Command, 0, end-start)
+		for _, command := range batch.commands[start:end] {
+			command.Args = resolveTempIds(command.Args, res.TempIdMapping).(map[string]interface{})
+			chunk = append(chunk, command)
+		}
+
+		var syncRes *SyncResponse
+		if syncRes, err = t.Sync(ctx, MakeSyncParams().WithCommands(chunk)); err != nil {
+			return
+		}
+
+		res.SyncToken = syncRes.SyncToken
+		for tempId, id := range syncRes.TempIdMapping {
+			res.TempIdMapping[tempId] = id
+		}
+
+		for _, command := range chunk {
+			result := CommandResult{
+				Uuid:   command.Uuid,
+				Type:   command.Type,
+				TempId: command.TempId,
+				Id:     res.TempIdMapping[command.TempId],
+			}
+
+			if result.Err, err = commandError(command, syncRes.SyncStatus[command.Uuid]); err != nil {
+				return
+			}
+
+			res.Results = append(res.Results, result)
+		}
+	}
+
+	return
+}
+
+func commandError(command Command, status json.RawMessage) (*CommandError, error) {
+	// Without a status there is no telling whether the command was applied.
+	if len(status) == 0 {
+		return &CommandError{Uuid: command.Uuid, Type: command.Type, Message: ErrNoCommandStatus.Error(), err: ErrNoCommandStatus}, nil
+	}
+
+	var ok string
+	if json.Unmarshal(status, &ok) == nil {
+		return nil, nil
+	}
+
+	e := &CommandError{
+		Uuid: command.Uuid,
+		Type: command.Type,
+	}
+	if err := json.Unmarshal(status, e); err != nil {
+		return nil, &DecodeError{Endpoint: SyncEndpoint, Snippet: snippet(status), Err: err}
+	}
+
+	if e.HttpCode == 0 {
+		e.HttpCode = http.StatusBadRequest
+	}
+
+	return e, nil
+}
+
+// endregion
+
+//goland:noinspection GoUnusedExportedFunction
+func NewUuid() string {
+	var buf [16]byte
+	_, _ = rand.Read(buf[:])
+	buf[6] = buf[6]&0x0f | 0x40
+	buf[8] = buf[8]&0x3f | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", buf[0:4], buf[4:6], buf[6:8], buf[8:10], buf[10:16])
+}
+
+func copyArgs(params map[string]interface{}, rename map[string]string) map[string]interface{} {
+	args := make(map[string]interface{}, len(params))
+	for key, value := range params {
+		if renamed, ok := rename[key]; ok {
+			key = renamed
+		}
+		args[key] = value
+	}
+
+	return args
+}
+
+func withId(id string, args map[string]interface{}) map[string]interface{} {
+	if args == nil {
+		args = make(map[string]interface{})
+	}
+	args["id"] = id
+
+	return args
+}
+
+// dueArgs converts the REST due_* parameters into the Sync API due object.
+func dueArgs(args map[string]interface{}) map[string]interface{} {
+	due := make(map[string]interface{})
+	for key, dueKey := range map[string]string{
+		"due_string":   "string",
+		"due_date":     "date",
+		"due_datetime": "date",
+		"due_lang":     "lang",
+	} {
+		if value, ok := args[key]; ok {
+			due[dueKey] = value
+			delete(args, key)
+		}
+	}
+
+	if len(due) != 0 {
+		args["due"] = due
+	}
+
+	return args
+}
+
+func resolveTempIds(value interface{}, mapping map[string]string) interface{} {
+	switch value := value.(type) {
+	case string:
+		if id, ok := mapping[value]; ok {
+			return id
+		}
+	case []string:
+		resolved := make([]string, len(value))
+		for i, item := range value {
+			resolved[i] = resolveTempIds(item, mapping).(string)
+		}
+		return resolved
+	case []interface{}:
+		resolved := make([]interface{}, len(value))
+		for i, item := range value {
+			resolved[i] = resolveTempIds(item, mapping)
+		}
+		return resolved
+	case map[string]interface{}:
+		resolved := make(map[string]interface{}, len(value))
+		for key, item := range value {
+			resolved[key] = resolveTempIds(item, mapping)
+		}
+		return resolved
+	}
+
+	return value
+}
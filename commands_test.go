@@ -0,0 +1,143 @@
+package todoist_test
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	todoist "github.com/temoon/todoist-api"
+)
+
+func TestCommitTempIds(t *testing.T) {
+	tests := []struct {
+		name  string
+		tasks int
+	}{
+		{name: "single request", tasks: 3},
+		{name: "temp ids across chunks", tasks: todoist.MaxCommandsPerRequest + 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newServer(t)
+			client := srv.Todoist()
+			ctx := context.Background()
+
+			batch := todoist.NewCommandBatch()
+			projectId := batch.AddProject(todoist.MakeAddProjectParams().WithName("Groceries"))
+
+			tempIds := make([]string, 0, tt.tasks)
+			for i := 0; i < tt.tasks; i++ {
+				params := todoist.MakeAddTaskParams().WithContent("Task " + strconv.Itoa(i)).WithProjectId(projectId)
+				tempIds = append(tempIds, batch.AddTask(params))
+			}
+			batch.CloseTask(tempIds[len(tempIds)-1])
+
+			res, err := client.Commit(ctx, batch)
+			if err != nil {
+				t.Fatalf("Commit() error = %v", err)
+			}
+			if errs := res.Errors(); len(errs) != 0 {
+				t.Fatalf("Errors() = %v", errs)
+			}
+			if len(res.Results) != batch.Len() {
+				t.Errorf("len(Results) = %d, want %d", len(res.Results), batch.Len())
+			}
+
+			realProjectId := res.Id(projectId)
+			if realProjectId == projectId {
+				t.Fatalf("Id(%q) was not mapped", projectId)
+			}
+
+			tasks, err := client.GetTasks(ctx, todoist.MakeGetTasksParams().WithProjectId(realProjectId))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(tasks) != tt.tasks-1 {
+				t.Errorf("open tasks in project = %d, want %d", len(tasks), tt.tasks-1)
+			}
+
+			for i, tempId := range tempIds {
+				if res.Id(tempId) == tempId {
+					t.Errorf("Id() of task %d was not mapped", i)
+				}
+			}
+
+			if id := res.Id("not-a-temp-id"); id != "not-a-temp-id" {
+				t.Errorf("Id() of an unknown id = %q", id)
+			}
+		})
+	}
+}
+
+func TestCommitCommandErrors(t *testing.T) {
+	srv := newServer(t)
+	client := srv.Todoist()
+
+	batch := todoist.NewCommandBatch()
+	good := batch.AddTask(todoist.MakeAddTaskParams().WithContent("Buy milk"))
+	bad := batch.CloseTask("404404")
+
+	res, err := client.Commit(context.Background(), batch)
+	if err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+
+	errs := res.Errors()
+	if len(errs) != 1 || errs[0].Uuid != bad {
+		t.Fatalf("Errors() = %v, want the close command", errs)
+	}
+	if !errors.Is(errs[0], todoist.ErrNotFound) {
+		t.Errorf("Errors()[0] = %v, want ErrNotFound", errs[0])
+	}
+	if res.Id(good) == good {
+		t.Errorf("the task added before the failure was not mapped")
+	}
+}
+
+func TestCommitMissingStatus(t *testing.T) {
+	s := newStub(t, body("application/json", `{"sync_token": "token", "sync_status": {}, "temp_id_mapping": {}}`))
+	client := todoist.New(&todoist.Opts{Token: "token", Client: s.client()})
+
+	batch := todoist.NewCommandBatch()
+	uuid := batch.CloseTask("1")
+
+	res, err := client.Commit(context.Background(), batch)
+	if err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+
+	errs := res.Errors()
+	if len(errs) != 1 || errs[0].Uuid != uuid {
+		t.Fatalf("Errors() = %v, want the close command", errs)
+	}
+	if !errors.Is(errs[0], todoist.ErrNoCommandStatus) {
+		t.Errorf("Errors()[0] = %v, want ErrNoCommandStatus", errs[0])
+	}
+}
+
+func TestCommitRetries(t *testing.T) {
+	srv := newServer(t)
+	s := newStub(t, srv.Config.Handler, hangUp)
+	opts := s.opts(srv)
+	opts.Retry = &todoist.RetryPolicy{MaxAttempts: 2, BaseBackoff: time.Millisecond}
+
+	batch := todoist.NewCommandBatch()
+	tempId := batch.AddTask(todoist.MakeAddTaskParams().WithContent("Buy milk"))
+
+	res, err := todoist.New(opts).Commit(context.Background(), batch)
+	if err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+	if errs := res.Errors(); len(errs) != 0 {
+		t.Fatalf("Errors() = %v, want none", errs)
+	}
+	if s.count() != 2 {
+		t.Errorf("requests = %d, want 2", s.count())
+	}
+	if _, err = srv.Todoist().GetTask(context.Background(), res.Id(tempId)); err != nil {
+		t.Errorf("GetTask(%q) error = %v", res.Id(tempId), err)
+	}
+}
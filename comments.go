@@ -1,7 +1,6 @@
 package todoist
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"net/http"
@@ -70,7 +69,7 @@ func (p *GetCommentsParams) WithTaskId(taskId string) *GetCommentsParams {
 
 func (t *Todoist) GetComments(ctx context.Context, params *GetCommentsParams) (comments []Comment, err error) {
 	comments = make([]Comment, 0)
-	err = t.request(ctx, http.MethodGet, CommentsEndpoint, *params, nil, &comments)
+	err = t.request(ctx, "GetComments", http.MethodGet, CommentsEndpoint, *params, nil, &comments)
 
 	return
 }
@@ -126,7 +125,7 @@ func (t *Todoist) AddComment(ctx context.Context, params *AddCommentParams) (com
 	}
 
 	comment = new(Comment)
-	err = t.request(ctx, http.MethodPost, CommentsEndpoint, nil, bytes.NewBuffer(payload), comment)
+	err = t.request(ctx, "AddComment", http.MethodPost, CommentsEndpoint, nil, payload, comment)
 
 	return
 }
@@ -138,7 +137,7 @@ func (t *Todoist) AddComment(ctx context.Context, params *AddCommentParams) (com
 func (t *Todoist) GetComment(ctx context.Context, commentId string) (comment *Comment, err error) {
 	comment = new(Comment)
 	encodedCommentId := url.PathEscape(commentId)
-	err = t.request(ctx, http.MethodGet, CommentsEndpoint+"/"+encodedCommentId, nil, nil, comment)
+	err = t.request(ctx, "GetComment", http.MethodGet, CommentsEndpoint+"/"+encodedCommentId, nil, nil, comment)
 
 	return
 }
@@ -170,7 +169,7 @@ func (t *Todoist) UpdateComment(ctx context.Context, commentId string, params *U
 	}
 
 	encodedCommentId := url.PathEscape(commentId)
-	return t.request(ctx, http.MethodPost, CommentsEndpoint+"/"+encodedCommentId, nil, bytes.NewBuffer(payload), nil)
+	return t.request(ctx, "UpdateComment", http.MethodPost, CommentsEndpoint+"/"+encodedCommentId, nil, payload, nil)
 }
 
 // endregion
@@ -179,7 +178,7 @@ func (t *Todoist) UpdateComment(ctx context.Context, commentId string, params *U
 
 func (t *Todoist) DeleteComment(ctx context.Context, commentId string) (err error) {
 	encodedCommentId := url.PathEscape(commentId)
-	return t.request(ctx, http.MethodDelete, CommentsEndpoint+"/"+encodedCommentId, nil, nil, nil)
+	return t.request(ctx, "DeleteComment", http.MethodDelete, CommentsEndpoint+"/"+encodedCommentId, nil, nil, nil)
 }
 
 // endregion
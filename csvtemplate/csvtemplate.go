@@ -0,0 +1,497 @@
+// Package csvtemplate reads and writes the CSV format of Todoist project templates.
+package csvtemplate
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	todoist "github.com/temoon/todoist-api"
+	"github.com/temoon/todoist-api/internal/ordering"
+)
+
+const (
+	TypeTask    = "task"
+	TypeSection = "section"
+	TypeNote    = "note"
+	TypeMeta    = "meta"
+)
+
+var Columns = []string{"TYPE", "CONTENT", "DESCRIPTION", "PRIORITY", "INDENT", "AUTHOR", "RESPONSIBLE", "DATE", "DATE_LANG", "TIMEZONE"}
+
+var ErrInvalidIndent = errors.New("indent is deeper than the previous task allows")
+
+// Row is a line of a template. Priority is as shown in the app, 1 is the highest and 4 the default.
+// Indent starts at 1 for top level tasks. Labels are part of the content, like "Buy milk @errand".
+type Row struct {
+	Type        string
+	Content     string
+	Description string
+	Priority    int
+	Indent      int
+	Author      string
+	Responsible string
+	Date        string
+	DateLang    string
+	Timezone    string
+}
+
+type Template struct {
+	Rows []Row
+}
+
+// Read parses a template. Columns are matched by name, so their order doesn't matter and unknown ones
+// are ignored. Empty lines are skipped.
+func Read(r io.Reader) (template *Template, err error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	var header []string
+	if header, err = reader.Read(); err != nil {
+		return
+	}
+
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[strings.ToUpper(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+
+	if _, ok := columns["TYPE"]; !ok {
+		return nil, errors.New("template has no TYPE column")
+	}
+
+	template = new(Template)
+	for number := 2; ; number++ {
+		var record []string
+		if record, err = reader.Read(); err == io.EOF {
+			return template, nil
+		} else if err != nil {
+			return nil, err
+		}
+
+		field := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		row := Row{
+			Type:        strings.ToLower(field("TYPE")),
+			Content:     field("CONTENT"),
+			Description: field("DESCRIPTION"),
+			Author:      field("AUTHOR"),
+			Responsible: field("RESPONSIBLE"),
+			Date:        field("DATE"),
+			DateLang:    field("DATE_LANG"),
+			Timezone:    field("TIMEZONE"),
+		}
+
+		if row.Type == "" && row.Content == "" {
+			continue
+		}
+
+		if row.Priority, err = atoi(field("PRIORITY"), 4); err != nil || row.Priority < 1 || row.Priority > 4 {
+			return nil, fmt.Errorf("record %d: invalid PRIORITY %q", number, field("PRIORITY"))
+		}
+
+		if row.Indent, err = atoi(field("INDENT"), 1); err != nil || row.Indent < 1 {
+			return nil, fmt.Errorf("record %d: invalid INDENT %q", number, field("INDENT"))
+		}
+
+		template.Rows = append(template.Rows, row)
+	}
+}
+
+func Write(w io.Writer, template *Template) (err error) {
+	writer := csv.NewWriter(w)
+	if err = writer.Write(Columns); err != nil {
+		return
+	}
+
+	for _, row := range template.Rows {
+		record := []string{row.Type, row.Content, row.Description, "", "", row.Author, row.Responsible, row.Date, row.DateLang, row.Timezone}
+		if row.Type == TypeTask {
+			record[3], record[4] = strconv.Itoa(row.Priority), strconv.Itoa(row.Indent)
+		}
+
+		if err = writer.Write(record); err != nil {
+			return
+		}
+	}
+
+	writer.Flush()
+
+	return writer.Error()
+}
+
+// FromProject builds the template of a project: tasks outside of sections first, then every section
+// followed by its tasks, with subtasks after their parent and siblings in their order.
+func FromProject(projectId string, sections []todoist.Section, tasks []todoist.Task) *Template {
+	children := make(map[string][]todoist.Task)
+	ids := make(map[string]bool)
+	for _, task := range tasks {
+		if task.ProjectId == projectId {
+			ids[task.Id] = true
+		}
+	}
+
+	for _, task := range tasks {
+		if !ids[task.Id] {
+			continue
+		}
+
+		// Top level tasks are grouped by section, subtasks by parent.
+		key := "section:" + task.SectionId
+		if ids[task.ParentId] {
+			key = task.ParentId
+		}
+		children[key] = append(children[key], task)
+	}
+
+	for key := range children {
+		sortTasks(children[key])
+	}
+
+	template := new(Template)
+
+	var walk func(key string, indent int)
+	walk = func(key string, indent int) {
+		for _, task := range children[key] {
+			template.Rows = append(template.Rows, taskRow(task, indent))
+			walk(task.Id, indent+1)
+		}
+	}
+
+	walk("section:", 1)
+
+	projectSections := make([]todoist.Section, 0)
+	for _, section := range sections {
+		if section.ProjectId == projectId {
+			projectSections = append(projectSections, section)
+		}
+	}
+
+	sort.SliceStable(projectSections, func(i, j int) bool {
+		return projectSections[i].Order < projectSections[j].Order
+	})
+
+	for _, section := range projectSections {
+		template.Rows = append(template.Rows, Row{Type: TypeSection, Content: section.Name})
+		walk("section:"+section.Id, 1)
+	}
+
+	return template
+}
+
+// Objects converts the template into the sections and tasks of a project without creating them.
+// Their ids are the numbers of the rows, starting at 1. Notes and meta rows are left out.
+func (t *Template) Objects(projectId string) (sections []todoist.Section, tasks []todoist.Task, err error) {
+	sections, tasks = make([]todoist.Section, 0), make([]todoist.Task, 0)
+
+	var sectionId string
+	var parents []string
+	order := make(map[string]int)
+
+	for i, row := range t.Rows {
+		id := strconv.Itoa(i + 1)
+
+		switch row.Type {
+		case TypeSection:
+			sectionId, parents = id, nil
+			sections = append(sections, todoist.Section{
+				Id:        id,
+				ProjectId: projectId,
+				Order:     len(sections) + 1,
+				Name:      row.Content,
+			})
+		case TypeTask:
+			if parents, err = indent(parents, row.Indent, i); err != nil {
+				return nil, nil, err
+			}
+
+			var parentId string
+			if len(parents) != 0 {
+				parentId = parents[len(parents)-1]
+			}
+			parents = append(parents, id)
+
+			content, labels := splitLabels(row.Content)
+
+			order[sectionId+"/"+parentId]++
+			tasks = append(tasks, todoist.Task{
+				Id:          id,
+				ProjectId:   projectId,
+				SectionId:   sectionId,
+				Content:     content,
+				Description: row.Description,
+				Labels:      labels,
+				ParentId:    parentId,
+				Order:       order[sectionId+"/"+parentId],
+				Priority:    5 - row.Priority,
+				Due:         rowDue(row),
+				AssigneeId:  responsibleId(row.Responsible),
+			})
+		}
+	}
+
+	return
+}
+
+// Result lists what Apply created, in the order of the rows.
+type Result struct {
+	Sections []todoist.Section
+	Tasks    []todoist.Task
+	Comments []todoist.Comment
+}
+
+// Apply creates the sections, tasks and notes of the template in a project. It stops at the first error
+// and returns what was created so far. Responsible people are matched against the collaborators of
+// the project by id, name or email; tasks of people who are not collaborators are left unassigned.
+// Dates like "2024-03-01 18:00" with a TIMEZONE are sent as that instant, other dates as due strings.
+func (t *Template) Apply(ctx context.Context, client *todoist.Todoist, projectId string) (result *Result, err error) {
+	result = new(Result)
+
+	var sectionId, lastTaskId string
+	var parents []string
+	var assignees map[string]string
+
+	for i, row := range t.Rows {
+		switch row.Type {
+		case TypeSection:
+			params := todoist.MakeAddSectionParams().
+				WithName(row.Content).
+				WithProjectId(projectId)
+
+			var section *todoist.Section
+			if section, err = client.AddSection(ctx, params); err != nil {
+				return
+			}
+
+			result.Sections = append(result.Sections, *section)
+			sectionId, parents, lastTaskId = section.Id, nil, ""
+		case TypeTask:
+			if parents, err = indent(parents, row.Indent, i); err != nil {
+				return
+			}
+
+			content, labels := splitLabels(row.Content)
+			params := todoist.MakeAddTaskParams().
+				WithContent(content).
+				WithDescription(row.Description).
+				WithProjectId(projectId).
+				WithSectionId(sectionId).
+				WithPriority(5 - row.Priority)
+
+			if datetime, ok := rowDatetime(row); ok {
+				params.WithDueDatetime(datetime.UTC().Format(time.RFC3339))
+			} else {
+				params.WithDueString(row.Date).WithDueLang(row.DateLang)
+			}
+
+			if row.Responsible != "" {
+				if assignees == nil {
+					if assignees, err = collaborators(ctx, client, projectId); err != nil {
+						return
+					}
+				}
+				params.WithAssigneeId(assignees[responsibleId(row.Responsible)])
+			}
+
+			if len(parents) != 0 {
+				params.WithParentId(parents[len(parents)-1])
+			}
+
+			if len(labels) != 0 {
+				params.WithLabels(labels)
+			}
+
+			var task *todoist.Task
+			if task, err = client.AddTask(ctx, params); err != nil {
+				return
+			}
+
+			result.Tasks = append(result.Tasks, *task)
+			parents = append(parents, task.Id)
+			lastTaskId = task.Id
+		case TypeNote:
+			params := todoist.MakeAddCommentParams().WithContent(row.Content)
+			if lastTaskId != "" {
+				params.WithTaskId(lastTaskId)
+			} else {
+				params.WithProjectId(projectId)
+			}
+
+			var comment *todoist.Comment
+			if comment, err = client.AddComment(ctx, params); err != nil {
+				return
+			}
+
+			result.Comments = append(result.Comments, *comment)
+		}
+	}
+
+	return
+}
+
+func taskRow(task todoist.Task, indent int) Row {
+	content := task.Content
+	for _, label := range task.Labels {
+		content += " @" + label
+	}
+
+	priority := 4
+	if task.Priority >= 1 && task.Priority <= 4 {
+		priority = 5 - task.Priority
+	}
+
+	row := Row{
+		Type:        TypeTask,
+		Content:     content,
+		Description: task.Description,
+		Priority:    priority,
+		Indent:      indent,
+		Responsible: task.AssigneeId,
+	}
+
+	if task.Due != nil {
+		row.Date = task.Due.String
+		if row.Date == "" {
+			row.Date = task.Due.Date
+		}
+		row.DateLang = "en"
+		row.Timezone = task.Due.Timezone
+
+		// Fixed datetimes are written in their timezone, so that Apply keeps the instant.
+		if !task.Due.IsRecurring && !task.Due.IsAllDay() && !task.Due.IsFloating() && task.Due.Timezone != "" {
+			if t, err := task.Due.InTimezone(); err == nil {
+				row.Date = t.Format("2006-01-02 15:04")
+			}
+		}
+	}
+
+	return row
+}
+
+// rowDatetime reads the date of a row with a timezone as a date and time in that timezone.
+func rowDatetime(row Row) (t time.Time, ok bool) {
+	if row.Timezone == "" {
+		return
+	}
+
+	loc, err := (&todoist.Due{Timezone: row.Timezone}).Location()
+	if err != nil {
+		return
+	}
+
+	for _, layout := range []string{"2006-01-02 15:04", "2006-01-02T15:04:05", "2006-01-02T15:04"} {
+		if t, err = time.ParseInLocation(layout, row.Date, loc); err == nil {
+			return t, true
+		}
+	}
+
+	return
+}
+
+// collaborators maps the ids, names and emails of the collaborators of a project to their ids.
+func collaborators(ctx context.Context, client *todoist.Todoist, projectId string) (assignees map[string]string, err error) {
+	var list []todoist.Collaborator
+	if list, err = client.GetCollaborators(ctx, projectId); err != nil {
+		return
+	}
+
+	assignees = make(map[string]string)
+	for _, collaborator := range list {
+		for _, key := range []string{collaborator.Name, collaborator.Email, collaborator.Id} {
+			if key != "" {
+				assignees[key] = collaborator.Id
+			}
+		}
+	}
+
+	return
+}
+
+func rowDue(row Row) *todoist.Due {
+	if row.Date == "" {
+		return nil
+	}
+
+	due := &todoist.Due{
+		String:   row.Date,
+		Timezone: row.Timezone,
+	}
+
+	if _, err := time.Parse(todoist.DateLayout, row.Date); err == nil {
+		due.Date = row.Date
+	}
+
+	if t, ok := rowDatetime(row); ok {
+		due.Date, due.Datetime = t.Format(todoist.DateLayout), t.UTC().Format(time.RFC3339)
+	}
+
+	if _, err := todoist.ParseRecurrence(row.Date); err == nil {
+		due.IsRecurring = true
+	}
+
+	return due
+}
+
+// indent trims the stack of parents to the indent of a row. A row may only be one level deeper than the previous one.
+func indent(parents []string, level int, row int) ([]string, error) {
+	if level < 1 || level > len(parents)+1 {
+		return nil, fmt.Errorf("row %d: %w", row+1, ErrInvalidIndent)
+	}
+
+	return parents[:level-1], nil
+}
+
+// splitLabels removes trailing @labels from the content.
+func splitLabels(content string) (string, []string) {
+	words := strings.Fields(content)
+
+	end := len(words)
+	for end > 0 && len(words[end-1]) > 1 && strings.HasPrefix(words[end-1], "@") {
+		end--
+	}
+
+	var labels []string
+	for _, word := range words[end:] {
+		labels = append(labels, word[1:])
+	}
+
+	if labels == nil {
+		return content, nil
+	}
+
+	return strings.Join(words[:end], " "), labels
+}
+
+// responsibleId takes the id out of "Name (id)", as Todoist writes collaborators.
+func responsibleId(value string) string {
+	if i := strings.LastIndexByte(value, '('); i != -1 && strings.HasSuffix(value, ")") {
+		return value[i+1 : len(value)-1]
+	}
+
+	return value
+}
+
+func sortTasks(tasks []todoist.Task) {
+	sort.SliceStable(tasks, func(i, j int) bool {
+		return ordering.LessOrder(tasks[i].Order, tasks[i].Id, tasks[j].Order, tasks[j].Id)
+	})
+}
+
+func atoi(value string, fallback int) (int, error) {
+	if value == "" {
+		return fallback, nil
+	}
+
+	return strconv.Atoi(value)
+}
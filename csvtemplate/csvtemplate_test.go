@@ -0,0 +1,260 @@
+package csvtemplate_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	todoist "github.com/temoon/todoist-api"
+	"github.com/temoon/todoist-api/csvtemplate"
+	"github.com/temoon/todoist-api/todoisttest"
+)
+
+func TestRead(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    []csvtemplate.Row
+		wantErr bool
+	}{
+		{
+			name: "defaults",
+			data: "TYPE,CONTENT\ntask,Buy milk\n",
+			want: []csvtemplate.Row{{Type: "task", Content: "Buy milk", Priority: 4, Indent: 1}},
+		},
+		{
+			name: "columns by name with a byte order mark",
+			data: "\ufeffcontent,Unknown,TYPE,INDENT,PRIORITY,DATE\n Buy milk ,x,TASK,2,1,tomorrow\n",
+			want: []csvtemplate.Row{{Type: "task", Content: "Buy milk", Priority: 1, Indent: 2, Date: "tomorrow"}},
+		},
+		{
+			name: "empty lines and short records",
+			data: "TYPE,CONTENT,DESCRIPTION\nsection,Drafts\n,,\n\nnote,\"Quoted, with comma\"\n",
+			want: []csvtemplate.Row{
+				{Type: "section", Content: "Drafts", Priority: 4, Indent: 1},
+				{Type: "note", Content: "Quoted, with comma", Priority: 4, Indent: 1},
+			},
+		},
+		{name: "no type column", data: "CONTENT\nBuy milk\n", wantErr: true},
+		{name: "invalid priority", data: "TYPE,CONTENT,PRIORITY\ntask,Buy milk,5\n", wantErr: true},
+		{name: "invalid indent", data: "TYPE,CONTENT,INDENT\ntask,Buy milk,0\n", wantErr: true},
+		{name: "empty", data: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			template, err := csvtemplate.Read(strings.NewReader(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Read() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && !reflect.DeepEqual(template.Rows, tt.want) {
+				t.Errorf("Read() = %+v, want %+v", template.Rows, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteReadRoundTrip(t *testing.T) {
+	template := &csvtemplate.Template{Rows: []csvtemplate.Row{
+		{Type: csvtemplate.TypeTask, Content: "Plan @work", Description: "First line\nsecond, \"quoted\"", Priority: 1, Indent: 1, DateLang: "en", Date: "every mon"},
+		{Type: csvtemplate.TypeTask, Content: "Draft", Priority: 4, Indent: 2, Responsible: "Jane Doe (2)"},
+		{Type: csvtemplate.TypeNote, Content: "Keep it short", Priority: 4, Indent: 1},
+		{Type: csvtemplate.TypeSection, Content: "Later", Priority: 4, Indent: 1},
+		{Type: csvtemplate.TypeTask, Content: "Review", Priority: 2, Indent: 1, Date: "2024-03-15 09:00", DateLang: "en", Timezone: "UTC"},
+	}}
+
+	var buf bytes.Buffer
+	if err := csvtemplate.Write(&buf, template); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	read, err := csvtemplate.Read(&buf)
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if !reflect.DeepEqual(read, template) {
+		t.Errorf("Read() = %+v, want %+v", read.Rows, template.Rows)
+	}
+}
+
+func TestObjects(t *testing.T) {
+	template := &csvtemplate.Template{Rows: []csvtemplate.Row{
+		{Type: csvtemplate.TypeTask, Content: "Plan @work @q1", Priority: 1, Indent: 1},
+		{Type: csvtemplate.TypeTask, Content: "Draft", Priority: 4, Indent: 2, Responsible: "Jane Doe (2)"},
+		{Type: csvtemplate.TypeNote, Content: "Keep it short", Priority: 4, Indent: 1},
+		{Type: csvtemplate.TypeTask, Content: "Email @ team", Priority: 4, Indent: 1, Date: "2024-03-15"},
+		{Type: csvtemplate.TypeSection, Content: "Later", Priority: 4, Indent: 1},
+		{Type: csvtemplate.TypeTask, Content: "Review", Priority: 2, Indent: 1, Date: "2024-03-15 10:00", Timezone: "UTC"},
+		{Type: csvtemplate.TypeTask, Content: "Repeat", Priority: 4, Indent: 1, Date: "every mon"},
+	}}
+
+	sections, tasks, err := template.Objects("p")
+	if err != nil {
+		t.Fatalf("Objects() error = %v", err)
+	}
+
+	if len(sections) != 1 || sections[0].Id != "5" || sections[0].Name != "Later" || sections[0].ProjectId != "p" {
+		t.Errorf("sections = %+v", sections)
+	}
+
+	tests := []struct {
+		id, content, parentId, sectionId, assigneeId string
+		labels                                       []string
+		order, priority                              int
+		due                                          *todoist.Due
+	}{
+		{id: "1", content: "Plan", labels: []string{"work", "q1"}, order: 1, priority: 4},
+		{id: "2", content: "Draft", parentId: "1", assigneeId: "2", order: 1, priority: 1},
+		{id: "4", content: "Email @ team", order: 2, priority: 1, due: &todoist.Due{String: "2024-03-15", Date: "2024-03-15"}},
+		{id: "6", content: "Review", sectionId: "5", order: 1, priority: 3,
+			due: &todoist.Due{String: "2024-03-15 10:00", Date: "2024-03-15", Datetime: "2024-03-15T10:00:00Z", Timezone: "UTC"}},
+		{id: "7", content: "Repeat", sectionId: "5", order: 2, priority: 1, due: &todoist.Due{String: "every mon", IsRecurring: true}},
+	}
+
+	if len(tasks) != len(tests) {
+		t.Fatalf("Objects() = %d tasks, want %d", len(tasks), len(tests))
+	}
+	for i, tt := range tests {
+		task := tasks[i]
+		if task.Id != tt.id || task.Content != tt.content || task.ParentId != tt.parentId || task.SectionId != tt.sectionId ||
+			task.AssigneeId != tt.assigneeId || !reflect.DeepEqual(task.Labels, tt.labels) || task.Order != tt.order ||
+			task.Priority != tt.priority || !reflect.DeepEqual(task.Due, tt.due) {
+			t.Errorf("task %d = %+v (due %+v), want %+v (due %+v)", i, task, task.Due, tt, tt.due)
+		}
+	}
+
+	bad := &csvtemplate.Template{Rows: []csvtemplate.Row{{Type: csvtemplate.TypeTask, Content: "Deep", Priority: 4, Indent: 2}}}
+	if _, _, err = bad.Objects("p"); !errors.Is(err, csvtemplate.ErrInvalidIndent) {
+		t.Errorf("Objects() error = %v, want ErrInvalidIndent", err)
+	}
+}
+
+func TestApply(t *testing.T) {
+	srv := todoisttest.NewServer()
+	defer srv.Close()
+
+	client := srv.Todoist()
+	ctx := context.Background()
+
+	project, err := client.AddProject(ctx, todoist.MakeAddProjectParams().WithName("Launch"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv.AddCollaborator(project.Id, todoist.Collaborator{Id: "2", Name: "Jane Doe", Email: "jane@example.com"})
+
+	const data = "TYPE,CONTENT,DESCRIPTION,PRIORITY,INDENT,AUTHOR,RESPONSIBLE,DATE,DATE_LANG,TIMEZONE\n" +
+		"note,About the project,,,,,,,,\n" +
+		"task,Plan @work,Scope first,1,1,,Jane Doe,,,\n" +
+		"task,Draft,,4,2,,jane@example.com,,,\n" +
+		"note,Keep it short,,,,,,,,\n" +
+		"section,Later,,,,,,,,\n" +
+		"task,Review,,2,1,,Someone Else (99),2024-03-15 10:00,en,Europe/Berlin\n" +
+		"task,Ship,,4,1,,Test User (2),2024-03-20,en,\n"
+
+	template, err := csvtemplate.Read(strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := template.Apply(ctx, client, project.Id)
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+
+	if len(result.Sections) != 1 || result.Sections[0].Name != "Later" {
+		t.Fatalf("Sections = %+v", result.Sections)
+	}
+	later := result.Sections[0].Id
+
+	if len(result.Comments) != 2 || result.Comments[0].ProjectId != project.Id || result.Comments[1].TaskId == "" {
+		t.Errorf("Comments = %+v, want one on the project and one on a task", result.Comments)
+	}
+
+	tests := []struct {
+		content    string
+		labels     []string
+		priority   int
+		parent     int
+		sectionId  string
+		assigneeId string
+		date       string
+		datetime   string
+	}{
+		{content: "Plan", labels: []string{"work"}, priority: 4, parent: -1, assigneeId: "2"},
+		{content: "Draft", priority: 1, parent: 0, assigneeId: "2"},
+		{content: "Review", priority: 3, parent: -1, sectionId: later, date: "2024-03-15", datetime: "2024-03-15T09:00:00Z"},
+		{content: "Ship", priority: 1, parent: -1, sectionId: later, assigneeId: "2", date: "2024-03-20"},
+	}
+
+	if len(result.Tasks) != len(tests) {
+		t.Fatalf("Tasks = %+v, want %d", result.Tasks, len(tests))
+	}
+	for i, tt := range tests {
+		task, err := client.GetTask(ctx, result.Tasks[i].Id)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		parentId := ""
+		if tt.parent >= 0 {
+			parentId = result.Tasks[tt.parent].Id
+		}
+
+		var date, datetime string
+		if task.Due != nil {
+			date, datetime = task.Due.Date, task.Due.Datetime
+		}
+
+		if task.Content != tt.content || !equalLabels(task.Labels, tt.labels) || task.Priority != tt.priority ||
+			task.ParentId != parentId || task.SectionId != tt.sectionId || task.AssigneeId != tt.assigneeId ||
+			date != tt.date || datetime != tt.datetime {
+			t.Errorf("task %d = %+v (due %+v), want %+v", i, task, task.Due, tt)
+		}
+	}
+}
+
+func TestFromProject(t *testing.T) {
+	sections := []todoist.Section{
+		{Id: "s2", ProjectId: "p", Name: "Later", Order: 2},
+		{Id: "s1", ProjectId: "p", Name: "Now", Order: 1},
+		{Id: "s3", ProjectId: "other", Name: "Elsewhere", Order: 1},
+	}
+	tasks := []todoist.Task{
+		{Id: "t4", ProjectId: "p", SectionId: "s2", Content: "Ship", Priority: 1, Order: 1},
+		{Id: "t2", ProjectId: "p", ParentId: "t1", Content: "Draft", Priority: 1, Order: 1},
+		{Id: "t1", ProjectId: "p", Content: "Plan", Labels: []string{"work"}, Priority: 4, Order: 1,
+			Due: &todoist.Due{Date: "2024-03-15", Datetime: "2024-03-15T09:00:00Z", Timezone: "UTC"}},
+		{Id: "t3", ProjectId: "p", SectionId: "s1", Content: "Review", Priority: 2, Order: 1, Due: &todoist.Due{String: "every mon", Date: "2024-03-18", IsRecurring: true}},
+		{Id: "t5", ProjectId: "other", Content: "Not mine", Priority: 1},
+	}
+
+	template := csvtemplate.FromProject("p", sections, tasks)
+
+	var got []string
+	for _, row := range template.Rows {
+		got = append(got, strings.Join([]string{row.Type, row.Content, string(rune('0' + row.Priority)), string(rune('0' + row.Indent)), row.Date, row.Timezone}, "|"))
+	}
+
+	want := []string{
+		"task|Plan @work|1|1|2024-03-15 09:00|UTC",
+		"task|Draft|4|2||",
+		"section|Now|0|0||",
+		"task|Review|3|1|every mon|",
+		"section|Later|0|0||",
+		"task|Ship|4|1||",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("FromProject() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func equalLabels(a []string, b []string) bool {
+	if len(a) == 0 && len(b) == 0 {
+		return true
+	}
+
+	return reflect.DeepEqual(a, b)
+}
@@ -0,0 +1,74 @@
+package todoist
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+const maxSnippetSize = 256
+
+var ErrInvalidContentType = errors.New("invalid response content type")
+
+type DecodeError struct {
+	Endpoint    string
+	ContentType string
+	Snippet     string
+	Err         error
+}
+
+func (e *DecodeError) Error() string {
+	msg := "decode " + e.Endpoint + ": " + e.Err.Error()
+	if e.Snippet != "" {
+		msg += ": " + e.Snippet
+	}
+
+	return msg
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+func decode(endpoint string, header http.Header, body []byte, data interface{}) (err error) {
+	if data == nil || len(bytes.TrimSpace(body)) == 0 {
+		return
+	}
+
+	contentType := header.Get("Content-Type")
+	if mediaType, _, parseErr := mime.ParseMediaType(contentType); parseErr != nil || !isJsonMediaType(mediaType) {
+		return &DecodeError{
+			Endpoint:    endpoint,
+			ContentType: contentType,
+			Snippet:     snippet(body),
+			Err:         ErrInvalidContentType,
+		}
+	}
+
+	if err = json.Unmarshal(body, data); err != nil {
+		return &DecodeError{
+			Endpoint:    endpoint,
+			ContentType: contentType,
+			Snippet:     snippet(body),
+			Err:         err,
+		}
+	}
+
+	return
+}
+
+func isJsonMediaType(mediaType string) bool {
+	return mediaType == "application/json" || strings.HasPrefix(mediaType, "application/") && strings.HasSuffix(mediaType, "+json")
+}
+
+func snippet(body []byte) string {
+	body = bytes.TrimSpace(body)
+	if len(body) <= maxSnippetSize {
+		return string(body)
+	}
+
+	return strings.ToValidUTF8(string(body[:maxSnippetSize]), "") + "..."
+}
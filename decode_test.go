@@ -0,0 +1,76 @@
+package todoist_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	todoist "github.com/temoon/todoist-api"
+)
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		data        string
+		want        int
+	}{
+		{name: "json", contentType: "application/json", data: `[{"id": "7"}]`, want: 1},
+		{name: "charset parameter", contentType: "application/json; charset=utf-8", data: `[{"id": "7"}, {"id": "8"}]`, want: 2},
+		{name: "json suffix", contentType: "application/problem+json", data: `[{"id": "7"}]`, want: 1},
+		{name: "empty body", contentType: "text/plain", data: " \n", want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newStub(t, body(tt.contentType, tt.data))
+
+			projects, err := todoist.New(&todoist.Opts{Token: "token", Client: s.client()}).GetProjects(context.Background())
+			if err != nil {
+				t.Fatalf("GetProjects() error = %v", err)
+			}
+			if len(projects) != tt.want {
+				t.Errorf("len(projects) = %d, want %d", len(projects), tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeError(t *testing.T) {
+	tests := []struct {
+		name    string
+		fault   http.HandlerFunc
+		wantErr error
+		snippet string
+	}{
+		{name: "html", fault: body("text/html", "<html>maintenance</html>"), wantErr: todoist.ErrInvalidContentType, snippet: "<html>maintenance</html>"},
+		{name: "no content type", fault: body("", `[]`), wantErr: todoist.ErrInvalidContentType},
+		{name: "truncated json", fault: body("application/json", `[{"id": "1"`), snippet: `[{"id": "1"`},
+		{name: "wrong shape", fault: body("application/json", `{"id": "1"}`), snippet: `{"id": "1"}`},
+		{name: "long body", fault: body("text/html", strings.Repeat("x", 300)), wantErr: todoist.ErrInvalidContentType, snippet: strings.Repeat("x", 256) + "..."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newStub(t, nil, tt.fault)
+
+			_, err := todoist.New(&todoist.Opts{Token: "token", Client: s.client()}).GetProjects(context.Background())
+
+			var decodeErr *todoist.DecodeError
+			if !errors.As(err, &decodeErr) {
+				t.Fatalf("GetProjects() error = %v, want *DecodeError", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("GetProjects() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.snippet != "" && decodeErr.Snippet != tt.snippet {
+				t.Errorf("Snippet = %q, want %q", decodeErr.Snippet, tt.snippet)
+			}
+			if decodeErr.Endpoint != todoist.ProjectsEndpoint {
+				t.Errorf("Endpoint = %q, want %q", decodeErr.Endpoint, todoist.ProjectsEndpoint)
+			}
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package todoist
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DateLayout     = "2006-01-02"
+	floatingLayout = "2006-01-02T15:04:05"
+)
+
+var ErrNoDueDate = errors.New("no due date")
+
+// Due is the due date of a task. It comes in three flavours: an all-day date ("2016-09-01"),
+// a floating datetime without timezone, which happens at the same wall clock time wherever the user is,
+// and a datetime fixed to an instant, with Timezone set to the zone the user picked.
+type Due struct {
+	String      string `json:"string"`
+	Date        string `json:"date"`
+	IsRecurring bool   `json:"is_recurring"`
+	Datetime    string `json:"datetime"`
+	Timezone    string `json:"timezone"`
+}
+
+func (d *Due) IsAllDay() bool {
+	return d != nil && d.Date != "" && d.datetime() == ""
+}
+
+func (d *Due) IsFloating() bool {
+	if d == nil {
+		return false
+	}
+
+	value := d.datetime()
+	if value == "" {
+		return false
+	}
+
+	_, err := time.Parse(time.RFC3339, value)
+
+	return err != nil
+}
+
+// Location returns the timezone of a fixed datetime. Timezones are either tz database names
+// or offsets like "UTC+02:00". It returns nil if the due has no timezone.
+func (d *Due) Location() (loc *time.Location, err error) {
+	if d == nil || d.Timezone == "" {
+		return nil, nil
+	}
+
+	if offset := strings.TrimPrefix(d.Timezone, "UTC"); offset != d.Timezone && offset != "" {
+		return parseOffset(d.Timezone, offset)
+	}
+
+	return time.LoadLocation(d.Timezone)
+}
+
+// Time returns the due time in loc. All-day dates are midnight and floating datetimes are the wall clock
+// time in loc, fixed datetimes are the same instant in any location. A nil loc means time.Local.
+func (d *Due) Time(loc *time.Location) (t time.Time, err error) {
+	if d == nil || d.Date == "" && d.Datetime == "" {
+		return time.Time{}, ErrNoDueDate
+	}
+
+	if loc == nil {
+		loc = time.Local
+	}
+
+	value := d.datetime()
+	if value == "" {
+		return time.ParseInLocation(DateLayout, d.Date, loc)
+	}
+
+	if t, err = time.Parse(time.RFC3339, value); err == nil {
+		return t.In(loc), nil
+	}
+
+	return time.ParseInLocation(floatingLayout, value, loc)
+}
+
+// InTimezone returns the due time in its own timezone, falling back to time.Local
+// for dates and floating datetimes.
+func (d *Due) InTimezone() (t time.Time, err error) {
+	var loc *time.Location
+	if loc, err = d.Location(); err != nil {
+		return
+	}
+
+	return d.Time(loc)
+}
+
+// IsOverdue reports whether the due has passed at now. All-day dates become overdue when the day is over
+// and floating datetimes are compared in the location of now.
+func (d *Due) IsOverdue(now time.Time) bool {
+	if d.IsAllDay() {
+		return d.Date < now.Format(DateLayout)
+	}
+
+	t, err := d.Time(now.Location())
+
+	return err == nil && t.Before(now)
+}
+
+// normalize moves the datetime of a Sync API due into Datetime and leaves the date in Date, as REST returns it.
+func (d *Due) normalize() {
+	if d.Datetime != "" || !strings.Contains(d.Date, "T") {
+		return
+	}
+
+	d.Datetime = d.Date
+	if t, err := d.InTimezone(); err == nil {
+		d.Date = t.Format(DateLayout)
+	} else if len(d.Date) > len(DateLayout) {
+		d.Date = d.Date[:len(DateLayout)]
+	}
+}
+
+// datetime returns the datetime part. The Sync API has no separate field and keeps it in Date.
+func (d *Due) datetime() string {
+	if d.Datetime != "" {
+		return d.Datetime
+	}
+
+	if strings.Contains(d.Date, "T") {
+		return d.Date
+	}
+
+	return ""
+}
+
+func parseOffset(name string, offset string) (loc *time.Location, err error) {
+	sign := 1
+	switch offset[0] {
+	case '+':
+	case '-':
+		sign = -1
+	default:
+		return nil, errors.New("invalid timezone offset: " + name)
+	}
+
+	hours, minutes := offset[1:], "0"
+	if i := strings.IndexByte(hours, ':'); i != -1 {
+		hours, minutes = hours[:i], hours[i+1:]
+	}
+
+	var h, m int
+	if h, err = strconv.Atoi(hours); err != nil {
+		return nil, errors.New("invalid timezone offset: " + name)
+	}
+	if m, err = strconv.Atoi(minutes); err != nil {
+		return nil, errors.New("invalid timezone offset: " + name)
+	}
+
+	return time.FixedZone(name, sign*(h*3600+m*60)), nil
+}
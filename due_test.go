@@ -0,0 +1,95 @@
+package todoist_test
+
+import (
+	"testing"
+	"time"
+
+	todoist "github.com/temoon/todoist-api"
+)
+
+func TestDueInTimezone(t *testing.T) {
+	tests := []struct {
+		name     string
+		due      *todoist.Due
+		allDay   bool
+		floating bool
+		zone     string
+		want     string
+		wantErr  string
+	}{
+		{name: "all day", due: &todoist.Due{Date: "2024-03-15"}, allDay: true, want: "2024-03-15 00:00"},
+		{name: "floating", due: &todoist.Due{Date: "2024-03-15", Datetime: "2024-03-15T09:00:00"}, floating: true, want: "2024-03-15 09:00"},
+		{name: "floating in date", due: &todoist.Due{Date: "2024-03-15T09:00:00"}, floating: true, want: "2024-03-15 09:00"},
+		{name: "utc", due: &todoist.Due{Date: "2024-03-15", Datetime: "2024-03-15T09:00:00Z", Timezone: "UTC"}, zone: "UTC", want: "2024-03-15 09:00 +00:00"},
+		{name: "tz database name", due: &todoist.Due{Date: "2024-03-15", Datetime: "2024-03-15T08:00:00Z", Timezone: "Europe/Berlin"}, zone: "Europe/Berlin", want: "2024-03-15 09:00 +01:00"},
+		{name: "fixed in date", due: &todoist.Due{Date: "2024-07-15T07:00:00Z", Timezone: "Europe/Berlin"}, zone: "Europe/Berlin", want: "2024-07-15 09:00 +02:00"},
+		{name: "positive offset", due: &todoist.Due{Datetime: "2024-03-15T07:00:00Z", Timezone: "UTC+02:00"}, zone: "UTC+02:00", want: "2024-03-15 09:00 +02:00"},
+		{name: "negative offset", due: &todoist.Due{Datetime: "2024-03-15T14:30:00Z", Timezone: "UTC-05:30"}, zone: "UTC-05:30", want: "2024-03-15 09:00 -05:30"},
+		{name: "offset in hours", due: &todoist.Due{Datetime: "2024-03-15T06:00:00Z", Timezone: "UTC+3"}, zone: "UTC+3", want: "2024-03-15 09:00 +03:00"},
+		{name: "invalid offset", due: &todoist.Due{Datetime: "2024-03-15T07:00:00Z", Timezone: "UTC*02:00"}, wantErr: "invalid timezone offset: UTC*02:00"},
+		{name: "invalid offset hours", due: &todoist.Due{Datetime: "2024-03-15T07:00:00Z", Timezone: "UTC+x"}, wantErr: "invalid timezone offset: UTC+x"},
+		{name: "no due", due: nil, wantErr: todoist.ErrNoDueDate.Error()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.due.IsAllDay(); got != tt.allDay {
+				t.Errorf("IsAllDay() = %t, want %t", got, tt.allDay)
+			}
+			if got := tt.due.IsFloating(); got != tt.floating {
+				t.Errorf("IsFloating() = %t, want %t", got, tt.floating)
+			}
+
+			got, err := tt.due.InTimezone()
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("InTimezone() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("InTimezone() error = %v", err)
+			}
+
+			// Dates and floating datetimes have no zone of their own and are read in time.Local.
+			layout := "2006-01-02 15:04 -07:00"
+			if tt.zone == "" {
+				layout = "2006-01-02 15:04"
+				if got.Location() != time.Local {
+					t.Errorf("InTimezone() location = %v, want Local", got.Location())
+				}
+			} else if got.Location().String() != tt.zone {
+				t.Errorf("InTimezone() location = %v, want %s", got.Location(), tt.zone)
+			}
+
+			if got.Format(layout) != tt.want {
+				t.Errorf("InTimezone() = %s, want %s", got.Format(layout), tt.want)
+			}
+		})
+	}
+}
+
+func TestDueIsOverdue(t *testing.T) {
+	now := time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		due  *todoist.Due
+		want bool
+	}{
+		{name: "date before", due: &todoist.Due{Date: "2024-03-14"}, want: true},
+		{name: "date today", due: &todoist.Due{Date: "2024-03-15"}, want: false},
+		{name: "floating before", due: &todoist.Due{Date: "2024-03-15", Datetime: "2024-03-15T11:00:00"}, want: true},
+		{name: "floating after", due: &todoist.Due{Date: "2024-03-15", Datetime: "2024-03-15T13:00:00"}, want: false},
+		{name: "fixed before", due: &todoist.Due{Date: "2024-03-15", Datetime: "2024-03-15T12:30:00+02:00", Timezone: "UTC+02:00"}, want: true},
+		{name: "fixed after", due: &todoist.Due{Date: "2024-03-15", Datetime: "2024-03-15T12:30:00Z", Timezone: "UTC"}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.due.IsOverdue(now); got != tt.want {
+				t.Errorf("IsOverdue() = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
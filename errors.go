@@ -0,0 +1,107 @@
+package todoist
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const maxErrorBodySize = 64 << 10
+
+var (
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrForbidden    = errors.New("forbidden")
+	ErrNotFound     = errors.New("not found")
+	ErrRateLimited  = errors.New("rate limited")
+	ErrServer       = errors.New("server error")
+)
+
+// ApiError is returned for every non-2xx response. It matches the Err* sentinels with errors.Is.
+type ApiError struct {
+	StatusCode int
+	Status     string
+	Method     string
+	Endpoint   string
+	Body       []byte
+	Tag        string
+	Message    string
+	RequestId  string
+	RetryAfter time.Duration
+}
+
+func (e *ApiError) Error() string {
+	msg := e.Method + " " + e.Endpoint + ": " + e.Status
+	if e.Tag != "" {
+		msg += " (" + e.Tag + ")"
+	}
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+
+	return msg
+}
+
+func (e *ApiError) Is(target error) bool {
+	switch target {
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrForbidden:
+		return e.StatusCode == http.StatusForbidden
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	case ErrServer:
+		return e.StatusCode >= 500
+	default:
+		return false
+	}
+}
+
+func newApiError(req *http.Request, result *Result, endpoint string) *ApiError {
+	e := &ApiError{
+		StatusCode: result.StatusCode,
+		Status:     result.Status,
+		Method:     req.Method,
+		Endpoint:   endpoint,
+		Body:       result.Body,
+		RequestId:  result.Header.Get("X-Request-Id"),
+		RetryAfter: parseRetryAfter(result.Header.Get("Retry-After"), time.Now()),
+	}
+
+	if e.RequestId == "" {
+		e.RequestId = req.Header.Get("X-Request-Id")
+	}
+
+	if e.Status == "" {
+		e.Status = fmt.Sprintf("%d %s", result.StatusCode, http.StatusText(result.StatusCode))
+	}
+
+	if len(e.Body) > maxErrorBodySize {
+		e.Body = e.Body[:maxErrorBodySize]
+	}
+	e.Tag, e.Message = parseErrorBody(e.Body)
+
+	return e
+}
+
+func parseErrorBody(body []byte) (tag string, message string) {
+	var data struct {
+		Error    string `json:"error"`
+		ErrorTag string `json:"error_tag"`
+	}
+
+	if err := json.Unmarshal(body, &data); err == nil {
+		return data.ErrorTag, data.Error
+	}
+
+	message = strings.TrimSpace(string(body))
+	if i := strings.IndexByte(message, '\n'); i != -1 {
+		message = message[:i]
+	}
+
+	return "", message
+}
@@ -0,0 +1,557 @@
+package todoist
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FilterEnv is what filters refer to besides the task itself. Now is time.Now() when zero, and its location
+// is the one of dates. UserId is "me". Projects, sections and collaborators resolve names.
+type FilterEnv struct {
+	Now           time.Time
+	UserId        string
+	Projects      []Project
+	Sections      []Section
+	Collaborators []Collaborator
+}
+
+// FilterQuery is anything that renders to the filter language.
+type FilterQuery interface {
+	String() string
+}
+
+type FilterError struct {
+	String string
+	Near   string
+}
+
+func (e *FilterError) Error() string {
+	if e.Near == "" {
+		return "unsupported filter " + strconv.Quote(e.String)
+	}
+
+	return "unsupported filter " + strconv.Quote(e.String) + " near " + strconv.Quote(e.Near)
+}
+
+// Filter is a parsed filter query. A query has one view per comma-separated part.
+type Filter struct {
+	query string
+	views []filterMatch
+}
+
+type filterMatch func(task *Task, scope *filterScope) bool
+
+type filterScope struct {
+	now           time.Time
+	today         time.Time
+	userId        string
+	projects      map[string]Project
+	sections      map[string]Section
+	collaborators []Collaborator
+}
+
+type filterToken struct {
+	op   byte
+	term string
+}
+
+type filterParser struct {
+	query  string
+	tokens []filterToken
+	pos    int
+}
+
+var dateUnits = map[string][3]int{
+	"day": {0, 0, 1}, "days": {0, 0, 1},
+	"week": {0, 0, 7}, "weeks": {0, 0, 7},
+	"month": {0, 1, 0}, "months": {0, 1, 0},
+	"year": {1, 0, 0}, "years": {1, 0, 0},
+}
+
+// ParseFilter parses the filter language of Todoist: terms like "today", "overdue", "p1", "@label",
+// "#Project", "##Project" with subprojects, "/Section", "assigned to: me", "no date", "due before: +3 days",
+// "date: 2024-02-01", "7 days" and "search: text", combined with "&", "|", "!" and parentheses.
+// Commas separate views. Names may contain "*" wildcards, and a backslash escapes the character after it.
+func ParseFilter(query string) (f *Filter, err error) {
+	p := &filterParser{query: query}
+	if err = p.tokenize(); err != nil {
+		return
+	}
+
+	f = &Filter{query: query}
+	for {
+		var match filterMatch
+		if match, err = p.or(); err != nil {
+			return nil, err
+		}
+		f.views = append(f.views, match)
+
+		if p.pos == len(p.tokens) {
+			return f, nil
+		}
+
+		if p.tokens[p.pos].op != ',' {
+			return nil, p.fail()
+		}
+		p.pos++
+	}
+}
+
+func (f *Filter) String() string {
+	return f.query
+}
+
+func (f *Filter) Views() int {
+	return len(f.views)
+}
+
+// Match reports whether the task matches any view.
+func (f *Filter) Match(task Task, env *FilterEnv) bool {
+	return f.matches(&task, newFilterScope(env))
+}
+
+// Select returns the matching tasks of every view, in the order of tasks.
+func (f *Filter) Select(tasks []Task, env *FilterEnv) [][]Task {
+	scope := newFilterScope(env)
+
+	views := make([][]Task, len(f.views))
+	for i, match := range f.views {
+		views[i] = make([]Task, 0)
+		for j := range tasks {
+			if match(&tasks[j], scope) {
+				views[i] = append(views[i], tasks[j])
+			}
+		}
+	}
+
+	return views
+}
+
+func (f *Filter) matches(task *Task, scope *filterScope) bool {
+	for _, match := range f.views {
+		if match(task, scope) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func newFilterScope(env *FilterEnv) *filterScope {
+	if env == nil {
+		env = &FilterEnv{}
+	}
+
+	scope := &filterScope{
+		now:           env.Now,
+		userId:        env.UserId,
+		projects:      make(map[string]Project, len(env.Projects)),
+		sections:      make(map[string]Section, len(env.Sections)),
+		collaborators: env.Collaborators,
+	}
+
+	if scope.now.IsZero() {
+		scope.now = time.Now()
+	}
+
+	year, month, day := scope.now.Date()
+	scope.today = time.Date(year, month, day, 0, 0, 0, 0, scope.now.Location())
+
+	for _, project := range env.Projects {
+		scope.projects[project.Id] = project
+	}
+
+	for _, section := range env.Sections {
+		scope.sections[section.Id] = section
+	}
+
+	return scope
+}
+
+// dueDate returns the date of the due in the location of now, or an empty string.
+func (s *filterScope) dueDate(task *Task) string {
+	if task.Due.IsAllDay() {
+		return task.Due.Date
+	}
+
+	t, err := task.Due.Time(s.now.Location())
+	if err != nil {
+		return ""
+	}
+
+	return t.Format(DateLayout)
+}
+
+// inProject reports whether the project of the task matches, or one of its parents with subprojects.
+func (s *filterScope) inProject(task *Task, pattern string, subprojects bool) bool {
+	project, ok := s.projects[task.ProjectId]
+	for steps := 0; ok && steps <= len(s.projects); steps++ {
+		if matchWildcard(pattern, project.Name) {
+			return true
+		}
+
+		if !subprojects {
+			return false
+		}
+
+		project, ok = s.projects[project.ParentId]
+	}
+
+	return false
+}
+
+// isUser reports whether the id is the one of "me", "others" or a collaborator name.
+func (s *filterScope) isUser(id string, who string) bool {
+	switch strings.ToLower(unescape(who)) {
+	case "me":
+		return id != "" && id == s.userId
+	case "others":
+		return id != "" && id != s.userId
+	}
+
+	for _, collaborator := range s.collaborators {
+		if collaborator.Id == id && (matchWildcard(who, collaborator.Name) || strings.EqualFold(unescape(who), collaborator.Email)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p *filterParser) tokenize() error {
+	var term strings.Builder
+	var pending bool
+
+	flush := func() {
+		if text := strings.TrimSpace(term.String()); pending && text != "" {
+			p.tokens = append(p.tokens, filterToken{term: text})
+		}
+		term.Reset()
+		pending = false
+	}
+
+	for i := 0; i < len(p.query); i++ {
+		c := p.query[i]
+		switch {
+		case c == '\\':
+			if i+1 == len(p.query) {
+				return &FilterError{String: p.query, Near: "\\"}
+			}
+			// Escapes stay in the term, so that escaped colons, sigils and wildcards are read literally.
+			term.WriteString(p.query[i : i+2])
+			i++
+			pending = true
+		case c == '&' || c == '|' || c == '(' || c == ')' || c == ',':
+			flush()
+			p.tokens = append(p.tokens, filterToken{op: c})
+		case c == '!' && strings.TrimSpace(term.String()) == "":
+			flush()
+			p.tokens = append(p.tokens, filterToken{op: c})
+		default:
+			term.WriteByte(c)
+			pending = pending || c != ' ' && c != '\t'
+		}
+	}
+
+	flush()
+
+	return nil
+}
+
+func (p *filterParser) fail() error {
+	if p.pos == len(p.tokens) {
+		return &FilterError{String: p.query}
+	}
+
+	token := p.tokens[p.pos]
+	if token.op != 0 {
+		return &FilterError{String: p.query, Near: string(token.op)}
+	}
+
+	return &FilterError{String: p.query, Near: token.term}
+}
+
+func (p *filterParser) or() (filterMatch, error) {
+	left, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.pos < len(p.tokens) && p.tokens[p.pos].op == '|' {
+		p.pos++
+
+		var right filterMatch
+		if right, err = p.and(); err != nil {
+			return nil, err
+		}
+
+		a, b := left, right
+		left = func(task *Task, scope *filterScope) bool { return a(task, scope) || b(task, scope) }
+	}
+
+	return left, nil
+}
+
+func (p *filterParser) and() (filterMatch, error) {
+	left, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.pos < len(p.tokens) && p.tokens[p.pos].op == '&' {
+		p.pos++
+
+		var right filterMatch
+		if right, err = p.unary(); err != nil {
+			return nil, err
+		}
+
+		a, b := left, right
+		left = func(task *Task, scope *filterScope) bool { return a(task, scope) && b(task, scope) }
+	}
+
+	return left, nil
+}
+
+func (p *filterParser) unary() (filterMatch, error) {
+	if p.pos == len(p.tokens) {
+		return nil, p.fail()
+	}
+
+	token := p.tokens[p.pos]
+	switch token.op {
+	case '!':
+		p.pos++
+		match, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return func(task *Task, scope *filterScope) bool { return !match(task, scope) }, nil
+	case '(':
+		p.pos++
+		match, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if p.pos == len(p.tokens) || p.tokens[p.pos].op != ')' {
+			return nil, p.fail()
+		}
+		p.pos++
+		return match, nil
+	case 0:
+		match, err := p.term(token.term)
+		if err != nil {
+			return nil, err
+		}
+		p.pos++
+		return match, nil
+	default:
+		return nil, p.fail()
+	}
+}
+
+func (p *filterParser) term(term string) (filterMatch, error) {
+	lower := strings.ToLower(term)
+	key, value := lower, ""
+	if i := indexUnescaped(term, ':'); i != -1 {
+		key, value = strings.ToLower(strings.TrimSpace(term[:i])), strings.TrimSpace(term[i+1:])
+	}
+
+	switch key {
+	case "search":
+		text := strings.ToLower(unescape(value))
+		return func(task *Task, _ *filterScope) bool { return strings.Contains(strings.ToLower(task.Content), text) }, nil
+	case "assigned to":
+		return func(task *Task, scope *filterScope) bool { return scope.isUser(task.AssigneeId, value) }, nil
+	case "assigned by":
+		return func(task *Task, scope *filterScope) bool { return scope.isUser(task.AssignerId, value) }, nil
+	case "date", "due":
+		return p.dateTerm(value, func(date string, target string) bool { return date == target })
+	case "date before", "due before":
+		return p.dateTerm(value, func(date string, target string) bool { return date < target })
+	case "date after", "due after":
+		return p.dateTerm(value, func(date string, target string) bool { return date > target })
+	}
+
+	switch lower {
+	case "overdue", "od":
+		return func(task *Task, scope *filterScope) bool { return task.Due.IsOverdue(scope.now) }, nil
+	case "no date":
+		return func(task *Task, _ *filterScope) bool { return task.Due == nil }, nil
+	case "no time":
+		return func(task *Task, _ *filterScope) bool { return task.Due == nil || task.Due.IsAllDay() }, nil
+	case "recurring":
+		return func(task *Task, _ *filterScope) bool { return task.Due != nil && task.Due.IsRecurring }, nil
+	case "no labels":
+		return func(task *Task, _ *filterScope) bool { return len(task.Labels) == 0 }, nil
+	case "no priority":
+		return func(task *Task, _ *filterScope) bool { return task.Priority <= 1 }, nil
+	case "subtask":
+		return func(task *Task, _ *filterScope) bool { return task.ParentId != "" }, nil
+	case "assigned":
+		return func(task *Task, _ *filterScope) bool { return task.AssigneeId != "" }, nil
+	case "shared":
+		return func(task *Task, scope *filterScope) bool { return scope.projects[task.ProjectId].IsShared }, nil
+	}
+
+	switch {
+	case len(lower) == 2 && lower[0] == 'p' && lower[1] >= '1' && lower[1] <= '4':
+		priority := 5 - int(lower[1]-'0')
+		return func(task *Task, _ *filterScope) bool {
+			return task.Priority == priority || priority == 1 && task.Priority == 0
+		}, nil
+	case strings.HasPrefix(term, "@") && len(term) > 1:
+		pattern := term[1:]
+		return func(task *Task, _ *filterScope) bool {
+			for _, label := range task.Labels {
+				if matchWildcard(pattern, label) {
+					return true
+				}
+			}
+			return false
+		}, nil
+	case strings.HasPrefix(term, "##") && len(term) > 2:
+		pattern := term[2:]
+		return func(task *Task, scope *filterScope) bool { return scope.inProject(task, pattern, true) }, nil
+	case strings.HasPrefix(term, "#") && len(term) > 1:
+		pattern := term[1:]
+		return func(task *Task, scope *filterScope) bool { return scope.inProject(task, pattern, false) }, nil
+	case strings.HasPrefix(term, "/") && len(term) > 1:
+		pattern := term[1:]
+		return func(task *Task, scope *filterScope) bool {
+			section, ok := scope.sections[task.SectionId]
+			return ok && matchWildcard(pattern, section.Name)
+		}, nil
+	}
+
+	// "7 days" and "next 7 days" are the days from today on.
+	if words := strings.Fields(strings.TrimPrefix(lower, "next ")); len(words) == 2 && (words[1] == "days" || words[1] == "day") {
+		if n, err := strconv.Atoi(words[0]); err == nil && n > 0 {
+			return func(task *Task, scope *filterScope) bool {
+				date := scope.dueDate(task)
+				return date != "" && date >= scope.today.Format(DateLayout) && date < scope.today.AddDate(0, 0, n).Format(DateLayout)
+			}, nil
+		}
+	}
+
+	return p.dateTerm(term, func(date string, target string) bool { return date == target })
+}
+
+// dateTerm compares the due date of tasks with a date: "today", "tomorrow", "yesterday", a weekday
+// for the next one, "2024-02-01", or an offset from today like "+3 days" or "-1 week".
+func (p *filterParser) dateTerm(value string, compare func(date string, target string) bool) (filterMatch, error) {
+	target, ok := parseFilterDate(value)
+	if !ok {
+		return nil, &FilterError{String: p.query, Near: value}
+	}
+
+	return func(task *Task, scope *filterScope) bool {
+		date := scope.dueDate(task)
+		return date != "" && compare(date, target(scope.today).Format(DateLayout))
+	}, nil
+}
+
+func parseFilterDate(value string) (func(today time.Time) time.Time, bool) {
+	lower := strings.ToLower(strings.TrimSpace(value))
+
+	switch lower {
+	case "today":
+		return func(today time.Time) time.Time { return today }, true
+	case "tomorrow":
+		return func(today time.Time) time.Time { return today.AddDate(0, 0, 1) }, true
+	case "yesterday":
+		return func(today time.Time) time.Time { return today.AddDate(0, 0, -1) }, true
+	}
+
+	if weekday, ok := weekdayWords[lower]; ok {
+		return func(today time.Time) time.Time {
+			return today.AddDate(0, 0, (int(weekday)-int(today.Weekday())+7)%7)
+		}, true
+	}
+
+	if date, err := time.Parse(DateLayout, lower); err == nil {
+		return func(today time.Time) time.Time {
+			return time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, today.Location())
+		}, true
+	}
+
+	words := strings.Fields(lower)
+	if len(words) != 2 {
+		return nil, false
+	}
+
+	n, err := strconv.Atoi(words[0])
+	unit, ok := dateUnits[words[1]]
+	if err != nil || !ok {
+		return nil, false
+	}
+
+	return func(today time.Time) time.Time { return today.AddDate(n*unit[0], n*unit[1], n*unit[2]) }, true
+}
+
+// indexUnescaped returns the index of the first c that is not escaped with a backslash, or -1.
+func indexUnescaped(text string, c byte) int {
+	for i := 0; i < len(text); i++ {
+		switch text[i] {
+		case '\\':
+			i++
+		case c:
+			return i
+		}
+	}
+
+	return -1
+}
+
+// unescape drops the backslashes of escaped characters.
+func unescape(text string) string {
+	var b strings.Builder
+	for i := 0; i < len(text); i++ {
+		if text[i] == '\\' && i+1 < len(text) {
+			i++
+		}
+		b.WriteByte(text[i])
+	}
+
+	return b.String()
+}
+
+// matchWildcard compares case-insensitively, with "*" matching any text unless escaped with a backslash.
+func matchWildcard(pattern string, value string) bool {
+	var parts []string
+	var part strings.Builder
+	for i := 0; i < len(pattern); i++ {
+		switch {
+		case pattern[i] == '\\' && i+1 < len(pattern):
+			part.WriteByte(pattern[i+1])
+			i++
+		case pattern[i] == '*':
+			parts = append(parts, strings.ToLower(part.String()))
+			part.Reset()
+		default:
+			part.WriteByte(pattern[i])
+		}
+	}
+	parts = append(parts, strings.ToLower(part.String()))
+	value = strings.ToLower(value)
+
+	if len(parts) == 1 {
+		return value == parts[0]
+	}
+
+	if !strings.HasPrefix(value, parts[0]) {
+		return false
+	}
+	value = value[len(parts[0]):]
+
+	for _, part := range parts[1 : len(parts)-1] {
+		i := strings.Index(value, part)
+		if i == -1 {
+			return false
+		}
+		value = value[i+len(part):]
+	}
+
+	return strings.HasSuffix(value, parts[len(parts)-1])
+}
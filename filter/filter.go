@@ -0,0 +1,320 @@
+// Package filter builds Todoist filter queries, escaping names so that they are always matched literally.
+package filter
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	precedenceRaw = iota
+	precedenceOr
+	precedenceAnd
+	precedenceTerm
+)
+
+// Query is a filter query. The zero value is empty and disappears when combined with other queries.
+type Query struct {
+	expr       string
+	precedence int
+}
+
+// Views are queries shown as separate lists, separated by commas. They can't be combined further.
+type Views []Query
+
+// Date is the operand of due date queries.
+type Date string
+
+const (
+	Today     Date = "today"
+	Tomorrow  Date = "tomorrow"
+	Yesterday Date = "yesterday"
+)
+
+// On returns the date of t.
+//
+//goland:noinspection GoUnusedExportedFunction
+func On(t time.Time) Date {
+	return Date(t.Format("2006-01-02"))
+}
+
+// DaysFromNow returns the date n days from today, before today when n is negative.
+//
+//goland:noinspection GoUnusedExportedFunction
+func DaysFromNow(n int) Date {
+	if n < 0 {
+		return Date(strconv.Itoa(n) + " days")
+	}
+
+	return Date("+" + strconv.Itoa(n) + " days")
+}
+
+// Escape puts a backslash before the characters that have a meaning in queries, wildcards and colons
+// included, and before a leading "#", "@" or "/", which would otherwise read as a project, label or section.
+func Escape(name string) string {
+	var b strings.Builder
+	for i, r := range strings.TrimSpace(name) {
+		if strings.ContainsRune(`\&|!(),*:`, r) || i == 0 && strings.ContainsRune("#@/", r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
+
+// region Terms
+
+func term(expr string) Query {
+	return Query{expr: expr, precedence: precedenceTerm}
+}
+
+// Raw wraps a query written by hand, like one with wildcards. It is put in parentheses when combined.
+//
+//goland:noinspection GoUnusedExportedFunction
+func Raw(query string) Query {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return Query{}
+	}
+
+	return Query{expr: query, precedence: precedenceRaw}
+}
+
+// Project matches the tasks of a project, without its subprojects.
+//
+//goland:noinspection GoUnusedExportedFunction
+func Project(name string) Query {
+	return term("#" + Escape(name))
+}
+
+// ProjectTree matches the tasks of a project and its subprojects.
+//
+//goland:noinspection GoUnusedExportedFunction
+func ProjectTree(name string) Query {
+	return term("##" + Escape(name))
+}
+
+//goland:noinspection GoUnusedExportedFunction
+func Section(name string) Query {
+	return term("/" + Escape(name))
+}
+
+//goland:noinspection GoUnusedExportedFunction
+func Label(name string) Query {
+	return term("@" + Escape(name))
+}
+
+// Priority matches a priority as shown in the app, 1 is the highest and 4 the default.
+// Priorities out of range are clamped to 1 or 4.
+//
+//goland:noinspection GoUnusedExportedFunction
+func Priority(priority int) Query {
+	switch {
+	case priority < 1:
+		priority = 1
+	case priority > 4:
+		priority = 4
+	}
+
+	return term("p" + strconv.Itoa(priority))
+}
+
+//goland:noinspection GoUnusedExportedFunction
+func Overdue() Query {
+	return term("overdue")
+}
+
+//goland:noinspection GoUnusedExportedFunction
+func DueToday() Query {
+	return term("today")
+}
+
+//goland:noinspection GoUnusedExportedFunction
+func NoDate() Query {
+	return term("no date")
+}
+
+//goland:noinspection GoUnusedExportedFunction
+func NoTime() Query {
+	return term("no time")
+}
+
+//goland:noinspection GoUnusedExportedFunction
+func Recurring() Query {
+	return term("recurring")
+}
+
+//goland:noinspection GoUnusedExportedFunction
+func NoLabels() Query {
+	return term("no labels")
+}
+
+//goland:noinspection GoUnusedExportedFunction
+func Subtask() Query {
+	return term("subtask")
+}
+
+// Assigned matches tasks assigned to anyone.
+//
+//goland:noinspection GoUnusedExportedFunction
+func Assigned() Query {
+	return term("assigned")
+}
+
+// AssignedTo matches tasks assigned to a collaborator, by name or email.
+//
+//goland:noinspection GoUnusedExportedFunction
+func AssignedTo(name string) Query {
+	return term("assigned to: " + Escape(name))
+}
+
+//goland:noinspection GoUnusedExportedFunction
+func AssignedToMe() Query {
+	return term("assigned to: me")
+}
+
+//goland:noinspection GoUnusedExportedFunction
+func AssignedToOthers() Query {
+	return term("assigned to: others")
+}
+
+// AssignedBy matches tasks assigned by a collaborator, by name or email.
+//
+//goland:noinspection GoUnusedExportedFunction
+func AssignedBy(name string) Query {
+	return term("assigned by: " + Escape(name))
+}
+
+//goland:noinspection GoUnusedExportedFunction
+func AssignedByMe() Query {
+	return term("assigned by: me")
+}
+
+// Search matches tasks whose content contains the text.
+//
+//goland:noinspection GoUnusedExportedFunction
+func Search(text string) Query {
+	return term("search: " + Escape(text))
+}
+
+//goland:noinspection GoUnusedExportedFunction
+func DueOn(date Date) Query {
+	return term("date: " + Escape(string(date)))
+}
+
+//goland:noinspection GoUnusedExportedFunction
+func DueBefore(date Date) Query {
+	return term("due before: " + Escape(string(date)))
+}
+
+//goland:noinspection GoUnusedExportedFunction
+func DueAfter(date Date) Query {
+	return term("due after: " + Escape(string(date)))
+}
+
+// DueBetween matches due dates after from and before to, both excluded.
+//
+//goland:noinspection GoUnusedExportedFunction
+func DueBetween(from Date, to Date) Query {
+	return DueAfter(from).And(DueBefore(to))
+}
+
+// DueWithin matches due dates from today on, for the given number of days. Less than one day means today.
+//
+//goland:noinspection GoUnusedExportedFunction
+func DueWithin(days int) Query {
+	if days < 1 {
+		return DueToday()
+	}
+
+	return term(strconv.Itoa(days) + " days")
+}
+
+// endregion
+
+// region Operators
+
+// And matches the tasks that match every query.
+//
+//goland:noinspection GoUnusedExportedFunction
+func And(queries ...Query) (q Query) {
+	return q.And(queries...)
+}
+
+// Or matches the tasks that match any query.
+//
+//goland:noinspection GoUnusedExportedFunction
+func Or(queries ...Query) (q Query) {
+	return q.Or(queries...)
+}
+
+//goland:noinspection GoUnusedExportedFunction
+func Not(query Query) Query {
+	return query.Not()
+}
+
+func (q Query) And(queries ...Query) Query {
+	return q.join(precedenceAnd, " & ", queries)
+}
+
+func (q Query) Or(queries ...Query) Query {
+	return q.join(precedenceOr, " | ", queries)
+}
+
+func (q Query) Not() Query {
+	if q.expr == "" {
+		return q
+	}
+
+	return term("!" + q.wrap(precedenceTerm))
+}
+
+func (q Query) IsEmpty() bool {
+	return q.expr == ""
+}
+
+func (q Query) String() string {
+	return q.expr
+}
+
+func (v Views) String() string {
+	views := make([]string, 0, len(v))
+	for _, q := range v {
+		if q.expr != "" {
+			views = append(views, q.expr)
+		}
+	}
+
+	return strings.Join(views, ", ")
+}
+
+func (q Query) join(precedence int, op string, queries []Query) Query {
+	result := q
+	for _, other := range queries {
+		switch {
+		case other.expr == "":
+		case result.expr == "":
+			result = other
+		default:
+			result = Query{
+				expr:       result.wrap(precedence) + op + other.wrap(precedence),
+				precedence: precedence,
+			}
+		}
+	}
+
+	return result
+}
+
+// wrap puts the query in parentheses when its operator binds less than the one it is an operand of.
+func (q Query) wrap(precedence int) string {
+	if q.precedence < precedence {
+		return "(" + q.expr + ")"
+	}
+
+	return q.expr
+}
+
+// endregion
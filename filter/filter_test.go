@@ -0,0 +1,148 @@
+package filter_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	todoist "github.com/temoon/todoist-api"
+	"github.com/temoon/todoist-api/filter"
+	"github.com/temoon/todoist-api/todoisttest"
+)
+
+func TestQueryString(t *testing.T) {
+	tests := []struct {
+		name  string
+		query todoist.FilterQuery
+		want  string
+	}{
+		{name: "project", query: filter.Project("Work"), want: "#Work"},
+		{name: "project tree", query: filter.ProjectTree("Work"), want: "##Work"},
+		{name: "escaped name", query: filter.Project("R&D (2024), *new*"), want: `#R\&D \(2024\)\, \*new\*`},
+		{name: "label", query: filter.Label(" errand "), want: "@errand"},
+		{name: "section", query: filter.Section("Drafts"), want: "/Drafts"},
+		{name: "leading hash", query: filter.Project("#Work"), want: `#\#Work`},
+		{name: "leading hash in tree", query: filter.ProjectTree("#Work"), want: `##\#Work`},
+		{name: "hash inside a name", query: filter.Project("C# tips"), want: "#C# tips"},
+		{name: "leading slash", query: filter.Section("/x"), want: `/\/x`},
+		{name: "leading at", query: filter.Label("@home"), want: `@\@home`},
+		{name: "assignee with a colon", query: filter.AssignedTo("Dr: Who"), want: `assigned to: Dr\: Who`},
+		{name: "priority", query: filter.Priority(1), want: "p1"},
+		{name: "priority above range", query: filter.Priority(5), want: "p4"},
+		{name: "priority below range", query: filter.Priority(0), want: "p1"},
+		{name: "within days", query: filter.DueWithin(7), want: "7 days"},
+		{name: "within no days", query: filter.DueWithin(0), want: "today"},
+		{name: "within negative days", query: filter.DueWithin(-3), want: "today"},
+		{name: "due on date", query: filter.DueOn(filter.On(time.Date(2024, 3, 1, 15, 0, 0, 0, time.UTC))), want: "date: 2024-03-01"},
+		{name: "due before offset", query: filter.DueBefore(filter.DaysFromNow(3)), want: "due before: +3 days"},
+		{name: "due after negative offset", query: filter.DueAfter(filter.DaysFromNow(-2)), want: "due after: -2 days"},
+		{name: "due between", query: filter.DueBetween(filter.Yesterday, filter.Tomorrow), want: "due after: yesterday & due before: tomorrow"},
+		{name: "assigned to", query: filter.AssignedTo("Jane Doe"), want: "assigned to: Jane Doe"},
+		{name: "search", query: filter.Search("a|b"), want: `search: a\|b`},
+		{name: "and", query: filter.And(filter.DueToday(), filter.Label("work")), want: "today & @work"},
+		{name: "or inside and", query: filter.And(filter.Or(filter.DueToday(), filter.Overdue()), filter.Priority(1)), want: "(today | overdue) & p1"},
+		{name: "and inside or", query: filter.Or(filter.And(filter.DueToday(), filter.Priority(1)), filter.Overdue()), want: "today & p1 | overdue"},
+		{name: "not term", query: filter.Not(filter.NoDate()), want: "!no date"},
+		{name: "not group", query: filter.Or(filter.DueToday(), filter.Overdue()).Not(), want: "!(today | overdue)"},
+		{name: "raw is grouped", query: filter.Raw("#Work | #Home").And(filter.Priority(2)), want: "(#Work | #Home) & p2"},
+		{name: "empty operands vanish", query: filter.And(filter.Query{}, filter.Overdue(), filter.Raw("  ")), want: "overdue"},
+		{name: "empty", query: filter.Or(), want: ""},
+		{name: "views", query: filter.Views{filter.DueToday(), filter.Query{}, filter.Overdue()}, want: "today, overdue"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.query.String(); got != tt.want {
+				t.Errorf("String() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestQueryParses checks that every built query is understood by ParseFilter and the fake server,
+// and that escaped names match literally.
+func TestQueryParses(t *testing.T) {
+	srv := todoisttest.NewServer()
+	defer srv.Close()
+
+	client := srv.Todoist()
+	ctx := context.Background()
+
+	project, err := client.AddProject(ctx, todoist.MakeAddProjectParams().WithName("R&D (2024)"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	inProject, err := client.AddTask(ctx, todoist.MakeAddTaskParams().WithContent("Prototype").WithProjectId(project.Id).WithPriority(4))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	inInbox, err := client.AddTask(ctx, todoist.MakeAddTaskParams().WithContent("Buy milk").WithLabels([]string{"errand*"}).WithDueString("today"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hashed, err := client.AddProject(ctx, todoist.MakeAddProjectParams().WithName("#Work"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	slashed, err := client.AddSection(ctx, todoist.MakeAddSectionParams().WithName("/x").WithProjectId(hashed.Id))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	inHashed, err := client.AddTask(ctx, todoist.MakeAddTaskParams().WithContent("Deploy").WithProjectId(hashed.Id))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	inSlashed, err := client.AddTask(ctx, todoist.MakeAddTaskParams().WithContent("Review").WithSectionId(slashed.Id).WithLabels([]string{"@home"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		query todoist.FilterQuery
+		want  []string
+	}{
+		{name: "project with a leading hash", query: filter.Project("#Work"), want: []string{inHashed.Id, inSlashed.Id}},
+		{name: "section with a leading slash", query: filter.Section("/x"), want: []string{inSlashed.Id}},
+		{name: "label with a leading at", query: filter.Label("@home"), want: []string{inSlashed.Id}},
+		{name: "escaped project", query: filter.Project("R&D (2024)"), want: []string{inProject.Id}},
+		{name: "escaped wildcard", query: filter.Label("errand*"), want: []string{inInbox.Id}},
+		{name: "literal star does not match as wildcard", query: filter.Label("err*"), want: nil},
+		{name: "combined", query: filter.Or(filter.Priority(1), filter.DueWithin(0)), want: []string{inProject.Id, inInbox.Id}},
+		{name: "negated", query: filter.Project("R&D (2024)").Not().And(filter.DueToday()), want: []string{inInbox.Id}},
+		{name: "views", query: filter.Views{filter.Priority(1), filter.Label("errand*")}, want: []string{inProject.Id, inInbox.Id}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := todoist.ParseFilter(tt.query.String()); err != nil {
+				t.Fatalf("ParseFilter(%q) error = %v", tt.query, err)
+			}
+
+			tasks, err := client.GetTasks(ctx, todoist.MakeGetTasksParams().WithFilterQuery(tt.query))
+			if err != nil {
+				t.Fatalf("GetTasks(%q) error = %v", tt.query, err)
+			}
+
+			var got []string
+			for _, task := range tasks {
+				got = append(got, task.Id)
+			}
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("GetTasks(%q) = %v, want %v", tt.query, got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("GetTasks(%q) = %v, want %v", tt.query, got, tt.want)
+				}
+			}
+		})
+	}
+}
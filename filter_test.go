@@ -0,0 +1,206 @@
+package todoist_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	todoist "github.com/temoon/todoist-api"
+)
+
+func filterFixture() ([]todoist.Task, *todoist.FilterEnv) {
+	env := &todoist.FilterEnv{
+		Now:    time.Date(2024, 3, 13, 10, 0, 0, 0, time.UTC),
+		UserId: "1",
+		Projects: []todoist.Project{
+			{Id: "10", Name: "Home"},
+			{Id: "11", Name: "Work"},
+			{Id: "12", Name: "Office (HQ)", ParentId: "11"},
+		},
+		Sections: []todoist.Section{
+			{Id: "20", ProjectId: "12", Name: "Drafts"},
+		},
+		Collaborators: []todoist.Collaborator{
+			{Id: "1", Name: "Test User", Email: "test@example.com"},
+			{Id: "2", Name: "Jane Doe", Email: "jane@example.com"},
+		},
+	}
+
+	tasks := []todoist.Task{
+		{Id: "1", ProjectId: "10", Content: "Buy milk", Labels: []string{"errand"}, Priority: 4, Due: &todoist.Due{Date: "2024-03-13"}},
+		{Id: "2", ProjectId: "12", SectionId: "20", Content: "Write report", Priority: 3, AssigneeId: "1", AssignerId: "2",
+			Due: &todoist.Due{Date: "2024-03-15", Datetime: "2024-03-15T09:00:00Z", Timezone: "UTC"}},
+		{Id: "3", ProjectId: "10", ParentId: "1", Content: "Ask for *oat* milk", Priority: 1},
+		{Id: "4", ProjectId: "11", Content: "Pay rent", Labels: []string{"bills", "home"}, Priority: 1, AssigneeId: "2",
+			Due: &todoist.Due{Date: "2024-03-10", IsRecurring: true, String: "every month"}},
+	}
+
+	return tasks, env
+}
+
+func TestParseFilterMatch(t *testing.T) {
+	tasks, env := filterFixture()
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{query: "today", want: []string{"1"}},
+		{query: "overdue", want: []string{"4"}},
+		{query: "no date", want: []string{"3"}},
+		{query: "recurring", want: []string{"4"}},
+		{query: "p1", want: []string{"1"}},
+		{query: "p4", want: []string{"3", "4"}},
+		{query: "@errand", want: []string{"1"}},
+		{query: "@b*", want: []string{"4"}},
+		{query: "no labels", want: []string{"2", "3"}},
+		{query: "#Home", want: []string{"1", "3"}},
+		{query: "#Work", want: []string{"4"}},
+		{query: "##Work", want: []string{"2", "4"}},
+		{query: `#Office \(HQ\)`, want: []string{"2"}},
+		{query: "/Drafts", want: []string{"2"}},
+		{query: "subtask", want: []string{"3"}},
+		{query: "assigned to: me", want: []string{"2"}},
+		{query: "assigned to: others", want: []string{"4"}},
+		{query: "assigned to: Jane Doe", want: []string{"4"}},
+		{query: "assigned by: jane@example.com", want: []string{"2"}},
+		{query: "search: milk", want: []string{"1", "3"}},
+		{query: `search: \*oat\*`, want: []string{"3"}},
+		{query: "Search: MILK", want: []string{"1", "3"}},
+		{query: "#\u212a\u212a\u212a: x", want: nil},
+		{query: "3 days", want: []string{"1", "2"}},
+		{query: "due before: today", want: []string{"4"}},
+		{query: "due after: tomorrow", want: []string{"2"}},
+		{query: "date: 2024-03-15", want: []string{"2"}},
+		{query: "due before: +3 days & !overdue", want: []string{"1", "2"}},
+		{query: "(today | overdue) & !@bills", want: []string{"1"}},
+		{query: "!#Home & !no date", want: []string{"2", "4"}},
+		{query: "today, overdue", want: []string{"1", "4"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			f, err := todoist.ParseFilter(tt.query)
+			if err != nil {
+				t.Fatalf("ParseFilter() error = %v", err)
+			}
+
+			var got []string
+			for _, task := range tasks {
+				if f.Match(task, env) {
+					got = append(got, task.Id)
+				}
+			}
+
+			if !equalStrings(got, tt.want) {
+				t.Errorf("Match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseFilterEscapes(t *testing.T) {
+	env := &todoist.FilterEnv{
+		Projects: []todoist.Project{
+			{Id: "10", Name: "Home"},
+			{Id: "11", Name: "#Home"},
+			{Id: "12", Name: "Errands: Q1", ParentId: "10"},
+		},
+		Sections: []todoist.Section{
+			{Id: "20", ProjectId: "10", Name: "/x"},
+		},
+		Collaborators: []todoist.Collaborator{
+			{Id: "2", Name: "Dr: Who", Email: "who@example.com"},
+		},
+	}
+
+	tasks := []todoist.Task{
+		{Id: "1", ProjectId: "10", Content: "Plain"},
+		{Id: "2", ProjectId: "11", Content: "Hashed", Labels: []string{"@home"}},
+		{Id: "3", ProjectId: "12", Content: "a:b", AssigneeId: "2"},
+		{Id: "4", ProjectId: "10", SectionId: "20", Content: "In section", Labels: []string{"home"}},
+	}
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{query: "#Home", want: []string{"1", "4"}},
+		{query: `#\#Home`, want: []string{"2"}},
+		{query: "##Home", want: []string{"1", "3", "4"}},
+		{query: `##\#Home`, want: []string{"2"}},
+		{query: `#Errands\: Q1`, want: []string{"3"}},
+		{query: `/\/x`, want: []string{"4"}},
+		{query: "@home", want: []string{"4"}},
+		{query: `@\@home`, want: []string{"2"}},
+		{query: `assigned to: Dr\: Who`, want: []string{"3"}},
+		{query: `search: a\:b`, want: []string{"3"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			f, err := todoist.ParseFilter(tt.query)
+			if err != nil {
+				t.Fatalf("ParseFilter() error = %v", err)
+			}
+
+			var got []string
+			for _, task := range tasks {
+				if f.Match(task, env) {
+					got = append(got, task.Id)
+				}
+			}
+
+			if !equalStrings(got, tt.want) {
+				t.Errorf("Match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterSelectViews(t *testing.T) {
+	tasks, env := filterFixture()
+
+	f, err := todoist.ParseFilter("overdue, #Home, @nothing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Views() != 3 {
+		t.Fatalf("Views() = %d, want 3", f.Views())
+	}
+
+	views := f.Select(tasks, env)
+	want := [][]string{{"4"}, {"1", "3"}, {}}
+	for i := range want {
+		if got := taskIds(views[i]); !equalStrings(got, want[i]) {
+			t.Errorf("view %d = %v, want %v", i, got, want[i])
+		}
+	}
+}
+
+func TestParseFilterErrors(t *testing.T) {
+	tests := []string{
+		"",
+		"today &",
+		"(today",
+		"today)",
+		"due before: someday",
+		"today overdue,",
+		"& p1",
+		"\u212a\u212a\u212a: x",
+	}
+
+	for _, query := range tests {
+		t.Run(query, func(t *testing.T) {
+			_, err := todoist.ParseFilter(query)
+
+			var filterErr *todoist.FilterError
+			if !errors.As(err, &filterErr) {
+				t.Fatalf("ParseFilter() error = %v, want *FilterError", err)
+			}
+			if filterErr.String != query {
+				t.Errorf("String = %q, want %q", filterErr.String, query)
+			}
+		})
+	}
+}
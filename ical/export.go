@@ -0,0 +1,487 @@
+package ical
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	todoist "github.com/temoon/todoist-api"
+	"github.com/temoon/todoist-api/internal/ordering"
+)
+
+var frequencies = map[todoist.Frequency]string{
+	todoist.Hourly:  "HOURLY",
+	todoist.Daily:   "DAILY",
+	todoist.Weekly:  "WEEKLY",
+	todoist.Monthly: "MONTHLY",
+	todoist.Yearly:  "YEARLY",
+}
+
+var weekdays = [...]string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+type ExportOpts struct {
+	// Component is VTODO by default. Tasks without a due date are skipped for VEVENT.
+	Component Component
+	Name      string
+	ProductId string
+	UidDomain string
+	// Stamp is the DTSTAMP of every component. It defaults to the Unix epoch, so that exporting the
+	// same tasks twice gives the same output; set it to the time of the export to tell versions apart.
+	Stamp time.Time
+	// EventDuration is the length of timed events. Without it they are instants.
+	EventDuration time.Duration
+}
+
+// Export writes tasks as a VCALENDAR, ordered by id. Recurring due strings are translated
+// to RRULE when the recurrence parser understands them and iCalendar can express them.
+// Every timezone used as a TZID comes with its VTIMEZONE.
+func Export(w io.Writer, tasks []todoist.Task, opts *ExportOpts) error {
+	if opts == nil {
+		opts = &ExportOpts{}
+	}
+
+	component := opts.Component
+	if component == "" {
+		component = Todo
+	}
+
+	productId := opts.ProductId
+	if productId == "" {
+		productId = ProductId
+	}
+
+	stamp := opts.Stamp
+	if stamp.IsZero() {
+		stamp = time.Unix(0, 0)
+	}
+
+	sorted := append([]todoist.Task(nil), tasks...)
+	sort.SliceStable(sorted, func(i, j int) bool { return ordering.LessId(sorted[i].Id, sorted[j].Id) })
+
+	e := newEncoder(w)
+	e.property("BEGIN", "", "VCALENDAR")
+	e.property("VERSION", "", "2.0")
+	e.property("PRODID", "", productId)
+	e.property("CALSCALE", "", "GREGORIAN")
+	e.property("METHOD", "", "PUBLISH")
+	if opts.Name != "" {
+		e.property("X-WR-CALNAME", "", escapeText(opts.Name))
+	}
+
+	for _, span := range dueZones(sorted) {
+		writeTimezone(e, span)
+	}
+
+	for i := range sorted {
+		task := &sorted[i]
+		if component == Event && task.Due == nil {
+			continue
+		}
+
+		e.property("BEGIN", "", string(component))
+		e.property("UID", "", uid(task.Id, opts.UidDomain))
+		e.property("DTSTAMP", "", stamp.UTC().Format(datetimeLayout)+"Z")
+		e.property("SUMMARY", "", escapeText(task.Content))
+		if task.Description != "" {
+			e.property("DESCRIPTION", "", escapeText(task.Description))
+		}
+
+		if params, value, ok := dueValue(task.Due); ok {
+			rule := rrule(task.Due)
+
+			switch component {
+			case Event:
+				e.property("DTSTART", params, value)
+				if end, ok := eventEnd(task.Due, opts.EventDuration); ok {
+					e.property("DTEND", params, end)
+				}
+			default:
+				// RRULE is relative to DTSTART, which is otherwise optional for VTODO.
+				if rule != "" {
+					e.property("DTSTART", params, value)
+				}
+				e.property("DUE", params, value)
+			}
+
+			if rule != "" {
+				e.property("RRULE", "", rule)
+			}
+		}
+
+		if component == Todo {
+			if task.IsCompleted {
+				e.property("STATUS", "", "COMPLETED")
+			} else {
+				e.property("STATUS", "", "NEEDS-ACTION")
+			}
+		}
+
+		if priority, ok := priorities[task.Priority]; ok {
+			e.property("PRIORITY", "", strconv.Itoa(priority))
+		}
+
+		if len(task.Labels) != 0 {
+			categories := make([]string, 0, len(task.Labels))
+			for _, label := range task.Labels {
+				categories = append(categories, escapeText(label))
+			}
+			e.property("CATEGORIES", "", strings.Join(categories, ","))
+		}
+
+		if task.Url != "" {
+			e.property("URL", "", task.Url)
+		}
+
+		if task.ParentId != "" {
+			e.property("RELATED-TO", "", uid(task.ParentId, opts.UidDomain))
+		}
+
+		e.property("END", "", string(component))
+	}
+
+	e.property("END", "", "VCALENDAR")
+
+	return e.flush()
+}
+
+// Marshal returns the output of Export.
+func Marshal(tasks []todoist.Task, opts *ExportOpts) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := Export(&buf, tasks, opts); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func uid(id string, domain string) string {
+	if domain == "" {
+		domain = UidDomain
+	}
+
+	return id + "@" + domain
+}
+
+// dueValue formats the due as a DATE, a floating DATE-TIME, a DATE-TIME with the TZID of its timezone
+// or, when the timezone is a bare offset, a DATE-TIME in UTC. Recurrences then follow the local time of the zone.
+func dueValue(due *todoist.Due) (params string, value string, ok bool) {
+	t, err := due.Time(time.UTC)
+	if err != nil {
+		return "", "", false
+	}
+
+	switch loc, named := dueZone(due); {
+	case due.IsAllDay():
+		return ";VALUE=DATE", t.Format(dateLayout), true
+	case due.IsFloating():
+		return "", t.Format(datetimeLayout), true
+	case named:
+		return ";TZID=" + loc.String(), t.In(loc).Format(datetimeLayout), true
+	default:
+		return "", t.Format(datetimeLayout) + "Z", true
+	}
+}
+
+// eventEnd returns DTEND of an event: the next day for all-day events and the start plus duration for timed ones.
+// It has the value type of DTSTART, as formatted by dueValue.
+func eventEnd(due *todoist.Due, duration time.Duration) (string, bool) {
+	t, err := due.Time(time.UTC)
+	if err != nil {
+		return "", false
+	}
+
+	switch loc, named := dueZone(due); {
+	case due.IsAllDay():
+		return t.AddDate(0, 0, 1).Format(dateLayout), true
+	case duration <= 0:
+		return "", false
+	case due.IsFloating():
+		return t.Add(duration).Format(datetimeLayout), true
+	case named:
+		return t.Add(duration).In(loc).Format(datetimeLayout), true
+	default:
+		return t.Add(duration).Format(datetimeLayout) + "Z", true
+	}
+}
+
+// dueZone returns the location of a fixed due when its timezone is a tz database name, which can be a TZID.
+func dueZone(due *todoist.Due) (loc *time.Location, named bool) {
+	loc, err := due.Location()
+	if err != nil || loc == nil || loc == time.UTC {
+		return nil, false
+	}
+
+	return loc, loc.String() == due.Timezone
+}
+
+// rrule translates a recurring due string. Rules counted from completion have no iCalendar equivalent.
+func rrule(due *todoist.Due) string {
+	if !due.IsRecurring {
+		return ""
+	}
+
+	r, err := due.Recurrence()
+	if err != nil || r.FromCompletion {
+		return ""
+	}
+
+	parts := []string{"FREQ=" + frequencies[r.Frequency]}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+
+	if r.Month != 0 {
+		parts = append(parts, "BYMONTH="+strconv.Itoa(int(r.Month)))
+	}
+
+	var byDay []string
+	for _, weekday := range r.Weekdays {
+		byDay = append(byDay, weekdays[weekday])
+	}
+	for _, nth := range r.NthWeekdays {
+		byDay = append(byDay, strconv.Itoa(nth.N)+weekdays[nth.Weekday])
+	}
+	if r.NthWorkday != 0 {
+		byDay = append(byDay, "MO", "TU", "WE", "TH", "FR")
+	}
+	if len(byDay) != 0 {
+		parts = append(parts, "BYDAY="+strings.Join(byDay, ","))
+	}
+
+	if len(r.MonthDays) != 0 {
+		byMonthDay := make([]string, 0, len(r.MonthDays))
+		for _, day := range r.MonthDays {
+			byMonthDay = append(byMonthDay, strconv.Itoa(day))
+		}
+		parts = append(parts, "BYMONTHDAY="+strings.Join(byMonthDay, ","))
+	}
+
+	if r.NthWorkday != 0 {
+		parts = append(parts, "BYSETPOS="+strconv.Itoa(r.NthWorkday))
+	}
+
+	if until, ok := untilValue(due, r.End); ok {
+		parts = append(parts, "UNTIL="+until)
+	}
+
+	return strings.Join(parts, ";")
+}
+
+// untilValue formats the inclusive end date with the value type of DTSTART, as RFC 5545 requires.
+// It is in UTC when DTSTART has a TZID.
+func untilValue(due *todoist.Due, end string) (string, bool) {
+	if end == "" {
+		return "", false
+	}
+
+	loc, err := due.Location()
+	if err != nil {
+		return "", false
+	}
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	date, err := time.ParseInLocation(todoist.DateLayout, end, loc)
+	if err != nil {
+		return "", false
+	}
+
+	last := date.AddDate(0, 0, 1).Add(-time.Second)
+	switch {
+	case due.IsAllDay():
+		return date.Format(dateLayout), true
+	case due.IsFloating():
+		return last.Format(datetimeLayout), true
+	default:
+		return last.UTC().Format(datetimeLayout) + "Z", true
+	}
+}
+
+// region VTIMEZONE
+
+// zoneSpan is a timezone written as a TZID and the years of the dues in it.
+type zoneSpan struct {
+	loc   *time.Location
+	first int
+	last  int
+}
+
+// zoneTransition is a change of the UTC offset of a timezone.
+type zoneTransition struct {
+	at   time.Time
+	from int
+	to   int
+	name string
+}
+
+// dueZones returns the timezones that dueValue writes as a TZID, ordered by name.
+func dueZones(tasks []todoist.Task) (spans []zoneSpan) {
+	index := make(map[string]int)
+	for i := range tasks {
+		due := tasks[i].Due
+		if due == nil || due.IsAllDay() || due.IsFloating() {
+			continue
+		}
+
+		loc, named := dueZone(due)
+		if !named {
+			continue
+		}
+
+		t, err := due.Time(time.UTC)
+		if err != nil {
+			continue
+		}
+
+		year := t.In(loc).Year()
+		if j, ok := index[loc.String()]; ok {
+			if year < spans[j].first {
+				spans[j].first = year
+			}
+			if year > spans[j].last {
+				spans[j].last = year
+			}
+			continue
+		}
+
+		index[loc.String()] = len(spans)
+		spans = append(spans, zoneSpan{loc: loc, first: year, last: year})
+	}
+
+	sort.Slice(spans, func(i, j int) bool { return spans[i].loc.String() < spans[j].loc.String() })
+
+	return
+}
+
+// writeTimezone writes the VTIMEZONE of a span with the offset changes from the year before its first due
+// to the year after its last one. Changes that happen by the same rule every year become a yearly RRULE,
+// so that recurrences keep their local time past the span. The others are listed one by one.
+func writeTimezone(e *encoder, span zoneSpan) {
+	e.property("BEGIN", "", "VTIMEZONE")
+	e.property("TZID", "", span.loc.String())
+
+	transitions := zoneTransitions(span.loc, span.first-1, span.last+1)
+	if len(transitions) == 0 {
+		name, offset := time.Date(span.first, time.January, 1, 0, 0, 0, 0, span.loc).Zone()
+		writeObservance(e, zoneTransition{at: time.Unix(int64(-offset), 0).UTC(), from: offset, to: offset, name: name}, "")
+	}
+
+	years := span.last - span.first + 3
+	counts := make(map[string]int)
+	for _, transition := range transitions {
+		counts[transition.rule()]++
+	}
+
+	written := make(map[string]bool)
+	for _, transition := range transitions {
+		rule := transition.rule()
+		switch {
+		case counts[rule] < years:
+			writeObservance(e, transition, "")
+		case !written[rule]:
+			written[rule] = true
+			writeObservance(e, transition, "FREQ=YEARLY;BYMONTH="+strconv.Itoa(int(transition.wall().Month()))+";BYDAY="+transition.byDay())
+		}
+	}
+
+	e.property("END", "", "VTIMEZONE")
+}
+
+// zoneTransitions finds the offset changes of a timezone in the years from first to last, both included.
+func zoneTransitions(loc *time.Location, first int, last int) (transitions []zoneTransition) {
+	offsetAt := func(unix int64) int {
+		_, offset := time.Unix(unix, 0).In(loc).Zone()
+		return offset
+	}
+
+	const day = 24 * 60 * 60
+	end := time.Date(last+1, time.January, 1, 0, 0, 0, 0, time.UTC).Unix()
+	start := time.Date(first, time.January, 1, 0, 0, 0, 0, time.UTC).Unix()
+
+	offset := offsetAt(start)
+	for lo := start; lo < end; lo += day {
+		next := offsetAt(lo + day)
+		if next == offset {
+			continue
+		}
+
+		from, to := lo, lo+day
+		for to-from > 1 {
+			if mid := (from + to) / 2; offsetAt(mid) == offset {
+				from = mid
+			} else {
+				to = mid
+			}
+		}
+
+		name, _ := time.Unix(to, 0).In(loc).Zone()
+		transitions = append(transitions, zoneTransition{at: time.Unix(to, 0).UTC(), from: offset, to: next, name: name})
+		offset = next
+	}
+
+	return
+}
+
+// wall returns the local time the change happens at, in the offset before it.
+func (t zoneTransition) wall() time.Time {
+	return t.at.Add(time.Duration(t.from) * time.Second)
+}
+
+// byDay returns the weekday of the change within its month, like "2SU" or "-1SU" for the last one.
+func (t zoneTransition) byDay() string {
+	wall := t.wall()
+	days := time.Date(wall.Year(), wall.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+
+	if wall.Day()+7 > days {
+		return "-1" + weekdays[wall.Weekday()]
+	}
+
+	return strconv.Itoa((wall.Day()-1)/7+1) + weekdays[wall.Weekday()]
+}
+
+// rule identifies changes that happen the same way in different years.
+func (t zoneTransition) rule() string {
+	wall := t.wall()
+	return fmt.Sprintf("%d %s %s %d %d %s", wall.Month(), t.byDay(), wall.Format("150405"), t.from, t.to, t.name)
+}
+
+func writeObservance(e *encoder, t zoneTransition, rrule string) {
+	kind := "STANDARD"
+	if t.to > t.from {
+		kind = "DAYLIGHT"
+	}
+
+	e.property("BEGIN", "", kind)
+	e.property("DTSTART", "", t.wall().Format(datetimeLayout))
+	e.property("TZOFFSETFROM", "", formatOffset(t.from))
+	e.property("TZOFFSETTO", "", formatOffset(t.to))
+	if rrule != "" {
+		e.property("RRULE", "", rrule)
+	}
+	if t.name != "" {
+		e.property("TZNAME", "", escapeText(t.name))
+	}
+	e.property("END", "", kind)
+}
+
+// formatOffset formats an offset in seconds as UTC-OFFSET, like "+0100".
+func formatOffset(seconds int) string {
+	sign := "+"
+	if seconds < 0 {
+		sign, seconds = "-", -seconds
+	}
+
+	offset := fmt.Sprintf("%s%02d%02d", sign, seconds/3600, seconds/60%60)
+	if seconds%60 != 0 {
+		offset += fmt.Sprintf("%02d", seconds%60)
+	}
+
+	return offset
+}
+
+// endregion
@@ -0,0 +1,251 @@
+package ical_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	todoist "github.com/temoon/todoist-api"
+	"github.com/temoon/todoist-api/ical"
+)
+
+var stamp = time.Date(2024, 3, 13, 10, 0, 0, 0, time.UTC)
+
+func TestExport(t *testing.T) {
+	tasks := []todoist.Task{
+		{Id: "10", Content: "Buy milk, bread; eggs", Description: "From the shop\nat the corner \\ left", Labels: []string{"errand", "a,b"}, Priority: 4, Due: &todoist.Due{Date: "2024-03-15"}},
+		{Id: "9", Content: "Standup", ParentId: "2", Priority: 1, Due: &todoist.Due{String: "every mon, fri", Date: "2024-03-15", IsRecurring: true}},
+		{Id: "2", Content: "Deploy", IsCompleted: true, Priority: 2, Due: &todoist.Due{Date: "2024-03-15", Datetime: "2024-03-15T09:00:00Z", Timezone: "UTC"}},
+	}
+
+	want := "BEGIN:VCALENDAR\r\n" +
+		"VERSION:2.0\r\n" +
+		"PRODID:" + ical.ProductId + "\r\n" +
+		"CALSCALE:GREGORIAN\r\n" +
+		"METHOD:PUBLISH\r\n" +
+		"X-WR-CALNAME:Work\\, home\r\n" +
+		"BEGIN:VTODO\r\n" +
+		"UID:2@todoist.com\r\n" +
+		"DTSTAMP:19700101T000000Z\r\n" +
+		"SUMMARY:Deploy\r\n" +
+		"DUE:20240315T090000Z\r\n" +
+		"STATUS:COMPLETED\r\n" +
+		"PRIORITY:9\r\n" +
+		"END:VTODO\r\n" +
+		"BEGIN:VTODO\r\n" +
+		"UID:9@todoist.com\r\n" +
+		"DTSTAMP:19700101T000000Z\r\n" +
+		"SUMMARY:Standup\r\n" +
+		"DTSTART;VALUE=DATE:20240315\r\n" +
+		"DUE;VALUE=DATE:20240315\r\n" +
+		"RRULE:FREQ=WEEKLY;BYDAY=MO,FR\r\n" +
+		"STATUS:NEEDS-ACTION\r\n" +
+		"RELATED-TO:2@todoist.com\r\n" +
+		"END:VTODO\r\n" +
+		"BEGIN:VTODO\r\n" +
+		"UID:10@todoist.com\r\n" +
+		"DTSTAMP:19700101T000000Z\r\n" +
+		"SUMMARY:Buy milk\\, bread\\; eggs\r\n" +
+		"DESCRIPTION:From the shop\\nat the corner \\\\ left\r\n" +
+		"DUE;VALUE=DATE:20240315\r\n" +
+		"STATUS:NEEDS-ACTION\r\n" +
+		"PRIORITY:1\r\n" +
+		"CATEGORIES:errand,a\\,b\r\n" +
+		"END:VTODO\r\n" +
+		"END:VCALENDAR\r\n"
+
+	data, err := ical.Marshal(tasks, &ical.ExportOpts{Name: "Work, home"})
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if string(data) != want {
+		t.Errorf("Marshal() =\n%s\nwant\n%s", data, want)
+	}
+}
+
+func TestExportEvents(t *testing.T) {
+	tasks := []todoist.Task{
+		{Id: "1", Content: "Someday"},
+		{Id: "2", Content: "Holiday", Due: &todoist.Due{Date: "2024-03-15"}},
+		{Id: "3", Content: "Call mom", Due: &todoist.Due{Date: "2024-03-15", Datetime: "2024-03-15T18:30:00"}},
+		{Id: "4", Content: "Daily", Due: &todoist.Due{String: "every day ending 2024-03-31", Date: "2024-03-15", Datetime: "2024-03-15T09:00:00Z", Timezone: "UTC", IsRecurring: true}},
+		{Id: "5", Content: "Water plants", Due: &todoist.Due{String: "every! 3 days", Date: "2024-03-15", IsRecurring: true}},
+	}
+
+	data, err := ical.Marshal(tasks, &ical.ExportOpts{Component: ical.Event, Stamp: stamp, EventDuration: 30 * time.Minute})
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	text := string(data)
+
+	for _, want := range []string{
+		"DTSTAMP:20240313T100000Z\r\n",
+		"UID:2@todoist.com\r\nDTSTAMP:20240313T100000Z\r\nSUMMARY:Holiday\r\nDTSTART;VALUE=DATE:20240315\r\nDTEND;VALUE=DATE:20240316\r\nEND:VEVENT\r\n",
+		"DTSTART:20240315T183000\r\nDTEND:20240315T190000\r\n",
+		"DTSTART:20240315T090000Z\r\nDTEND:20240315T093000Z\r\nRRULE:FREQ=DAILY;UNTIL=20240331T235959Z\r\n",
+		"SUMMARY:Water plants\r\nDTSTART;VALUE=DATE:20240315\r\nDTEND;VALUE=DATE:20240316\r\nEND:VEVENT\r\n",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Marshal() has no %q:\n%s", want, text)
+		}
+	}
+
+	for _, unwanted := range []string{"Someday", "STATUS:", "DUE"} {
+		if strings.Contains(text, unwanted) {
+			t.Errorf("Marshal() has %q:\n%s", unwanted, text)
+		}
+	}
+}
+
+func TestExportTimezone(t *testing.T) {
+	if _, err := time.LoadLocation("Europe/Berlin"); err != nil {
+		t.Skip("no tz database:", err)
+	}
+
+	task := todoist.Task{Id: "1", Content: "Standup", Due: &todoist.Due{
+		String:      "every day at 9am ending 2024-03-31",
+		Date:        "2024-03-15",
+		Datetime:    "2024-03-15T08:00:00Z",
+		Timezone:    "Europe/Berlin",
+		IsRecurring: true,
+	}}
+
+	data, err := ical.Marshal([]todoist.Task{task}, &ical.ExportOpts{Component: ical.Event, Stamp: stamp, EventDuration: 30 * time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{
+		"BEGIN:VTIMEZONE\r\n" +
+			"TZID:Europe/Berlin\r\n" +
+			"BEGIN:DAYLIGHT\r\n" +
+			"DTSTART:20230326T020000\r\n" +
+			"TZOFFSETFROM:+0100\r\n" +
+			"TZOFFSETTO:+0200\r\n" +
+			"RRULE:FREQ=YEARLY;BYMONTH=3;BYDAY=-1SU\r\n" +
+			"TZNAME:CEST\r\n" +
+			"END:DAYLIGHT\r\n" +
+			"BEGIN:STANDARD\r\n" +
+			"DTSTART:20231029T030000\r\n" +
+			"TZOFFSETFROM:+0200\r\n" +
+			"TZOFFSETTO:+0100\r\n" +
+			"RRULE:FREQ=YEARLY;BYMONTH=10;BYDAY=-1SU\r\n" +
+			"TZNAME:CET\r\n" +
+			"END:STANDARD\r\n" +
+			"END:VTIMEZONE\r\n",
+		"DTSTART;TZID=Europe/Berlin:20240315T090000\r\n",
+		"DTEND;TZID=Europe/Berlin:20240315T093000\r\n",
+		"RRULE:FREQ=DAILY;UNTIL=20240331T215959Z\r\n",
+		"DTSTAMP:20240313T100000Z\r\n",
+	} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("Marshal() has no %q:\n%s", want, data)
+		}
+	}
+
+	items, err := ical.Parse(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := items[0].Start.Time; !got.Equal(time.Date(2024, 3, 15, 8, 0, 0, 0, time.UTC)) || got.Location().String() != "Europe/Berlin" {
+		t.Errorf("Start = %v, want 08:00 UTC in Europe/Berlin", got)
+	}
+
+	// A reader without the tz database has only the VTIMEZONE to go by.
+	items, err = ical.Parse(bytes.NewReader(bytes.ReplaceAll(data, []byte("Europe/Berlin"), []byte("W. Europe Standard Time"))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := items[0].Start.Time; !got.Equal(time.Date(2024, 3, 15, 8, 0, 0, 0, time.UTC)) {
+		t.Errorf("Start = %v, want 08:00 UTC", got)
+	}
+}
+
+func TestExportTimezones(t *testing.T) {
+	if _, err := time.LoadLocation("Europe/Moscow"); err != nil {
+		t.Skip("no tz database:", err)
+	}
+
+	tasks := []todoist.Task{
+		{Id: "1", Content: "Sushi", Due: &todoist.Due{Date: "2024-03-15", Datetime: "2024-03-15T03:00:00Z", Timezone: "Asia/Tokyo"}},
+		{Id: "2", Content: "Metro", Due: &todoist.Due{Date: "2011-03-15", Datetime: "2011-03-15T06:00:00Z", Timezone: "Europe/Moscow"}},
+		{Id: "3", Content: "Lunch", Due: &todoist.Due{Date: "2024-03-16", Datetime: "2024-03-16T03:00:00Z", Timezone: "Asia/Tokyo"}},
+	}
+
+	data, err := ical.Marshal(tasks, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := string(data)
+
+	for _, want := range []string{
+		"BEGIN:VTIMEZONE\r\n" +
+			"TZID:Asia/Tokyo\r\n" +
+			"BEGIN:STANDARD\r\n" +
+			"DTSTART:19700101T000000\r\n" +
+			"TZOFFSETFROM:+0900\r\n" +
+			"TZOFFSETTO:+0900\r\n" +
+			"TZNAME:JST\r\n" +
+			"END:STANDARD\r\n" +
+			"END:VTIMEZONE\r\n" +
+			"BEGIN:VTIMEZONE\r\n" +
+			"TZID:Europe/Moscow\r\n",
+		"DTSTART:20110327T020000\r\n" +
+			"TZOFFSETFROM:+0300\r\n" +
+			"TZOFFSETTO:+0400\r\n" +
+			"TZNAME:MSK\r\n",
+		"DUE;TZID=Asia/Tokyo:20240315T120000\r\n",
+		"DUE;TZID=Europe/Moscow:20110315T090000\r\n",
+		"DTSTAMP:19700101T000000Z\r\n",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Marshal() has no %q:\n%s", want, text)
+		}
+	}
+
+	if n := strings.Count(text, "BEGIN:VTIMEZONE"); n != 2 {
+		t.Errorf("Marshal() has %d VTIMEZONEs, want 2:\n%s", n, text)
+	}
+	if strings.Contains(text, "FREQ=YEARLY") {
+		t.Errorf("Marshal() has yearly rules for one-off changes:\n%s", text)
+	}
+}
+
+func TestExportFolding(t *testing.T) {
+	summary := strings.Repeat("Grüße ", 30)
+
+	data, err := ical.Marshal([]todoist.Task{{Id: "1", Content: summary}}, &ical.ExportOpts{Stamp: stamp})
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(string(data), "\r\n"), "\r\n")
+
+	var unfolded []string
+	for _, line := range lines {
+		if len(line) > 75 {
+			t.Errorf("line longer than 75 octets: %q", line)
+		}
+		if !utf8.ValidString(line) {
+			t.Errorf("line splits a UTF-8 sequence: %q", line)
+		}
+
+		if strings.HasPrefix(line, " ") {
+			unfolded[len(unfolded)-1] += line[1:]
+		} else {
+			unfolded = append(unfolded, line)
+		}
+	}
+
+	found := false
+	for _, line := range unfolded {
+		if line == "SUMMARY:"+summary {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("unfolded lines have no SUMMARY:%s\n%s", summary, data)
+	}
+}
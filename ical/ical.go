@@ -0,0 +1,99 @@
+// Package ical converts tasks to and from iCalendar (RFC 5545).
+package ical
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	ProductId = "-//temoon//todoist-api//EN"
+	UidDomain = "todoist.com"
+)
+
+type Component string
+
+const (
+	Todo  Component = "VTODO"
+	Event Component = "VEVENT"
+)
+
+const (
+	dateLayout     = "20060102"
+	datetimeLayout = "20060102T150405"
+	maxLineLength  = 75
+)
+
+// Priorities of Todoist (4 is p1) mapped to PRIORITY, where 1 is the highest and 0 is undefined.
+var priorities = map[int]int{4: 1, 3: 5, 2: 9}
+
+type encoder struct {
+	w   *bufio.Writer
+	err error
+}
+
+// property writes a content line, folded to 75 octets without splitting UTF-8 sequences.
+// Continuation lines start with a space, which counts towards their length.
+func (e *encoder) property(name string, params string, value string) {
+	if e.err != nil {
+		return
+	}
+
+	line := name + params + ":" + value
+	for limit := maxLineLength; len(line) > limit; limit = maxLineLength - 1 {
+		cut := limit
+		for !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+
+		if _, e.err = e.w.WriteString(line[:cut] + "\r\n "); e.err != nil {
+			return
+		}
+		line = line[cut:]
+	}
+
+	_, e.err = e.w.WriteString(line + "\r\n")
+}
+
+func newEncoder(w io.Writer) *encoder {
+	return &encoder{w: bufio.NewWriter(w)}
+}
+
+func (e *encoder) flush() error {
+	if e.err != nil {
+		return e.err
+	}
+
+	return e.w.Flush()
+}
+
+func escapeText(value string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+	).Replace(value)
+}
+
+func unescapeText(value string) string {
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] != '\\' || i+1 == len(value) {
+			b.WriteByte(value[i])
+			continue
+		}
+
+		i++
+		if value[i] == 'n' || value[i] == 'N' {
+			b.WriteByte('\n')
+		} else {
+			b.WriteByte(value[i])
+		}
+	}
+
+	return b.String()
+}
@@ -0,0 +1,400 @@
+package ical_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	todoist "github.com/temoon/todoist-api"
+	"github.com/temoon/todoist-api/ical"
+	"github.com/temoon/todoist-api/todoisttest"
+)
+
+func TestExportParseRoundTrip(t *testing.T) {
+	tests := []struct {
+		name      string
+		component ical.Component
+		task      todoist.Task
+		want      ical.Item
+		wantDate  string
+	}{
+		{
+			name: "escaped text",
+			task: todoist.Task{Id: "1", Content: "Buy milk, bread; eggs", Description: "From the shop\nat the corner \\ left", Labels: []string{"errand", "a,b"}, Priority: 4},
+			want: ical.Item{Component: ical.Todo, Uid: "1@todoist.com", Summary: "Buy milk, bread; eggs", Description: "From the shop\nat the corner \\ left", Categories: []string{"errand", "a,b"}, Priority: 1, Status: "NEEDS-ACTION"},
+		},
+		{
+			name: "long folded summary",
+			task: todoist.Task{Id: "2", Content: strings.Repeat("Grüße ", 30), IsCompleted: true},
+			want: ical.Item{Component: ical.Todo, Uid: "2@todoist.com", Summary: strings.Repeat("Grüße ", 30), Status: "COMPLETED"},
+		},
+		{
+			name:     "all day",
+			task:     todoist.Task{Id: "3", Content: "Pay rent", Due: &todoist.Due{Date: "2024-03-15"}},
+			want:     ical.Item{Component: ical.Todo, Uid: "3@todoist.com", Summary: "Pay rent", Status: "NEEDS-ACTION"},
+			wantDate: "2024-03-15 all day",
+		},
+		{
+			name:     "floating",
+			task:     todoist.Task{Id: "4", Content: "Call mom", Due: &todoist.Due{Date: "2024-03-15", Datetime: "2024-03-15T18:30:00"}},
+			want:     ical.Item{Component: ical.Todo, Uid: "4@todoist.com", Summary: "Call mom", Status: "NEEDS-ACTION"},
+			wantDate: "2024-03-15T18:30:00Z floating",
+		},
+		{
+			name:     "utc",
+			task:     todoist.Task{Id: "5", Content: "Deploy", Due: &todoist.Due{Date: "2024-03-15", Datetime: "2024-03-15T09:00:00Z", Timezone: "UTC"}},
+			want:     ical.Item{Component: ical.Todo, Uid: "5@todoist.com", Summary: "Deploy", Status: "NEEDS-ACTION"},
+			wantDate: "2024-03-15T09:00:00Z",
+		},
+		{
+			name:      "recurring event",
+			component: ical.Event,
+			task:      todoist.Task{Id: "6", Content: "Standup", ParentId: "5", Due: &todoist.Due{String: "every mon, fri", Date: "2024-03-15", IsRecurring: true}},
+			want:      ical.Item{Component: ical.Event, Uid: "6@todoist.com", Summary: "Standup", RRule: "FREQ=WEEKLY;BYDAY=MO,FR"},
+			wantDate:  "2024-03-15 all day",
+		},
+		{
+			name:     "recurring from completion has no rule",
+			task:     todoist.Task{Id: "7", Content: "Water plants", Due: &todoist.Due{String: "every! 3 days", Date: "2024-03-15", IsRecurring: true}},
+			want:     ical.Item{Component: ical.Todo, Uid: "7@todoist.com", Summary: "Water plants", Status: "NEEDS-ACTION"},
+			wantDate: "2024-03-15 all day",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := ical.Marshal([]todoist.Task{tt.task}, &ical.ExportOpts{Component: tt.component, Stamp: stamp})
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+
+			for _, line := range strings.Split(string(data), "\r\n") {
+				if len(line) > 75 {
+					t.Errorf("line longer than 75 octets: %q", line)
+				}
+			}
+
+			items, err := ical.Parse(bytes.NewReader(data))
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if len(items) != 1 {
+				t.Fatalf("Parse() = %d items, want 1", len(items))
+			}
+
+			got := items[0]
+			if got.Err != nil {
+				t.Fatalf("Parse() item error = %v", got.Err)
+			}
+			if got.Component != tt.want.Component || got.Uid != tt.want.Uid || got.Summary != tt.want.Summary ||
+				got.Description != tt.want.Description || !equalStrings(got.Categories, tt.want.Categories) ||
+				got.Priority != tt.want.Priority || got.Status != tt.want.Status || got.RRule != tt.want.RRule {
+				t.Errorf("Parse() = %+v, want %+v", got, tt.want)
+			}
+
+			date := got.Due
+			if date == nil {
+				date = got.Start
+			}
+			if gotDate := formatDate(date); gotDate != tt.wantDate {
+				t.Errorf("date = %q, want %q", gotDate, tt.wantDate)
+			}
+		})
+	}
+}
+
+func TestParseDates(t *testing.T) {
+	const windowsZone = "BEGIN:VTIMEZONE\r\n" +
+		"TZID:W. Europe Standard Time\r\n" +
+		"BEGIN:STANDARD\r\n" +
+		"DTSTART:16010101T030000\r\n" +
+		"TZOFFSETFROM:+0200\r\n" +
+		"TZOFFSETTO:+0100\r\n" +
+		"RRULE:FREQ=YEARLY;BYDAY=-1SU;BYMONTH=10\r\n" +
+		"END:STANDARD\r\n" +
+		"BEGIN:DAYLIGHT\r\n" +
+		"DTSTART:16010101T020000\r\n" +
+		"TZOFFSETFROM:+0100\r\n" +
+		"TZOFFSETTO:+0200\r\n" +
+		"RRULE:FREQ=YEARLY;BYDAY=-1SU;BYMONTH=3\r\n" +
+		"END:DAYLIGHT\r\n" +
+		"END:VTIMEZONE\r\n"
+
+	tests := []struct {
+		name     string
+		timezone string
+		trailing bool
+		due      string
+		want     string
+		wantErr  bool
+	}{
+		{name: "date", due: "DUE;VALUE=DATE:20240315", want: "2024-03-15 all day"},
+		{name: "utc", due: "DUE:20240315T090000Z", want: "2024-03-15T09:00:00Z"},
+		{name: "floating", due: "DUE:20240315T090000", want: "2024-03-15T09:00:00Z floating"},
+		{name: "vtimezone in winter", timezone: windowsZone, due: "DUE;TZID=W. Europe Standard Time:20240115T090000", want: "2024-01-15T09:00:00+01:00"},
+		{name: "vtimezone in summer", timezone: windowsZone, due: "DUE;TZID=W. Europe Standard Time:20240715T090000", want: "2024-07-15T09:00:00+02:00"},
+		{name: "vtimezone after the component", timezone: windowsZone, trailing: true, due: "DUE;TZID=\"W. Europe Standard Time\":20240715T090000", want: "2024-07-15T09:00:00+02:00"},
+		{name: "unknown tzid is floating", due: "DUE;TZID=Nowhere:20240315T090000", want: "2024-03-15T09:00:00Z floating"},
+		{name: "bad date", due: "DUE;VALUE=DATE:2024-03-15", wantErr: true},
+		{name: "bad datetime", due: "DUE:20240315T25", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			todo := "BEGIN:VTODO\r\nUID:1\r\nSUMMARY:Task\r\n" + tt.due + "\r\nEND:VTODO\r\n"
+
+			calendar := "BEGIN:VCALENDAR\r\n" + tt.timezone + todo
+			if tt.trailing {
+				calendar = "BEGIN:VCALENDAR\r\n" + todo + tt.timezone
+			}
+			calendar += "BEGIN:VTODO\r\nUID:2\r\nSUMMARY:Other\r\nEND:VTODO\r\nEND:VCALENDAR\r\n"
+
+			items, err := ical.Parse(strings.NewReader(calendar))
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if len(items) != 2 {
+				t.Fatalf("Parse() = %d items, want 2", len(items))
+			}
+			if items[1].Err != nil {
+				t.Errorf("error of the other item = %v", items[1].Err)
+			}
+
+			if tt.wantErr {
+				if items[0].Err == nil {
+					t.Errorf("Parse() item error = nil, Due = %v", items[0].Due)
+				}
+				return
+			}
+			if items[0].Err != nil {
+				t.Fatalf("Parse() item error = %v", items[0].Err)
+			}
+			if got := formatDate(items[0].Due); got != tt.want {
+				t.Errorf("Due = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestImport(t *testing.T) {
+	srv := todoisttest.NewServer()
+	defer srv.Close()
+
+	client := srv.Todoist()
+	ctx := context.Background()
+
+	project, err := client.AddProject(ctx, todoist.MakeAddProjectParams().WithName("Calendar"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const calendar = "BEGIN:VCALENDAR\r\n" +
+		"BEGIN:VTODO\r\nUID:a\r\nSUMMARY:Pay rent\r\nDUE;VALUE=DATE:20240401\r\nPRIORITY:1\r\nCATEGORIES:bills\r\nEND:VTODO\r\n" +
+		"BEGIN:VEVENT\r\nUID:b\r\nSUMMARY:Standup\r\nDTSTART:20240315T090000Z\r\nRRULE:FREQ=WEEKLY;BYDAY=MO,FR\r\nEND:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\nUID:b\r\nRECURRENCE-ID:20240318T090000Z\r\nSUMMARY:Standup moved\r\nDTSTART:20240318T100000Z\r\nEND:VEVENT\r\n" +
+		"BEGIN:VTODO\r\nUID:c\r\nSUMMARY:Done already\r\nSTATUS:COMPLETED\r\nEND:VTODO\r\n" +
+		"BEGIN:VTODO\r\nUID:d\r\nSUMMARY:Bad date\r\nDUE:tomorrow\r\nEND:VTODO\r\n" +
+		"BEGIN:VTODO\r\nSUMMARY:No uid\r\nEND:VTODO\r\n" +
+		"BEGIN:VTODO\r\nUID:e\r\nSUMMARY:Counted\r\nDTSTART;VALUE=DATE:20240315\r\nRRULE:FREQ=DAILY;COUNT=3\r\nEND:VTODO\r\n" +
+		"END:VCALENDAR\r\n"
+
+	type outcome struct {
+		uid    string
+		action ical.Action
+	}
+
+	tests := []struct {
+		name   string
+		opts   ical.ImportOpts
+		want   []outcome
+		tasks  int
+		before func(t *testing.T)
+	}{
+		{
+			name: "dry run",
+			opts: ical.ImportOpts{ProjectId: project.Id, Location: time.UTC, DryRun: true},
+			want: []outcome{
+				{"a", ical.Created}, {"b", ical.Created}, {"b", ical.Skipped}, {"c", ical.Skipped},
+				{"d", ical.Failed}, {"", ical.Failed}, {"e", ical.Created},
+			},
+		},
+		{
+			name: "first import",
+			opts: ical.ImportOpts{ProjectId: project.Id, Location: time.UTC},
+			want: []outcome{
+				{"a", ical.Created}, {"b", ical.Created}, {"b", ical.Skipped}, {"c", ical.Skipped},
+				{"d", ical.Failed}, {"", ical.Failed}, {"e", ical.Created},
+			},
+			tasks: 3,
+		},
+		{
+			name: "second import",
+			opts: ical.ImportOpts{ProjectId: project.Id, Location: time.UTC},
+			want: []outcome{
+				{"a", ical.Skipped}, {"b", ical.Skipped}, {"b", ical.Skipped}, {"c", ical.Skipped},
+				{"d", ical.Failed}, {"", ical.Failed}, {"e", ical.Skipped},
+			},
+			tasks: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := ical.Import(ctx, client, strings.NewReader(calendar), &tt.opts)
+			if err != nil {
+				t.Fatalf("Import() error = %v", err)
+			}
+
+			if len(report.Entries) != len(tt.want) {
+				t.Fatalf("Import() = %d entries, want %d", len(report.Entries), len(tt.want))
+			}
+			for i, want := range tt.want {
+				entry := report.Entries[i]
+				if entry.Uid != want.uid || entry.Action != want.action {
+					t.Errorf("entry %d = %s %s (%s, %v), want %s %s", i, entry.Uid, entry.Action, entry.Reason, entry.Err, want.uid, want.action)
+				}
+			}
+
+			tasks, err := client.GetTasks(ctx, todoist.MakeGetTasksParams().WithProjectId(project.Id))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(tasks) != tt.tasks {
+				t.Errorf("project has %d tasks, want %d", len(tasks), tt.tasks)
+			}
+		})
+	}
+
+	tasks, err := client.GetTasks(ctx, todoist.MakeGetTasksParams().WithProjectId(project.Id))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	byUid := make(map[string]todoist.Task)
+	for _, task := range tasks {
+		byUid[ical.MarkedUid(task.Description)] = task
+	}
+
+	if rent := byUid["a"]; rent.Priority != 4 || rent.Due == nil || rent.Due.Date != "2024-04-01" || !equalStrings(rent.Labels, []string{"bills"}) {
+		t.Errorf("imported a = %+v", rent)
+	}
+	if standup := byUid["b"]; standup.Due == nil || !standup.Due.IsRecurring {
+		t.Errorf("imported b = %+v, want a recurring due", standup)
+	}
+	if counted := byUid["e"]; counted.Due == nil || counted.Due.IsRecurring || counted.Due.Date != "2024-03-15" {
+		t.Errorf("imported e = %+v, want a single date", counted)
+	}
+
+	// Completed tasks are not listed, Known marks them as imported.
+	rent := byUid["a"]
+	if err = client.CloseTask(ctx, rent.Id); err != nil {
+		t.Fatal(err)
+	}
+	rent.IsCompleted = true
+
+	report, err := ical.Import(ctx, client, strings.NewReader(calendar), &ical.ImportOpts{ProjectId: project.Id, Location: time.UTC, Known: []todoist.Task{rent}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := report.Count(ical.Created); got != 0 {
+		t.Errorf("Count(Created) with Known = %d, want 0", got)
+	}
+}
+
+func TestExportImportRoundTrip(t *testing.T) {
+	srv := todoisttest.NewServer()
+	defer srv.Close()
+
+	client := srv.Todoist()
+	ctx := context.Background()
+
+	source := []todoist.Task{
+		{Id: "1", Content: "Pay rent", Description: "Transfer, not cash", Labels: []string{"bills"}, Priority: 4, Due: &todoist.Due{Date: "2024-04-01"}},
+		{Id: "2", Content: "Deploy", Priority: 3, Due: &todoist.Due{Date: "2024-03-15", Datetime: "2024-03-15T09:00:00Z", Timezone: "UTC"}},
+		{Id: "3", Content: "Standup", Priority: 1, Due: &todoist.Due{String: "every mon, fri", Date: "2024-03-15", IsRecurring: true}},
+		{Id: "4", Content: "Someday", Priority: 1},
+	}
+
+	data, err := ical.Marshal(source, &ical.ExportOpts{Stamp: stamp})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := ical.Import(ctx, client, bytes.NewReader(data), &ical.ImportOpts{Location: time.UTC})
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+	if got := report.Count(ical.Created); got != len(source) {
+		t.Fatalf("Count(Created) = %d, want %d: %+v", got, len(source), report.Entries)
+	}
+
+	tasks, err := client.GetTasks(ctx, todoist.MakeGetTasksParams())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	imported := make(map[string]todoist.Task)
+	for _, task := range tasks {
+		imported[ical.MarkedUid(task.Description)] = task
+	}
+
+	for _, want := range source {
+		got, ok := imported[want.Id+"@"+ical.UidDomain]
+		if !ok {
+			t.Errorf("task %s was not imported", want.Id)
+			continue
+		}
+
+		description := strings.TrimSuffix(strings.TrimSuffix(got.Description, ical.UidMarker+want.Id+"@"+ical.UidDomain), "\n\n")
+		if got.Content != want.Content || description != want.Description || got.Priority != want.Priority || !equalStrings(got.Labels, want.Labels) {
+			t.Errorf("task %s = %+v, want %+v", want.Id, got, want)
+		}
+
+		switch {
+		case want.Due == nil:
+			if got.Due != nil {
+				t.Errorf("task %s Due = %+v, want none", want.Id, got.Due)
+			}
+		case got.Due == nil:
+			t.Errorf("task %s has no due", want.Id)
+		case want.Due.IsRecurring:
+			// The fake resolves the date of due strings against its own clock.
+			if !got.Due.IsRecurring || got.Due.String != want.Due.String+" starting "+want.Due.Date {
+				t.Errorf("task %s Due = %+v, want %+v", want.Id, got.Due, want.Due)
+			}
+		case got.Due.Date != want.Due.Date:
+			t.Errorf("task %s Due = %+v, want %+v", want.Id, got.Due, want.Due)
+		case want.Due.Datetime != "" && got.Due.Datetime != want.Due.Datetime:
+			t.Errorf("task %s Datetime = %q, want %q", want.Id, got.Due.Datetime, want.Due.Datetime)
+		}
+	}
+}
+
+func formatDate(date *ical.Date) string {
+	switch {
+	case date == nil:
+		return ""
+	case date.AllDay:
+		return date.Time.Format("2006-01-02") + " all day"
+	case date.Floating:
+		return date.Time.Format(time.RFC3339) + " floating"
+	default:
+		return date.Time.Format(time.RFC3339)
+	}
+}
+
+func equalStrings(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,438 @@
+package ical
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	todoist "github.com/temoon/todoist-api"
+)
+
+// UidMarker starts the last line of the description of imported tasks. It carries the UID
+// of the calendar component, so that importing the same calendar again skips it.
+const UidMarker = "ical-uid: "
+
+type Action string
+
+const (
+	Created Action = "created"
+	Skipped Action = "skipped"
+	Failed  Action = "failed"
+)
+
+var (
+	ErrMissingUid     = errors.New("component has no UID")
+	ErrMissingSummary = errors.New("component has no SUMMARY")
+)
+
+var rruleWeekdays = map[string]string{
+	"MO": "mon", "TU": "tue", "WE": "wed", "TH": "thu", "FR": "fri", "SA": "sat", "SU": "sun",
+}
+
+type ImportOpts struct {
+	ProjectId string
+	SectionId string
+	// Location of floating times and of recurring due strings, time.Local by default.
+	Location *time.Location
+	// DryRun reports what would be created without creating anything.
+	DryRun bool
+	// Known are tasks checked for UID markers besides the active tasks of the project, like
+	// completed tasks from the sync API, which the REST API doesn't list.
+	Known []todoist.Task
+}
+
+// ImportEntry is the outcome for one calendar component. Created entries of a dry run have no TaskId.
+type ImportEntry struct {
+	Uid     string
+	Summary string
+	Action  Action
+	Reason  string
+	Params  *todoist.AddTaskParams
+	TaskId  string
+	Err     error
+}
+
+type ImportReport struct {
+	DryRun  bool
+	Entries []ImportEntry
+}
+
+func (r *ImportReport) Count(action Action) (count int) {
+	for _, entry := range r.Entries {
+		if entry.Action == action {
+			count++
+		}
+	}
+
+	return
+}
+
+// Import creates a task for every todo and event of the calendar. Components whose UID is already
+// marked in a task description of the project are skipped, as are completed todos and overrides
+// of single occurrences. Only active tasks are listed, so components imported earlier whose task
+// was completed since are created again unless the completed tasks are passed in opts.Known.
+// Failures, including dates that can't be read, are reported per component, the error is only set
+// when the calendar can't be read, the existing tasks can't be listed or ctx is done.
+func Import(ctx context.Context, t *todoist.Todoist, r io.Reader, opts *ImportOpts) (report *ImportReport, err error) {
+	if opts == nil {
+		opts = &ImportOpts{}
+	}
+
+	var items []Item
+	if items, err = Parse(r); err != nil {
+		return
+	}
+
+	params := todoist.MakeGetTasksParams()
+	if opts.ProjectId != "" {
+		params.WithProjectId(opts.ProjectId)
+	}
+
+	var tasks []todoist.Task
+	if tasks, err = t.GetTasks(ctx, params); err != nil {
+		return
+	}
+
+	imported := make(map[string]string)
+	for _, task := range append(tasks, opts.Known...) {
+		if uid := MarkedUid(task.Description); uid != "" {
+			imported[uid] = task.Id
+		}
+	}
+
+	report = &ImportReport{DryRun: opts.DryRun}
+	for _, item := range items {
+		entry := ImportEntry{
+			Uid:     item.Uid,
+			Summary: item.Summary,
+		}
+
+		taskId, seen := imported[item.Uid]
+
+		switch {
+		case item.Uid == "":
+			entry.Action, entry.Err = Failed, ErrMissingUid
+		case item.Err != nil:
+			entry.Action, entry.Err = Failed, item.Err
+		case item.RecurrenceId != "":
+			entry.Action, entry.Reason = Skipped, "overrides a single occurrence"
+		case seen:
+			entry.Action, entry.Reason, entry.TaskId = Skipped, "already imported", taskId
+		case item.Status == "COMPLETED" || item.Status == "CANCELLED":
+			entry.Action, entry.Reason = Skipped, strings.ToLower(item.Status)
+		default:
+			if entry.Params, entry.Reason, entry.Err = item.params(opts); entry.Err != nil {
+				entry.Action = Failed
+				break
+			}
+
+			entry.Action = Created
+			if opts.DryRun {
+				imported[item.Uid] = ""
+				break
+			}
+
+			var task *todoist.Task
+			if task, entry.Err = t.AddTask(ctx, entry.Params); entry.Err != nil {
+				if err = ctx.Err(); err != nil {
+					return
+				}
+				entry.Action = Failed
+				break
+			}
+
+			entry.TaskId = task.Id
+			imported[item.Uid] = task.Id
+		}
+
+		report.Entries = append(report.Entries, entry)
+	}
+
+	return
+}
+
+// MarkedUid returns the UID marked in the description of an imported task.
+func MarkedUid(description string) string {
+	lines := strings.Split(strings.TrimRight(description, "\n"), "\n")
+	if last := lines[len(lines)-1]; strings.HasPrefix(last, UidMarker) {
+		return strings.TrimSpace(strings.TrimPrefix(last, UidMarker))
+	}
+
+	return ""
+}
+
+// params maps the component to AddTask parameters. The reason explains
+// a recurrence that had to be dropped.
+func (item *Item) params(opts *ImportOpts) (params *todoist.AddTaskParams, reason string, err error) {
+	if strings.TrimSpace(item.Summary) == "" {
+		return nil, "", ErrMissingSummary
+	}
+
+	loc := opts.Location
+	if loc == nil {
+		loc = time.Local
+	}
+
+	description := UidMarker + item.Uid
+	if item.Description != "" {
+		description = item.Description + "\n\n" + description
+	}
+
+	params = todoist.MakeAddTaskParams().
+		WithContent(item.Summary).
+		WithDescription(description)
+
+	if opts.ProjectId != "" {
+		params.WithProjectId(opts.ProjectId)
+	}
+
+	if opts.SectionId != "" {
+		params.WithSectionId(opts.SectionId)
+	}
+
+	if len(item.Categories) != 0 {
+		params.WithLabels(item.Categories)
+	}
+
+	switch {
+	case item.Priority >= 1 && item.Priority <= 4:
+		params.WithPriority(4)
+	case item.Priority == 5:
+		params.WithPriority(3)
+	case item.Priority >= 6 && item.Priority <= 9:
+		params.WithPriority(2)
+	}
+
+	date := item.Due
+	if date == nil || item.Component == Event {
+		date = item.Start
+	}
+	if date == nil {
+		return
+	}
+
+	if item.RRule != "" {
+		if dueString, ok := recurrence(item.RRule, date, loc); ok {
+			params.WithDueString(dueString)
+			return
+		}
+		reason = "recurrence can't be expressed, imported as a single date"
+	}
+
+	switch {
+	case date.AllDay:
+		params.WithDueDate(date.Time.Format(todoist.DateLayout))
+	case date.Floating:
+		wall := date.Time
+		params.WithDueDatetime(time.Date(wall.Year(), wall.Month(), wall.Day(), wall.Hour(), wall.Minute(), wall.Second(), 0, loc).UTC().Format(time.RFC3339))
+	default:
+		params.WithDueDatetime(date.Time.UTC().Format(time.RFC3339))
+	}
+
+	return
+}
+
+// recurrence translates an RRULE into a Todoist due string anchored at the start date. It fails for rules
+// Todoist can't express, like COUNT, and the result is checked with todoist.ParseRecurrence.
+func recurrence(rule string, start *Date, loc *time.Location) (dueString string, ok bool) {
+	parts := make(map[string]string)
+	for _, part := range strings.Split(rule, ";") {
+		i := strings.IndexByte(part, '=')
+		if i == -1 {
+			return "", false
+		}
+		parts[strings.ToUpper(part[:i])] = strings.ToUpper(part[i+1:])
+	}
+
+	for key := range parts {
+		switch key {
+		case "FREQ", "INTERVAL", "BYDAY", "BYMONTHDAY", "BYMONTH", "BYSETPOS", "UNTIL", "WKST":
+		default:
+			return "", false
+		}
+	}
+
+	interval := 1
+	if value, ok := parts["INTERVAL"]; ok {
+		var err error
+		if interval, err = strconv.Atoi(value); err != nil || interval < 1 {
+			return "", false
+		}
+	}
+
+	var days []string
+	var nth bool
+	for _, value := range splitNonEmpty(parts["BYDAY"]) {
+		if len(value) < 2 {
+			return "", false
+		}
+
+		weekday, ok := rruleWeekdays[value[len(value)-2:]]
+		if !ok {
+			return "", false
+		}
+
+		if prefix := value[:len(value)-2]; prefix != "" {
+			n, err := strconv.Atoi(strings.TrimPrefix(prefix, "+"))
+			if err != nil || n == 0 || n < -1 {
+				return "", false
+			}
+			weekday = ordinal(n) + " " + weekday
+			nth = true
+		}
+
+		days = append(days, weekday)
+	}
+
+	var monthDays []string
+	for _, value := range splitNonEmpty(parts["BYMONTHDAY"]) {
+		n, err := strconv.Atoi(value)
+		if err != nil || n == 0 || n < -1 || n > 31 {
+			return "", false
+		}
+		monthDays = append(monthDays, ordinal(n))
+	}
+	for i, day := range monthDays {
+		if day == "last" {
+			monthDays[i] = "last day"
+		}
+	}
+
+	every := func(unit string, on []string) string {
+		s := "every " + unit
+		if interval > 1 {
+			s = "every " + strconv.Itoa(interval) + " " + unit + "s"
+		}
+		if len(on) != 0 {
+			s += " on the " + strings.Join(on, ", ")
+		}
+		return s
+	}
+
+	switch parts["FREQ"] {
+	case "DAILY":
+		switch {
+		case len(monthDays) != 0 || nth || parts["BYMONTH"] != "" || parts["BYSETPOS"] != "":
+			return "", false
+		case len(days) != 0 && interval > 1:
+			return "", false
+		case len(days) != 0:
+			dueString = "every " + strings.Join(days, ", ")
+		default:
+			dueString = every("day", nil)
+		}
+	case "WEEKLY":
+		switch {
+		case len(monthDays) != 0 || nth || parts["BYMONTH"] != "" || parts["BYSETPOS"] != "":
+			return "", false
+		case len(days) != 0 && interval == 1:
+			dueString = "every " + strings.Join(days, ", ")
+		case len(days) != 0:
+			dueString = every("week", nil) + " on " + strings.Join(days, ", ")
+		default:
+			dueString = every("week", nil)
+		}
+	case "MONTHLY":
+		if parts["BYMONTH"] != "" {
+			return "", false
+		}
+
+		if value := parts["BYSETPOS"]; value != "" {
+			if parts["BYDAY"] != "MO,TU,WE,TH,FR" || len(monthDays) != 0 || (value != "1" && value != "-1") {
+				return "", false
+			}
+			position, _ := strconv.Atoi(value)
+			days = []string{ordinal(position) + " workday"}
+		} else if len(days) != 0 && !nth {
+			return "", false
+		}
+
+		on := append(monthDays, days...)
+		if interval == 1 && len(on) != 0 {
+			dueString = "every " + strings.Join(on, ", ")
+		} else {
+			dueString = every("month", on)
+		}
+	case "YEARLY":
+		month, monthDay := parts["BYMONTH"], parts["BYMONTHDAY"]
+		switch {
+		case len(days) != 0 || parts["BYSETPOS"] != "":
+			return "", false
+		case month == "" && monthDay == "":
+			dueString = every("year", nil)
+		case month != "" && monthDay != "" && !strings.Contains(month+monthDay, ","):
+			m, err := strconv.Atoi(month)
+			if err != nil || m < 1 || m > 12 || monthDays[0] == "last day" {
+				return "", false
+			}
+			on := strings.ToLower(time.Month(m).String()[:3]) + " " + monthDay
+			if interval == 1 {
+				dueString = "every " + on
+			} else {
+				dueString = every("year", nil) + " on " + on
+			}
+		default:
+			return "", false
+		}
+	default:
+		return "", false
+	}
+
+	t := start.Time
+	if !start.AllDay && !start.Floating {
+		t = t.In(loc)
+	}
+
+	if !start.AllDay {
+		dueString += " at " + t.Format("15:04")
+	}
+	dueString += " starting " + t.Format(todoist.DateLayout)
+
+	if value := parts["UNTIL"]; value != "" {
+		until, err := parseDate(nil, value, nil)
+		if err != nil {
+			return "", false
+		}
+		if !until.AllDay && !until.Floating {
+			until.Time = until.Time.In(loc)
+		}
+		dueString += " ending " + until.Time.Format(todoist.DateLayout)
+	}
+
+	if _, err := todoist.ParseRecurrence(dueString); err != nil {
+		return "", false
+	}
+
+	return dueString, true
+}
+
+// ordinal formats 1 as "1st" and -1 as "last".
+func ordinal(n int) string {
+	if n == -1 {
+		return "last"
+	}
+
+	suffix := "th"
+	switch {
+	case n%100 >= 11 && n%100 <= 13:
+	case n%10 == 1:
+		suffix = "st"
+	case n%10 == 2:
+		suffix = "nd"
+	case n%10 == 3:
+		suffix = "rd"
+	}
+
+	return strconv.Itoa(n) + suffix
+}
+
+func splitNonEmpty(value string) []string {
+	if value == "" {
+		return nil
+	}
+
+	return strings.Split(value, ",")
+}
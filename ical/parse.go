@@ -0,0 +1,397 @@
+package ical
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidCalendar = errors.New("invalid calendar")
+
+// Item is a VTODO or VEVENT read from a calendar.
+type Item struct {
+	Component    Component
+	Uid          string
+	RecurrenceId string
+	Summary      string
+	Description  string
+	Categories   []string
+	Priority     int
+	Status       string
+	Url          string
+	Start        *Date
+	Due          *Date
+	RRule        string
+	// Err is set when a date of the component can't be read.
+	Err error
+}
+
+// Date is a DATE or DATE-TIME value. Times with a TZID are in that location and UTC times in UTC.
+// A TZID that is not a tz database name, like the Windows names Outlook writes, is resolved with
+// the VTIMEZONE of the calendar into a fixed offset. Without one, the time is floating.
+// Floating times have their wall clock time in UTC.
+type Date struct {
+	Time     time.Time
+	AllDay   bool
+	Floating bool
+}
+
+// timezone is a VTIMEZONE, used for TZIDs the tz database doesn't know.
+type timezone struct {
+	observances []observance
+}
+
+// observance is a STANDARD or DAYLIGHT part of a VTIMEZONE. Rules are yearly, on the nth weekday of a month.
+type observance struct {
+	start   time.Time
+	offset  int
+	month   time.Month
+	nth     int
+	weekday time.Weekday
+	until   time.Time
+}
+
+type pendingDate struct {
+	item   int
+	due    bool
+	params map[string]string
+	value  string
+}
+
+var icalWeekdays = map[string]time.Weekday{
+	"SU": time.Sunday, "MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday,
+	"TH": time.Thursday, "FR": time.Friday, "SA": time.Saturday,
+}
+
+// Parse reads the VTODO and VEVENT components of a calendar. Other components, including
+// alarms nested in todos and events, are ignored. Dates that can't be read set the Err of their item.
+func Parse(r io.Reader) (items []Item, err error) {
+	var lines []string
+	if lines, err = unfold(r); err != nil {
+		return
+	}
+
+	var stack []string
+	var item *Item
+	var dates []pendingDate
+
+	timezones := make(map[string]*timezone)
+	var zone *timezone
+	var zoneId string
+	var part *observance
+
+	for _, line := range lines {
+		name, params, value, ok := parseLine(line)
+		if !ok {
+			return nil, ErrInvalidCalendar
+		}
+
+		switch name {
+		case "BEGIN":
+			stack = append(stack, strings.ToUpper(value))
+			switch {
+			case len(stack) == 2 && (Component(stack[1]) == Todo || Component(stack[1]) == Event):
+				item = &Item{Component: Component(stack[1])}
+			case len(stack) == 2 && stack[1] == "VTIMEZONE":
+				zone, zoneId = &timezone{}, ""
+			case len(stack) == 3 && zone != nil && (stack[2] == "STANDARD" || stack[2] == "DAYLIGHT"):
+				part = &observance{}
+			}
+			continue
+		case "END":
+			if len(stack) == 0 || stack[len(stack)-1] != strings.ToUpper(value) {
+				return nil, ErrInvalidCalendar
+			}
+			switch {
+			case len(stack) == 2 && item != nil:
+				items = append(items, *item)
+				item = nil
+			case len(stack) == 2 && zone != nil:
+				if zoneId != "" {
+					timezones[zoneId] = zone
+				}
+				zone = nil
+			case len(stack) == 3 && part != nil:
+				zone.observances = append(zone.observances, *part)
+				part = nil
+			}
+			stack = stack[:len(stack)-1]
+			continue
+		}
+
+		if zone != nil {
+			if len(stack) == 2 && name == "TZID" {
+				zoneId = value
+			} else if part != nil {
+				part.parse(name, value)
+			}
+			continue
+		}
+
+		if item == nil || len(stack) != 2 {
+			continue
+		}
+
+		switch name {
+		case "UID":
+			item.Uid = value
+		case "RECURRENCE-ID":
+			item.RecurrenceId = value
+		case "SUMMARY":
+			item.Summary = unescapeText(value)
+		case "DESCRIPTION":
+			item.Description = unescapeText(value)
+		case "CATEGORIES":
+			for _, category := range splitList(value) {
+				if category = strings.TrimSpace(unescapeText(category)); category != "" {
+					item.Categories = append(item.Categories, category)
+				}
+			}
+		case "PRIORITY":
+			item.Priority, _ = strconv.Atoi(value)
+		case "STATUS":
+			item.Status = strings.ToUpper(value)
+		case "URL":
+			item.Url = value
+		case "DTSTART", "DUE":
+			// Resolved at the end, the VTIMEZONE of a TZID may come later.
+			dates = append(dates, pendingDate{item: len(items), due: name == "DUE", params: params, value: value})
+		case "RRULE":
+			item.RRule = value
+		}
+	}
+
+	if len(stack) != 0 {
+		return nil, ErrInvalidCalendar
+	}
+
+	for _, pending := range dates {
+		item := &items[pending.item]
+
+		date, err := parseDate(pending.params, pending.value, timezones)
+		if err != nil {
+			if item.Err == nil {
+				item.Err = fmt.Errorf("invalid date %q: %w", pending.value, err)
+			}
+			continue
+		}
+
+		if pending.due {
+			item.Due = date
+		} else {
+			item.Start = date
+		}
+	}
+
+	return
+}
+
+// unfold joins continuation lines, which start with a space or a tab.
+func unfold(r io.Reader) (lines []string, err error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		switch {
+		case line == "":
+		case (line[0] == ' ' || line[0] == '\t') && len(lines) != 0:
+			lines[len(lines)-1] += line[1:]
+		default:
+			lines = append(lines, line)
+		}
+	}
+
+	return lines, scanner.Err()
+}
+
+// parseLine splits a content line into its name, parameters and value. Parameter values may be quoted.
+func parseLine(line string) (name string, params map[string]string, value string, ok bool) {
+	params = make(map[string]string)
+
+	quoted := false
+	start := 0
+	key := ""
+	for i := 0; i < len(line); i++ {
+		switch c := line[i]; {
+		case c == '"':
+			quoted = !quoted
+		case quoted:
+		case c == ';' || c == ':':
+			part := line[start:i]
+			if name == "" {
+				name = strings.ToUpper(part)
+			} else if key != "" {
+				params[key] = strings.Trim(part, `"`)
+			}
+			key, start = "", i+1
+
+			if c == ':' {
+				return name, params, line[i+1:], name != ""
+			}
+		case c == '=' && key == "" && name != "":
+			key, start = strings.ToUpper(line[start:i]), i+1
+		}
+	}
+
+	return "", nil, "", false
+}
+
+func parseDate(params map[string]string, value string, timezones map[string]*timezone) (date *Date, err error) {
+	if params["VALUE"] == "DATE" || len(value) == len(dateLayout) {
+		var t time.Time
+		if t, err = time.Parse(dateLayout, value); err != nil {
+			return
+		}
+		return &Date{Time: t, AllDay: true}, nil
+	}
+
+	loc := time.UTC
+	floating := true
+	switch {
+	case strings.HasSuffix(value, "Z"):
+		value = strings.TrimSuffix(value, "Z")
+		floating = false
+	case params["TZID"] != "":
+		tzid := strings.TrimPrefix(params["TZID"], "/")
+		if loc, err = time.LoadLocation(tzid); err == nil {
+			floating = false
+			break
+		}
+
+		loc, err = time.UTC, nil
+		if zone := timezones[params["TZID"]]; zone != nil && len(zone.observances) != 0 {
+			var wall time.Time
+			if wall, err = time.Parse(datetimeLayout, value); err != nil {
+				return
+			}
+			return &Date{Time: wall.Add(-time.Duration(zone.offset(wall)) * time.Second).In(time.FixedZone(tzid, zone.offset(wall)))}, nil
+		}
+	}
+
+	var t time.Time
+	if t, err = time.ParseInLocation(datetimeLayout, value, loc); err != nil {
+		return
+	}
+
+	return &Date{Time: t, Floating: floating}, nil
+}
+
+func (o *observance) parse(name string, value string) {
+	switch name {
+	case "DTSTART":
+		o.start, _ = time.Parse(datetimeLayout, value)
+	case "TZOFFSETTO":
+		o.offset, _ = parseOffset(value)
+	case "RRULE":
+		for _, rule := range strings.Split(strings.ToUpper(value), ";") {
+			i := strings.IndexByte(rule, '=')
+			if i == -1 {
+				continue
+			}
+
+			switch key, value := rule[:i], rule[i+1:]; key {
+			case "BYMONTH":
+				month, _ := strconv.Atoi(value)
+				o.month = time.Month(month)
+			case "BYDAY":
+				if len(value) > 2 {
+					o.nth, _ = strconv.Atoi(strings.TrimPrefix(value[:len(value)-2], "+"))
+					o.weekday = icalWeekdays[value[len(value)-2:]]
+				}
+			case "UNTIL":
+				if len(value) >= len(dateLayout) {
+					o.until, _ = time.Parse(dateLayout, value[:len(dateLayout)])
+				}
+			}
+		}
+	}
+}
+
+// onset returns the last start of the observance at or before the wall clock time.
+func (o *observance) onset(wall time.Time) (onset time.Time, ok bool) {
+	if o.month == 0 || o.nth == 0 {
+		return o.start, !o.start.After(wall)
+	}
+
+	for year := wall.Year(); year >= wall.Year()-1 && year >= o.start.Year(); year-- {
+		first := time.Date(year, o.month, 1, o.start.Hour(), o.start.Minute(), o.start.Second(), 0, time.UTC)
+		if o.nth < 0 {
+			first = first.AddDate(0, 1, -7)
+		}
+
+		day := first.AddDate(0, 0, (int(o.weekday)-int(first.Weekday())+7)%7)
+		if o.nth > 0 {
+			day = day.AddDate(0, 0, 7*(o.nth-1))
+		} else {
+			day = day.AddDate(0, 0, 7*(o.nth+1))
+		}
+
+		if !o.until.IsZero() && day.After(o.until.AddDate(0, 0, 1)) || day.Before(o.start) || day.After(wall) {
+			continue
+		}
+
+		return day, true
+	}
+
+	return time.Time{}, false
+}
+
+// offset returns the UTC offset in seconds at the wall clock time, the one of the observance that started last.
+func (z *timezone) offset(wall time.Time) int {
+	offset := z.observances[0].offset
+
+	var latest time.Time
+	for i := range z.observances {
+		if onset, ok := z.observances[i].onset(wall); ok && !onset.Before(latest) {
+			latest, offset = onset, z.observances[i].offset
+		}
+	}
+
+	return offset
+}
+
+// parseOffset reads a UTC offset like "-0800" or "+053000" in seconds.
+func parseOffset(value string) (seconds int, err error) {
+	if len(value) != 5 && len(value) != 7 || value[0] != '+' && value[0] != '-' {
+		return 0, ErrInvalidCalendar
+	}
+
+	for i, unit := range []int{3600, 60, 1} {
+		if 1+2*i+2 > len(value) {
+			break
+		}
+
+		var n int
+		if n, err = strconv.Atoi(value[1+2*i : 1+2*i+2]); err != nil {
+			return
+		}
+		seconds += n * unit
+	}
+
+	if value[0] == '-' {
+		seconds = -seconds
+	}
+
+	return
+}
+
+// splitList splits a comma separated value, leaving escaped commas alone.
+func splitList(value string) (items []string) {
+	start := 0
+	for i := 0; i < len(value); i++ {
+		switch value[i] {
+		case '\\':
+			i++
+		case ',':
+			items = append(items, value[start:i])
+			start = i + 1
+		}
+	}
+
+	return append(items, value[start:])
+}
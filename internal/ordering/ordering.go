@@ -0,0 +1,20 @@
+// Package ordering holds the sort order shared by the packages of the module.
+package ordering
+
+// LessId orders ids by length and then lexically, which is the numeric order for ids without leading zeros.
+func LessId(a string, b string) bool {
+	if len(a) != len(b) {
+		return len(a) < len(b)
+	}
+
+	return a < b
+}
+
+// LessOrder orders by order and then by id.
+func LessOrder(orderA int, idA string, orderB int, idB string) bool {
+	if orderA != orderB {
+		return orderA < orderB
+	}
+
+	return LessId(idA, idB)
+}
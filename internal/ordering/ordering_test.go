@@ -0,0 +1,27 @@
+package ordering_test
+
+import (
+	"testing"
+
+	"github.com/temoon/todoist-api/internal/ordering"
+)
+
+func TestLessId(t *testing.T) {
+	tests := []struct {
+		a    string
+		b    string
+		want bool
+	}{
+		{a: "9", b: "10", want: true},
+		{a: "10", b: "9", want: false},
+		{a: "10", b: "11", want: true},
+		{a: "11", b: "11", want: false},
+		{a: "abc", b: "abd", want: true},
+	}
+
+	for _, tt := range tests {
+		if got := ordering.LessId(tt.a, tt.b); got != tt.want {
+			t.Errorf("LessId(%q, %q) = %t, want %t", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
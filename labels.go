@@ -1,7 +1,6 @@
 package todoist
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"net/http"
@@ -22,7 +21,7 @@ type Label struct {
 
 func (t *Todoist) GetLabels(ctx context.Context) (labels []Label, err error) {
 	labels = make([]Label, 0)
-	err = t.request(ctx, http.MethodGet, LabelsEndpoint, nil, nil, &labels)
+	err = t.request(ctx, "GetLabels", http.MethodGet, LabelsEndpoint, nil, nil, &labels)
 
 	return
 }
@@ -75,7 +74,7 @@ func (t *Todoist) AddLabel(ctx context.Context, params *AddLabelParams) (label *
 	}
 
 	label = new(Label)
-	err = t.request(ctx, http.MethodPost, LabelsEndpoint, nil, bytes.NewBuffer(payload), label)
+	err = t.request(ctx, "AddLabel", http.MethodPost, LabelsEndpoint, nil, payload, label)
 
 	return
 }
@@ -87,7 +86,7 @@ func (t *Todoist) AddLabel(ctx context.Context, params *AddLabelParams) (label *
 func (t *Todoist) GetLabel(ctx context.Context, labelId string) (label *Label, err error) {
 	label = new(Label)
 	encodedLabelId := url.PathEscape(labelId)
-	err = t.request(ctx, http.MethodGet, LabelsEndpoint+"/"+encodedLabelId, nil, nil, label)
+	err = t.request(ctx, "GetLabel", http.MethodGet, LabelsEndpoint+"/"+encodedLabelId, nil, nil, label)
 
 	return
 }
@@ -140,7 +139,7 @@ func (t *Todoist) UpdateLabel(ctx context.Context, labelId string, params *Updat
 	}
 
 	encodedLabelId := url.PathEscape(labelId)
-	return t.request(ctx, http.MethodPost, LabelsEndpoint+"/"+encodedLabelId, nil, bytes.NewBuffer(payload), nil)
+	return t.request(ctx, "UpdateLabel", http.MethodPost, LabelsEndpoint+"/"+encodedLabelId, nil, payload, nil)
 }
 
 // endregion
@@ -149,7 +148,7 @@ func (t *Todoist) UpdateLabel(ctx context.Context, labelId string, params *Updat
 
 func (t *Todoist) DeleteLabel(ctx context.Context, labelId string) (err error) {
 	encodedLabelId := url.PathEscape(labelId)
-	return t.request(ctx, http.MethodDelete, LabelsEndpoint+"/"+encodedLabelId, nil, nil, nil)
+	return t.request(ctx, "DeleteLabel", http.MethodDelete, LabelsEndpoint+"/"+encodedLabelId, nil, nil, nil)
 }
 
 // endregion
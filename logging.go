@@ -0,0 +1,130 @@
+package todoist
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+const redacted = "[REDACTED]"
+
+var redactedFields = map[string]bool{
+	"content":     true,
+	"description": true,
+}
+
+// Logger receives one record per call, with attributes as key-value pairs.
+// *slog.Logger implements it as is, other loggers (e.g. zap.SugaredLogger) need a small adapter.
+type Logger interface {
+	InfoContext(ctx context.Context, msg string, args ...interface{})
+	ErrorContext(ctx context.Context, msg string, args ...interface{})
+}
+
+func logging(logger Logger, redactContent bool) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, call *Call) (result *Result, err error) {
+			start := time.Now()
+			result, err = next(ctx, call)
+
+			args := []interface{}{
+				"operation", call.Operation,
+				"method", call.Method,
+				"endpoint", call.Endpoint,
+				"latency", time.Since(start),
+				"request_bytes", len(call.Payload),
+			}
+
+			if len(call.Params) != 0 {
+				args = append(args, "params", redactParams(call.Params, redactContent))
+			}
+
+			if call.Payload != nil {
+				args = append(args, "payload", redactPayload(call.Payload, redactContent))
+			}
+
+			if len(call.Header) != 0 {
+				args = append(args, "header", redactHeader(call.Header))
+			}
+
+			if result != nil {
+				args = append(args,
+					"status", result.StatusCode,
+					"retries", result.Attempts-1,
+					"response_bytes", len(result.Body),
+				)
+
+				if requestId := result.Header.Get("X-Request-Id"); requestId != "" {
+					args = append(args, "request_id", requestId)
+				}
+			}
+
+			if err != nil {
+				logger.ErrorContext(ctx, "todoist request failed", append(args, "error", err.Error())...)
+			} else {
+				logger.InfoContext(ctx, "todoist request", args...)
+			}
+
+			return
+		}
+	}
+}
+
+func redactHeader(header http.Header) http.Header {
+	header = header.Clone()
+	if header.Get("Authorization") != "" {
+		header.Set("Authorization", redacted)
+	}
+
+	return header
+}
+
+func redactParams(params map[string]string, redactContent bool) map[string]string {
+	if !redactContent {
+		return params
+	}
+
+	redactedParams := make(map[string]string, len(params))
+	for key, value := range params {
+		if redactedFields[key] || key == "filter" {
+			value = redacted
+		}
+		redactedParams[key] = value
+	}
+
+	return redactedParams
+}
+
+func redactPayload(payload []byte, redactContent bool) string {
+	if !redactContent {
+		return string(payload)
+	}
+
+	var data interface{}
+	if err := json.Unmarshal(payload, &data); err != nil {
+		return redacted
+	}
+
+	redactedPayload, _ := json.Marshal(redactValue(data))
+
+	return string(redactedPayload)
+}
+
+func redactValue(value interface{}) interface{} {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		for key, item := range value {
+			if redactedFields[key] {
+				value[key] = redacted
+			} else {
+				value[key] = redactValue(item)
+			}
+		}
+	case []interface{}:
+		for i, item := range value {
+			value[i] = redactValue(item)
+		}
+	}
+
+	return value
+}
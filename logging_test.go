@@ -0,0 +1,150 @@
+package todoist_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	todoist "github.com/temoon/todoist-api"
+)
+
+type record struct {
+	level string
+	msg   string
+	attrs map[string]interface{}
+}
+
+// recorder is a Logger that keeps the records it receives.
+type recorder struct {
+	mu      sync.Mutex
+	records []record
+}
+
+func (r *recorder) InfoContext(_ context.Context, msg string, args ...interface{}) {
+	r.add("info", msg, args)
+}
+
+func (r *recorder) ErrorContext(_ context.Context, msg string, args ...interface{}) {
+	r.add("error", msg, args)
+}
+
+func (r *recorder) add(level string, msg string, args []interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	attrs := make(map[string]interface{})
+	for i := 0; i+1 < len(args); i += 2 {
+		attrs[args[i].(string)] = args[i+1]
+	}
+
+	r.records = append(r.records, record{level: level, msg: msg, attrs: attrs})
+}
+
+func TestLogging(t *testing.T) {
+	s := newStub(t, body("application/json", `{"id": "7"}`))
+
+	logger := &recorder{}
+	opts := &todoist.Opts{
+		Token:  "secret-token",
+		Client: s.client(),
+		Retry:  &todoist.RetryPolicy{MaxAttempts: 2, BaseBackoff: time.Millisecond},
+		Logger: logger,
+		Middleware: []todoist.Middleware{
+			todoist.BeforeRequest(func(_ context.Context, call *todoist.Call) error {
+				call.Header.Set("Authorization", "Bearer secret-token")
+				call.Header.Set("X-Trace", "trace-1")
+				return nil
+			}),
+		},
+	}
+	client := todoist.New(opts)
+	ctx := context.Background()
+
+	s.fail(status(http.StatusServiceUnavailable))
+	if _, err := client.GetTask(ctx, "7"); err != nil {
+		t.Fatalf("GetTask() error = %v", err)
+	}
+
+	s.fail(status(http.StatusNotFound))
+	if _, err := client.GetTask(ctx, "8"); !errors.Is(err, todoist.ErrNotFound) {
+		t.Fatalf("GetTask() error = %v, want ErrNotFound", err)
+	}
+
+	if len(logger.records) != 2 {
+		t.Fatalf("records = %d, want one per call", len(logger.records))
+	}
+
+	ok, failed := logger.records[0], logger.records[1]
+	if ok.level != "info" || ok.attrs["method"] != http.MethodGet || ok.attrs["endpoint"] != todoist.TasksEndpoint+"/7" ||
+		ok.attrs["status"] != http.StatusOK || ok.attrs["retries"] != 1 {
+		t.Errorf("record of a retried call = %s %+v", ok.level, ok.attrs)
+	}
+	if failed.level != "error" || failed.attrs["status"] != http.StatusNotFound || failed.attrs["error"] == nil {
+		t.Errorf("record of a failed call = %s %+v", failed.level, failed.attrs)
+	}
+
+	for _, r := range logger.records {
+		if text := fmt.Sprint(r.attrs); strings.Contains(text, "secret-token") {
+			t.Errorf("record leaks the token: %s", text)
+		}
+
+		header, _ := r.attrs["header"].(http.Header)
+		if header.Get("Authorization") != "[REDACTED]" || header.Get("X-Trace") != "trace-1" {
+			t.Errorf("header = %v, want the authorization redacted", header)
+		}
+	}
+}
+
+func TestLoggingRedactContent(t *testing.T) {
+	tests := []struct {
+		name   string
+		redact bool
+		want   []string
+		hidden []string
+	}{
+		{
+			name:   "redacted",
+			redact: true,
+			want:   []string{`"content":"[REDACTED]"`, `"description":"[REDACTED]"`, `"priority":4`, "filter:[REDACTED]", "project_id:3"},
+			hidden: []string{"Secret plan", "Between us", "#Secret"},
+		},
+		{
+			name: "plain",
+			want: []string{`"content":"Secret plan"`, `"description":"Between us"`, "filter:#Secret"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newStub(t, body("application/json", `[]`))
+
+			logger := &recorder{}
+			client := todoist.New(&todoist.Opts{Token: "token", Client: s.client(), Logger: logger, RedactContent: tt.redact})
+			ctx := context.Background()
+
+			_, _ = client.AddTask(ctx, todoist.MakeAddTaskParams().WithContent("Secret plan").WithDescription("Between us").WithPriority(4))
+			_, _ = client.GetTasks(ctx, todoist.MakeGetTasksParams().WithProjectId("3").WithFilter("#Secret"))
+
+			if len(logger.records) != 2 {
+				t.Fatalf("records = %d, want 2", len(logger.records))
+			}
+
+			text := fmt.Sprint(logger.records[0].attrs) + fmt.Sprint(logger.records[1].attrs)
+			for _, want := range tt.want {
+				if !strings.Contains(text, want) {
+					t.Errorf("records do not contain %s: %s", want, text)
+				}
+			}
+			for _, hidden := range tt.hidden {
+				if strings.Contains(text, hidden) {
+					t.Errorf("records contain %s: %s", hidden, text)
+				}
+			}
+		})
+	}
+}
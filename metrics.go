@@ -0,0 +1,223 @@
+package todoist
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	ErrorClassCanceled     = "canceled"
+	ErrorClassNetwork      = "network"
+	ErrorClassUnauthorized = "unauthorized"
+	ErrorClassForbidden    = "forbidden"
+	ErrorClassNotFound     = "not_found"
+	ErrorClassRateLimited  = "rate_limited"
+	ErrorClassClient       = "client"
+	ErrorClassServer       = "server"
+	ErrorClassDecode       = "decode"
+	ErrorClassOther        = "other"
+)
+
+var DefaultBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+// Metrics observes every call once, after retries, keyed by the method name (GetTasks, AddProject, ...).
+// Status is 0 when no response was received.
+type Metrics interface {
+	ObserveCall(operation string, status int, duration time.Duration, err error)
+}
+
+//goland:noinspection GoUnusedExportedFunction
+func ErrorClass(err error) string {
+	var apiErr *ApiError
+	var decodeErr *DecodeError
+	var netErr net.Error
+
+	switch {
+	case err == nil:
+		return ""
+	case errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) || errors.Is(err, ErrRateLimitWait):
+		return ErrorClassCanceled
+	case errors.As(err, &apiErr):
+		switch {
+		case apiErr.Is(ErrUnauthorized):
+			return ErrorClassUnauthorized
+		case apiErr.Is(ErrForbidden):
+			return ErrorClassForbidden
+		case apiErr.Is(ErrNotFound):
+			return ErrorClassNotFound
+		case apiErr.Is(ErrRateLimited):
+			return ErrorClassRateLimited
+		case apiErr.Is(ErrServer):
+			return ErrorClassServer
+		default:
+			return ErrorClassClient
+		}
+	case errors.As(err, &decodeErr):
+		return ErrorClassDecode
+	case errors.As(err, &netErr):
+		return ErrorClassNetwork
+	default:
+		return ErrorClassOther
+	}
+}
+
+type operationCode struct {
+	operation string
+	code      int
+}
+
+type operationClass struct {
+	operation string
+	class     string
+}
+
+// histogram keeps the bounds it was created with, so that changing Buckets later doesn't mix them up.
+type histogram struct {
+	bounds []float64
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+// MemoryMetrics keeps counters and latency histograms in memory and renders them
+// in the Prometheus text exposition format.
+type MemoryMetrics struct {
+	Namespace string
+	// Buckets are the upper bounds of the latency histograms, in seconds. They are copied
+	// by the first call of each operation.
+	Buckets []float64
+
+	mu        sync.Mutex
+	requests  map[operationCode]uint64
+	errors    map[operationClass]uint64
+	durations map[string]*histogram
+}
+
+//goland:noinspection GoUnusedExportedFunction
+func NewMemoryMetrics() *MemoryMetrics {
+	return &MemoryMetrics{
+		Namespace: "todoist",
+		Buckets:   DefaultBuckets,
+		requests:  make(map[operationCode]uint64),
+		errors:    make(map[operationClass]uint64),
+		durations: make(map[string]*histogram),
+	}
+}
+
+func (m *MemoryMetrics) ObserveCall(operation string, status int, duration time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.requests[operationCode{operation, status}]++
+
+	if err != nil {
+		m.errors[operationClass{operation, ErrorClass(err)}]++
+	}
+
+	h, ok := m.durations[operation]
+	if !ok {
+		h = &histogram{
+			bounds: append([]float64(nil), m.Buckets...),
+			counts: make([]uint64, len(m.Buckets)),
+		}
+		m.durations[operation] = h
+	}
+
+	seconds := duration.Seconds()
+	for i, bound := range h.bounds {
+		if seconds <= bound {
+			h.counts[i]++
+		}
+	}
+	h.count++
+	h.sum += seconds
+}
+
+func (m *MemoryMetrics) WritePrometheus(w io.Writer) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	b := bufio.NewWriter(w)
+
+	name := m.name("requests_total")
+	fmt.Fprintf(b, "# HELP %s Total number of API calls by operation and HTTP status code.\n", name)
+	fmt.Fprintf(b, "# TYPE %s counter\n", name)
+	requests := make([]operationCode, 0, len(m.requests))
+	for key := range m.requests {
+		requests = append(requests, key)
+	}
+	sort.Slice(requests, func(i, j int) bool {
+		if requests[i].operation != requests[j].operation {
+			return requests[i].operation < requests[j].operation
+		}
+		return requests[i].code < requests[j].code
+	})
+	for _, key := range requests {
+		fmt.Fprintf(b, "%s{operation=%s,code=\"%d\"} %d\n", name, quote(key.operation), key.code, m.requests[key])
+	}
+
+	name = m.name("errors_total")
+	fmt.Fprintf(b, "# HELP %s Total number of failed API calls by operation and error class.\n", name)
+	fmt.Fprintf(b, "# TYPE %s counter\n", name)
+	errs := make([]operationClass, 0, len(m.errors))
+	for key := range m.errors {
+		errs = append(errs, key)
+	}
+	sort.Slice(errs, func(i, j int) bool {
+		if errs[i].operation != errs[j].operation {
+			return errs[i].operation < errs[j].operation
+		}
+		return errs[i].class < errs[j].class
+	})
+	for _, key := range errs {
+		fmt.Fprintf(b, "%s{operation=%s,class=%s} %d\n", name, quote(key.operation), quote(key.class), m.errors[key])
+	}
+
+	name = m.name("request_duration_seconds")
+	fmt.Fprintf(b, "# HELP %s API call latency by operation, including retries.\n", name)
+	fmt.Fprintf(b, "# TYPE %s histogram\n", name)
+	operations := make([]string, 0, len(m.durations))
+	for operation := range m.durations {
+		operations = append(operations, operation)
+	}
+	sort.Strings(operations)
+	for _, operation := range operations {
+		h := m.durations[operation]
+		for i, bound := range h.bounds {
+			le := strconv.FormatFloat(bound, 'g', -1, 64)
+			fmt.Fprintf(b, "%s_bucket{operation=%s,le=\"%s\"} %d\n", name, quote(operation), le, h.counts[i])
+		}
+		fmt.Fprintf(b, "%s_bucket{operation=%s,le=\"+Inf\"} %d\n", name, quote(operation), h.count)
+		fmt.Fprintf(b, "%s_sum{operation=%s} %s\n", name, quote(operation), strconv.FormatFloat(h.sum, 'g', -1, 64))
+		fmt.Fprintf(b, "%s_count{operation=%s} %d\n", name, quote(operation), h.count)
+	}
+
+	return b.Flush()
+}
+
+func (m *MemoryMetrics) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_ = m.WritePrometheus(w)
+}
+
+func (m *MemoryMetrics) name(name string) string {
+	if m.Namespace == "" {
+		return name
+	}
+
+	return m.Namespace + "_" + name
+}
+
+func quote(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value) + `"`
+}
@@ -0,0 +1,164 @@
+package todoist_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	todoist "github.com/temoon/todoist-api"
+)
+
+type observation struct {
+	operation string
+	status    int
+	err       error
+}
+
+type observer []observation
+
+func (o *observer) ObserveCall(operation string, status int, _ time.Duration, err error) {
+	*o = append(*o, observation{operation: operation, status: status, err: err})
+}
+
+func TestMetricsObserveCall(t *testing.T) {
+	s := newStub(t, body("application/json", `{"id": "7"}`))
+
+	metrics := &observer{}
+	opts := &todoist.Opts{
+		Token:   "token",
+		Client:  s.client(),
+		Retry:   &todoist.RetryPolicy{MaxAttempts: 2, BaseBackoff: time.Millisecond},
+		Metrics: metrics,
+	}
+	client := todoist.New(opts)
+	ctx := context.Background()
+
+	s.fail(status(http.StatusServiceUnavailable))
+	_, _ = client.GetProject(ctx, "7")
+	s.fail(status(http.StatusNotFound))
+	_, _ = client.GetTask(ctx, "8")
+	s.fail(hangUp, hangUp)
+	_ = client.CloseTask(ctx, "9")
+
+	if len(*metrics) != 3 {
+		t.Fatalf("observations = %+v, want one per call", *metrics)
+	}
+
+	got := *metrics
+	if got[0].operation != "GetProject" || got[0].status != http.StatusOK || got[0].err != nil {
+		t.Errorf("retried call = %+v", got[0])
+	}
+	if got[1].operation != "GetTask" || got[1].status != http.StatusNotFound || !errors.Is(got[1].err, todoist.ErrNotFound) {
+		t.Errorf("failed call = %+v", got[1])
+	}
+	if got[2].operation != "CloseTask" || got[2].status != 0 || got[2].err == nil {
+		t.Errorf("call without a response = %+v", got[2])
+	}
+}
+
+func TestMemoryMetricsWritePrometheus(t *testing.T) {
+	m := todoist.NewMemoryMetrics()
+	m.Buckets = []float64{0.1, 1}
+
+	m.ObserveCall("GetTasks", http.StatusOK, 50*time.Millisecond, nil)
+	m.ObserveCall("GetTasks", http.StatusOK, 500*time.Millisecond, nil)
+	m.ObserveCall("GetTasks", http.StatusServiceUnavailable, 2*time.Second, &todoist.ApiError{StatusCode: http.StatusServiceUnavailable})
+	m.ObserveCall(`Add"Task`, 0, 250*time.Millisecond, context.Canceled)
+
+	want := `# HELP todoist_requests_total Total number of API calls by operation and HTTP status code.
+# TYPE todoist_requests_total counter
+todoist_requests_total{operation="Add\"Task",code="0"} 1
+todoist_requests_total{operation="GetTasks",code="200"} 2
+todoist_requests_total{operation="GetTasks",code="503"} 1
+# HELP todoist_errors_total Total number of failed API calls by operation and error class.
+# TYPE todoist_errors_total counter
+todoist_errors_total{operation="Add\"Task",class="canceled"} 1
+todoist_errors_total{operation="GetTasks",class="server"} 1
+# HELP todoist_request_duration_seconds API call latency by operation, including retries.
+# TYPE todoist_request_duration_seconds histogram
+todoist_request_duration_seconds_bucket{operation="Add\"Task",le="0.1"} 0
+todoist_request_duration_seconds_bucket{operation="Add\"Task",le="1"} 1
+todoist_request_duration_seconds_bucket{operation="Add\"Task",le="+Inf"} 1
+todoist_request_duration_seconds_sum{operation="Add\"Task"} 0.25
+todoist_request_duration_seconds_count{operation="Add\"Task"} 1
+todoist_request_duration_seconds_bucket{operation="GetTasks",le="0.1"} 1
+todoist_request_duration_seconds_bucket{operation="GetTasks",le="1"} 2
+todoist_request_duration_seconds_bucket{operation="GetTasks",le="+Inf"} 3
+todoist_request_duration_seconds_sum{operation="GetTasks"} 2.55
+todoist_request_duration_seconds_count{operation="GetTasks"} 3
+`
+
+	var b strings.Builder
+	if err := m.WritePrometheus(&b); err != nil {
+		t.Fatalf("WritePrometheus() error = %v", err)
+	}
+	if b.String() != want {
+		t.Errorf("WritePrometheus() =\n%s\nwant\n%s", b.String(), want)
+	}
+}
+
+func TestMemoryMetricsBucketsChanged(t *testing.T) {
+	m := todoist.NewMemoryMetrics()
+	m.Buckets = []float64{0.1, 1}
+	m.ObserveCall("GetTasks", http.StatusOK, 50*time.Millisecond, nil)
+
+	m.Buckets = []float64{0.5}
+	m.ObserveCall("GetTasks", http.StatusOK, 2*time.Second, nil)
+	m.ObserveCall("GetProjects", http.StatusOK, 250*time.Millisecond, nil)
+
+	want := `# HELP todoist_request_duration_seconds API call latency by operation, including retries.
+# TYPE todoist_request_duration_seconds histogram
+todoist_request_duration_seconds_bucket{operation="GetProjects",le="0.5"} 1
+todoist_request_duration_seconds_bucket{operation="GetProjects",le="+Inf"} 1
+todoist_request_duration_seconds_sum{operation="GetProjects"} 0.25
+todoist_request_duration_seconds_count{operation="GetProjects"} 1
+todoist_request_duration_seconds_bucket{operation="GetTasks",le="0.1"} 1
+todoist_request_duration_seconds_bucket{operation="GetTasks",le="1"} 1
+todoist_request_duration_seconds_bucket{operation="GetTasks",le="+Inf"} 2
+todoist_request_duration_seconds_sum{operation="GetTasks"} 2.05
+todoist_request_duration_seconds_count{operation="GetTasks"} 2
+`
+
+	var b strings.Builder
+	if err := m.WritePrometheus(&b); err != nil {
+		t.Fatalf("WritePrometheus() error = %v", err)
+	}
+	if got := b.String(); !strings.HasSuffix(got, want) {
+		t.Errorf("WritePrometheus() =\n%s\nwant the histograms\n%s", got, want)
+	}
+}
+
+func TestErrorClass(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{name: "nil", err: nil, want: ""},
+		{name: "canceled", err: context.Canceled, want: todoist.ErrorClassCanceled},
+		{name: "deadline", err: fmt.Errorf("get: %w", context.DeadlineExceeded), want: todoist.ErrorClassCanceled},
+		{name: "rate limit wait", err: todoist.ErrRateLimitWait, want: todoist.ErrorClassCanceled},
+		{name: "unauthorized", err: &todoist.ApiError{StatusCode: http.StatusUnauthorized}, want: todoist.ErrorClassUnauthorized},
+		{name: "forbidden", err: &todoist.ApiError{StatusCode: http.StatusForbidden}, want: todoist.ErrorClassForbidden},
+		{name: "not found", err: &todoist.ApiError{StatusCode: http.StatusNotFound}, want: todoist.ErrorClassNotFound},
+		{name: "rate limited", err: &todoist.ApiError{StatusCode: http.StatusTooManyRequests}, want: todoist.ErrorClassRateLimited},
+		{name: "client", err: &todoist.ApiError{StatusCode: http.StatusBadRequest}, want: todoist.ErrorClassClient},
+		{name: "server", err: fmt.Errorf("get: %w", &todoist.ApiError{StatusCode: http.StatusBadGateway}), want: todoist.ErrorClassServer},
+		{name: "decode", err: &todoist.DecodeError{Err: todoist.ErrInvalidContentType}, want: todoist.ErrorClassDecode},
+		{name: "network", err: &net.OpError{Op: "dial", Err: errors.New("connection refused")}, want: todoist.ErrorClassNetwork},
+		{name: "other", err: errors.New("invalid params"), want: todoist.ErrorClassOther},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := todoist.ErrorClass(tt.err); got != tt.want {
+				t.Errorf("ErrorClass(%v) = %q, want %q", tt.err, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package todoist
+
+import (
+	"context"
+	"net/http"
+)
+
+// Call describes a single API call. Middleware may change it before passing it on,
+// e.g. to add headers, and the changes apply to every retry attempt.
+type Call struct {
+	Operation  string
+	Method     string
+	BaseUrl    string
+	Endpoint   string
+	Params     map[string]string
+	Payload    []byte
+	Header     http.Header
+	Idempotent bool
+}
+
+// Result is the raw response of the last attempt. It is returned along with an *ApiError
+// for non-2xx responses, and is nil when the request failed before a response was received.
+type Result struct {
+	StatusCode int
+	Status     string
+	Header     http.Header
+	Body       []byte
+	Attempts   int
+}
+
+type Handler func(ctx context.Context, call *Call) (*Result, error)
+
+// Middleware wraps the handler that performs calls, including rate limiting and retries.
+// Middleware listed first in Opts is the outermost.
+type Middleware func(next Handler) Handler
+
+//goland:noinspection GoUnusedExportedFunction
+func BeforeRequest(hook func(ctx context.Context, call *Call) error) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, call *Call) (*Result, error) {
+			if err := hook(ctx, call); err != nil {
+				return nil, err
+			}
+
+			return next(ctx, call)
+		}
+	}
+}
+
+//goland:noinspection GoUnusedExportedFunction
+func AfterResponse(hook func(ctx context.Context, call *Call, result *Result, err error)) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, call *Call) (result *Result, err error) {
+			result, err = next(ctx, call)
+			hook(ctx, call, result, err)
+
+			return
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package todoist_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	todoist "github.com/temoon/todoist-api"
+)
+
+func TestMiddlewareHooks(t *testing.T) {
+	var traces []string
+	s := newStub(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traces = append(traces, r.Header.Get("X-Trace"))
+		body("application/json", `{"id": "7", "content": "Buy milk"}`)(w, r)
+	}))
+
+	var calls []todoist.Call
+	var statuses []int
+	var errs []error
+	opts := &todoist.Opts{
+		Token:  "token",
+		Client: s.client(),
+		Middleware: []todoist.Middleware{
+			todoist.BeforeRequest(func(_ context.Context, call *todoist.Call) error {
+				call.Header.Set("X-Trace", "trace-1")
+				calls = append(calls, *call)
+				return nil
+			}),
+			todoist.AfterResponse(func(_ context.Context, _ *todoist.Call, result *todoist.Result, err error) {
+				statuses = append(statuses, result.StatusCode)
+				errs = append(errs, err)
+			}),
+		},
+	}
+	client := todoist.New(opts)
+	ctx := context.Background()
+
+	if _, err := client.AddTask(ctx, todoist.MakeAddTaskParams().WithContent("Buy milk")); err != nil {
+		t.Fatalf("AddTask() error = %v", err)
+	}
+
+	s.fail(status(http.StatusNotFound))
+	if _, err := client.GetTasks(ctx, todoist.MakeGetTasksParams().WithProjectId("42")); !errors.Is(err, todoist.ErrNotFound) {
+		t.Fatalf("GetTasks() error = %v, want ErrNotFound", err)
+	}
+
+	if len(calls) != 2 {
+		t.Fatalf("BeforeRequest saw %d calls, want 2", len(calls))
+	}
+	if add := calls[0]; add.Operation != "AddTask" || add.Method != http.MethodPost || add.Endpoint != todoist.TasksEndpoint ||
+		!strings.Contains(string(add.Payload), `"content":"Buy milk"`) {
+		t.Errorf("AddTask call = %s %s %s %s", add.Operation, add.Method, add.Endpoint, add.Payload)
+	}
+	if get := calls[1]; get.Operation != "GetTasks" || get.Method != http.MethodGet || get.Params["project_id"] != "42" || get.Payload != nil {
+		t.Errorf("GetTasks call = %s %s %v %s", get.Operation, get.Method, get.Params, get.Payload)
+	}
+
+	if len(traces) != 1 || traces[0] != "trace-1" {
+		t.Errorf("X-Trace = %q, want the header set by BeforeRequest", traces)
+	}
+
+	if len(statuses) != 2 || statuses[0] != http.StatusOK || statuses[1] != http.StatusNotFound {
+		t.Errorf("AfterResponse statuses = %v, want [200 404]", statuses)
+	}
+	if len(errs) != 2 || errs[0] != nil || !errors.Is(errs[1], todoist.ErrNotFound) {
+		t.Errorf("AfterResponse errors = %v, want [nil ErrNotFound]", errs)
+	}
+}
+
+func TestMiddlewareBeforeRequestError(t *testing.T) {
+	s := newStub(t, body("application/json", `[]`))
+
+	errDenied := errors.New("denied")
+	after := false
+	opts := &todoist.Opts{
+		Token:  "token",
+		Client: s.client(),
+		Middleware: []todoist.Middleware{
+			todoist.AfterResponse(func(_ context.Context, _ *todoist.Call, result *todoist.Result, err error) {
+				after = result == nil && errors.Is(err, errDenied)
+			}),
+			todoist.BeforeRequest(func(context.Context, *todoist.Call) error {
+				return errDenied
+			}),
+		},
+	}
+
+	if _, err := todoist.New(opts).GetProjects(context.Background()); !errors.Is(err, errDenied) {
+		t.Fatalf("GetProjects() error = %v, want the hook error", err)
+	}
+	if s.count() != 0 {
+		t.Errorf("requests = %d, want none", s.count())
+	}
+	if !after {
+		t.Errorf("AfterResponse did not see the hook error")
+	}
+}
+
+func TestMiddlewareErrorResult(t *testing.T) {
+	s := newStub(t, body("application/json", `[]`))
+
+	opts := &todoist.Opts{
+		Token:  "token",
+		Client: s.client(),
+		Middleware: []todoist.Middleware{
+			func(todoist.Handler) todoist.Handler {
+				return func(context.Context, *todoist.Call) (*todoist.Result, error) {
+					header := http.Header{"Retry-After": []string{"2"}, "X-Request-Id": []string{"cached"}}
+					return &todoist.Result{StatusCode: http.StatusTooManyRequests, Header: header, Body: []byte("slow down")}, nil
+				}
+			},
+		},
+	}
+
+	_, err := todoist.New(opts).GetProjects(context.Background())
+
+	var apiErr *todoist.ApiError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("GetProjects() error = %v, want %T", err, apiErr)
+	}
+	if !errors.Is(err, todoist.ErrRateLimited) {
+		t.Errorf("GetProjects() error = %v, want ErrRateLimited", err)
+	}
+	if apiErr.Method != http.MethodGet || apiErr.Endpoint != "projects" || apiErr.Status != "429 Too Many Requests" ||
+		apiErr.RequestId != "cached" || apiErr.RetryAfter != 2*time.Second || string(apiErr.Body) != "slow down" {
+		t.Errorf("ApiError = %+v", apiErr)
+	}
+	if s.count() != 0 {
+		t.Errorf("requests = %d, want none", s.count())
+	}
+}
+
+func TestMiddlewareOrder(t *testing.T) {
+	s := newStub(t, body("application/json", `[]`))
+
+	var got []string
+	trace := func(name string) todoist.Middleware {
+		return func(next todoist.Handler) todoist.Handler {
+			return func(ctx context.Context, call *todoist.Call) (*todoist.Result, error) {
+				got = append(got, name+" before")
+				result, err := next(ctx, call)
+				got = append(got, name+" after")
+				return result, err
+			}
+		}
+	}
+
+	opts := &todoist.Opts{Token: "token", Client: s.client(), Middleware: []todoist.Middleware{trace("outer"), trace("inner")}}
+	if _, err := todoist.New(opts).GetProjects(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"outer before", "inner before", "inner after", "outer after"}
+	if strings.Join(got, ", ") != strings.Join(want, ", ") {
+		t.Errorf("middleware ran as %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,188 @@
+// Package oauth implements the Todoist OAuth 2 authorization code flow.
+package oauth
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	todoist "github.com/temoon/todoist-api"
+)
+
+const (
+	AuthorizeUrl = "https://todoist.com/oauth/authorize"
+	TokenUrl     = "https://todoist.com/oauth/access_token"
+	RevokeUrl    = "https://api.todoist.com/sync/v9/access_tokens/revoke"
+)
+
+const (
+	ScopeTaskAdd       = "task:add"
+	ScopeDataRead      = "data:read"
+	ScopeDataReadWrite = "data:read_write"
+	ScopeDataDelete    = "data:delete"
+	ScopeProjectDelete = "project:delete"
+)
+
+type Config struct {
+	ClientId     string
+	ClientSecret string
+	Scopes       []string
+	AuthorizeUrl string
+	TokenUrl     string
+	RevokeUrl    string
+	Client       *http.Client
+}
+
+type Token struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+}
+
+// Token makes *Token a todoist.TokenSource.
+func (t *Token) Token(_ context.Context) (string, error) {
+	return t.AccessToken, nil
+}
+
+//goland:noinspection GoUnusedExportedFunction
+func GenerateState() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(buf), nil
+}
+
+func (c *Config) AuthCodeUrl(state string) string {
+	query := url.Values{}
+	query.Set("client_id", c.ClientId)
+	query.Set("scope", strings.Join(c.Scopes, ","))
+	query.Set("state", state)
+
+	return c.authorizeUrl() + "?" + query.Encode()
+}
+
+func (c *Config) Exchange(ctx context.Context, code string) (token *Token, err error) {
+	form := url.Values{}
+	form.Set("client_id", c.ClientId)
+	form.Set("client_secret", c.ClientSecret)
+	form.Set("code", code)
+
+	token = new(Token)
+	if err = c.post(ctx, c.tokenUrl(), "application/x-www-form-urlencoded", []byte(form.Encode()), token); err != nil {
+		return nil, err
+	}
+
+	if token.AccessToken == "" {
+		return nil, fmt.Errorf("%s: no access token in response", c.tokenUrl())
+	}
+
+	return
+}
+
+func (c *Config) Revoke(ctx context.Context, accessToken string) (err error) {
+	var payload []byte
+	if payload, err = json.Marshal(map[string]string{
+		"client_id":     c.ClientId,
+		"client_secret": c.ClientSecret,
+		"access_token":  accessToken,
+	}); err != nil {
+		return
+	}
+
+	return c.post(ctx, c.revokeUrl(), "application/json", payload, nil)
+}
+
+func (c *Config) post(ctx context.Context, endpoint string, contentType string, payload []byte, data interface{}) (err error) {
+	var req *http.Request
+	if req, err = http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(payload)); err != nil {
+		return
+	}
+	req.Header.Set("Content-Type", contentType)
+
+	var res *http.Response
+	if res, err = c.client().Do(req); err != nil {
+		return
+	}
+	//goland:noinspection GoUnhandledErrorResult
+	defer res.Body.Close()
+
+	var body []byte
+	if body, err = io.ReadAll(res.Body); err != nil {
+		return
+	}
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return newError(req, res, body)
+	}
+
+	if data == nil || len(bytes.TrimSpace(body)) == 0 {
+		return
+	}
+
+	return json.Unmarshal(body, data)
+}
+
+func newError(req *http.Request, res *http.Response, body []byte) *todoist.ApiError {
+	e := &todoist.ApiError{
+		StatusCode: res.StatusCode,
+		Status:     res.Status,
+		Method:     req.Method,
+		Endpoint:   req.URL.String(),
+		Body:       body,
+		RequestId:  res.Header.Get("X-Request-Id"),
+	}
+
+	var data struct {
+		Error string `json:"error"`
+	}
+	if json.Unmarshal(body, &data) == nil {
+		e.Tag = data.Error
+	} else {
+		e.Message = strings.TrimSpace(string(body))
+	}
+
+	return e
+}
+
+func (c *Config) authorizeUrl() string {
+	if c.AuthorizeUrl != "" {
+		return c.AuthorizeUrl
+	}
+
+	return AuthorizeUrl
+}
+
+func (c *Config) tokenUrl() string {
+	if c.TokenUrl != "" {
+		return c.TokenUrl
+	}
+
+	return TokenUrl
+}
+
+func (c *Config) revokeUrl() string {
+	if c.RevokeUrl != "" {
+		return c.RevokeUrl
+	}
+
+	return RevokeUrl
+}
+
+func (c *Config) client() *http.Client {
+	if c.Client != nil {
+		return c.Client
+	}
+
+	return &http.Client{
+		Timeout: 15 * time.Second,
+	}
+}
@@ -0,0 +1,155 @@
+package oauth_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	todoist "github.com/temoon/todoist-api"
+	"github.com/temoon/todoist-api/oauth"
+	"github.com/temoon/todoist-api/todoisttest"
+)
+
+func TestAuthCodeUrl(t *testing.T) {
+	srv := todoisttest.NewServer()
+	defer srv.Close()
+
+	config := srv.OAuthConfig(oauth.ScopeDataRead, oauth.ScopeTaskAdd)
+
+	state, err := oauth.GenerateState()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	authCodeUrl := config.AuthCodeUrl(state)
+	if !strings.HasPrefix(authCodeUrl, srv.URL+"/oauth/authorize?") {
+		t.Fatalf("AuthCodeUrl() = %s, want the authorize url of the server", authCodeUrl)
+	}
+
+	u, err := url.Parse(authCodeUrl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := u.Query()
+	if query.Get("client_id") != todoisttest.DefaultClientId || query.Get("scope") != "data:read,task:add" || query.Get("state") != state {
+		t.Errorf("AuthCodeUrl() query = %v", query)
+	}
+
+	res, err := srv.Client().Get(authCodeUrl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	var approved struct {
+		Code  string `json:"code"`
+		State string `json:"state"`
+	}
+	if err = json.NewDecoder(res.Body).Decode(&approved); err != nil {
+		t.Fatal(err)
+	}
+	if approved.Code == "" || approved.State != state {
+		t.Errorf("authorization = %+v, want a code and state %s", approved, state)
+	}
+
+	if _, err = config.Exchange(context.Background(), approved.Code); err != nil {
+		t.Errorf("Exchange() of the approved code error = %v", err)
+	}
+
+	if got := (&oauth.Config{ClientId: "client"}).AuthCodeUrl("s"); !strings.HasPrefix(got, oauth.AuthorizeUrl+"?") {
+		t.Errorf("AuthCodeUrl() without AuthorizeUrl = %s, want %s", got, oauth.AuthorizeUrl)
+	}
+}
+
+func TestExchange(t *testing.T) {
+	srv := todoisttest.NewServer()
+	defer srv.Close()
+
+	ctx := context.Background()
+	used := srv.AuthorizationCode()
+	if _, err := srv.OAuthConfig(oauth.ScopeDataRead).Exchange(ctx, used); err != nil {
+		t.Fatal(err)
+	}
+
+	wrongSecret := srv.OAuthConfig(oauth.ScopeDataRead)
+	wrongSecret.ClientSecret = "wrong"
+
+	tests := []struct {
+		name    string
+		config  *oauth.Config
+		code    string
+		wantTag string
+	}{
+		{name: "success", config: srv.OAuthConfig(oauth.ScopeDataRead), code: srv.AuthorizationCode()},
+		{name: "bad authorization code", config: srv.OAuthConfig(oauth.ScopeDataRead), code: "nope", wantTag: "bad_authorization_code"},
+		{name: "code used twice", config: srv.OAuthConfig(oauth.ScopeDataRead), code: used, wantTag: "bad_authorization_code"},
+		{name: "bad client credentials", config: wrongSecret, code: srv.AuthorizationCode(), wantTag: "bad_client_credentials"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := tt.config.Exchange(ctx, tt.code)
+
+			if tt.wantTag == "" {
+				if err != nil {
+					t.Fatalf("Exchange() error = %v", err)
+				}
+				if token.AccessToken == "" || token.TokenType != "Bearer" {
+					t.Errorf("Exchange() = %+v, want a bearer token", token)
+				}
+				return
+			}
+
+			var apiErr *todoist.ApiError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("Exchange() error = %v, want *ApiError", err)
+			}
+			if apiErr.StatusCode != http.StatusBadRequest || apiErr.Tag != tt.wantTag {
+				t.Errorf("Exchange() error = %+v, want 400 %s", apiErr, tt.wantTag)
+			}
+			if token != nil {
+				t.Errorf("Exchange() = %+v, want nil", token)
+			}
+		})
+	}
+}
+
+func TestTokenSource(t *testing.T) {
+	srv := todoisttest.NewServer()
+	defer srv.Close()
+
+	ctx := context.Background()
+	config := srv.OAuthConfig(oauth.ScopeDataReadWrite)
+
+	token, err := config.Exchange(ctx, srv.AuthorizationCode())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	opts := srv.Opts()
+	opts.Token = ""
+	opts.TokenSource = token
+	client := todoist.New(opts)
+
+	if _, err = client.GetProjects(ctx); err != nil {
+		t.Fatalf("GetProjects() with the exchanged token error = %v", err)
+	}
+
+	wrongSecret := srv.OAuthConfig()
+	wrongSecret.ClientSecret = "wrong"
+	if err = wrongSecret.Revoke(ctx, token.AccessToken); !errors.Is(err, todoist.ErrForbidden) {
+		t.Errorf("Revoke() with a wrong secret error = %v, want ErrForbidden", err)
+	}
+
+	if err = config.Revoke(ctx, token.AccessToken); err != nil {
+		t.Fatalf("Revoke() error = %v", err)
+	}
+
+	if _, err = client.GetProjects(ctx); !errors.Is(err, todoist.ErrUnauthorized) {
+		t.Errorf("GetProjects() with a revoked token error = %v, want ErrUnauthorized", err)
+	}
+}
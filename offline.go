@@ -0,0 +1,532 @@
+package todoist
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+var ErrUnresolvedTempId = errors.New("object was not created, the operation that created it was rejected")
+
+// OfflineOp is a mutation waiting to be sent. Its Id doubles as the idempotency key,
+// so an operation that reached Todoist before the connection dropped is not applied twice.
+type OfflineOp struct {
+	Id        string                 `json:"id"`
+	Operation string                 `json:"operation"`
+	TargetId  string                 `json:"target_id,omitempty"`
+	TempId    string                 `json:"temp_id,omitempty"`
+	Params    map[string]interface{} `json:"params,omitempty"`
+	QueuedAt  time.Time              `json:"queued_at"`
+}
+
+type Conflict struct {
+	Op  OfflineOp
+	Err error
+}
+
+type offlineState struct {
+	Ops     []OfflineOp       `json:"ops"`
+	TempIds map[string]string `json:"temp_ids"`
+}
+
+// OfflineQueue wraps the mutating methods of Todoist. When the network is unavailable, or earlier
+// operations are still pending, calls are stored in a file and replayed in order by Flush.
+// Objects created offline get a temp id, which may be used in later calls and is rewritten on replay.
+type OfflineQueue struct {
+	t    *Todoist
+	path string
+
+	OnConflict func(conflict Conflict)
+
+	mu    sync.Mutex
+	state offlineState
+	// resolved keeps the temp ids pruned from state, so that they can still be resolved.
+	resolved map[string]string
+}
+
+//goland:noinspection GoUnusedExportedFunction
+func OpenOfflineQueue(t *Todoist, path string) (q *OfflineQueue, err error) {
+	q = &OfflineQueue{
+		t:    t,
+		path: path,
+		state: offlineState{
+			TempIds: make(map[string]string),
+		},
+		resolved: make(map[string]string),
+	}
+
+	var data []byte
+	if data, err = os.ReadFile(path); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return q, nil
+		}
+		return nil, err
+	}
+
+	if err = json.Unmarshal(data, &q.state); err != nil {
+		return nil, err
+	}
+
+	if q.state.TempIds == nil {
+		q.state.TempIds = make(map[string]string)
+	}
+
+	return
+}
+
+func (q *OfflineQueue) Pending() []OfflineOp {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return append([]OfflineOp(nil), q.state.Ops...)
+}
+
+// ResolveId returns the real id of an object created offline, once it has been replayed. Temp ids are kept
+// in the file while queued operations refer to them, and in memory for the life of the queue.
+func (q *OfflineQueue) ResolveId(id string) string {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return q.resolve(id)
+}
+
+// Flush replays pending operations in order. Operations whose target no longer exists are reported
+// to OnConflict and dropped, together with later operations that refer to objects they should have created.
+// Any other error, like a network failure or a revoked token, stops the flush and keeps the operation
+// for the next call. An operation Todoist keeps rejecting can be removed with Discard.
+func (q *OfflineQueue) Flush(ctx context.Context) (replayed int, err error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for len(q.state.Ops) != 0 {
+		op := q.state.Ops[0]
+
+		var result interface{}
+		if result, err = q.execute(ctx, op); err != nil {
+			if !errors.Is(err, ErrNotFound) && !errors.Is(err, ErrUnresolvedTempId) {
+				return
+			}
+
+			if q.OnConflict != nil {
+				q.OnConflict(Conflict{Op: op, Err: err})
+			}
+		} else {
+			replayed++
+		}
+
+		if err = q.remove(0, createdId(result)); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// Discard removes a pending operation. Later operations that refer to an object it should have created
+// become conflicts. It returns false when no operation has the id.
+func (q *OfflineQueue) Discard(opId string) (ok bool, err error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for i, op := range q.state.Ops {
+		if op.Id == opId {
+			return true, q.remove(i, "")
+		}
+	}
+
+	return false, nil
+}
+
+// region Tasks
+
+func (q *OfflineQueue) AddTask(ctx context.Context, params *AddTaskParams) (task *Task, err error) {
+	var result interface{}
+	var tempId string
+	if result, tempId, err = q.do(ctx, "AddTask", "", *params); err != nil || result != nil {
+		task, _ = result.(*Task)
+		return
+	}
+
+	task = new(Task)
+	if err = placeholder(*params, task); err == nil {
+		task.Id = tempId
+	}
+
+	return
+}
+
+func (q *OfflineQueue) UpdateTask(ctx context.Context, taskId string, params *UpdateTaskParams) (err error) {
+	_, _, err = q.do(ctx, "UpdateTask", taskId, *params)
+	return
+}
+
+func (q *OfflineQueue) CloseTask(ctx context.Context, taskId string) (err error) {
+	_, _, err = q.do(ctx, "CloseTask", taskId, nil)
+	return
+}
+
+func (q *OfflineQueue) ReopenTask(ctx context.Context, taskId string) (err error) {
+	_, _, err = q.do(ctx, "ReopenTask", taskId, nil)
+	return
+}
+
+func (q *OfflineQueue) DeleteTask(ctx context.Context, taskId string) (err error) {
+	_, _, err = q.do(ctx, "DeleteTask", taskId, nil)
+	return
+}
+
+// endregion
+
+// region Projects
+
+func (q *OfflineQueue) AddProject(ctx context.Context, params *AddProjectParams) (project *Project, err error) {
+	var result interface{}
+	var tempId string
+	if result, tempId, err = q.do(ctx, "AddProject", "", *params); err != nil || result != nil {
+		project, _ = result.(*Project)
+		return
+	}
+
+	project = new(Project)
+	if err = placeholder(*params, project); err == nil {
+		project.Id = tempId
+	}
+
+	return
+}
+
+func (q *OfflineQueue) UpdateProject(ctx context.Context, projectId string, params *UpdateProjectParams) (err error) {
+	_, _, err = q.do(ctx, "UpdateProject", projectId, *params)
+	return
+}
+
+func (q *OfflineQueue) DeleteProject(ctx context.Context, projectId string) (err error) {
+	_, _, err = q.do(ctx, "DeleteProject", projectId, nil)
+	return
+}
+
+// endregion
+
+// region Sections
+
+func (q *OfflineQueue) AddSection(ctx context.Context, params *AddSectionParams) (section *Section, err error) {
+	var result interface{}
+	var tempId string
+	if result, tempId, err = q.do(ctx, "AddSection", "", *params); err != nil || result != nil {
+		section, _ = result.(*Section)
+		return
+	}
+
+	section = new(Section)
+	if err = placeholder(*params, section); err == nil {
+		section.Id = tempId
+	}
+
+	return
+}
+
+func (q *OfflineQueue) UpdateSection(ctx context.Context, sectionId string, params *UpdateSectionParams) (err error) {
+	_, _, err = q.do(ctx, "UpdateSection", sectionId, *params)
+	return
+}
+
+func (q *OfflineQueue) DeleteSection(ctx context.Context, sectionId string) (err error) {
+	_, _, err = q.do(ctx, "DeleteSection", sectionId, nil)
+	return
+}
+
+// endregion
+
+// region Labels
+
+func (q *OfflineQueue) AddLabel(ctx context.Context, params *AddLabelParams) (label *Label, err error) {
+	var result interface{}
+	var tempId string
+	if result, tempId, err = q.do(ctx, "AddLabel", "", *params); err != nil || result != nil {
+		label, _ = result.(*Label)
+		return
+	}
+
+	label = new(Label)
+	if err = placeholder(*params, label); err == nil {
+		label.Id = tempId
+	}
+
+	return
+}
+
+func (q *OfflineQueue) UpdateLabel(ctx context.Context, labelId string, params *UpdateLabelParams) (err error) {
+	_, _, err = q.do(ctx, "UpdateLabel", labelId, *params)
+	return
+}
+
+func (q *OfflineQueue) DeleteLabel(ctx context.Context, labelId string) (err error) {
+	_, _, err = q.do(ctx, "DeleteLabel", labelId, nil)
+	return
+}
+
+// endregion
+
+// region Comments
+
+func (q *OfflineQueue) AddComment(ctx context.Context, params *AddCommentParams) (comment *Comment, err error) {
+	var result interface{}
+	var tempId string
+	if result, tempId, err = q.do(ctx, "AddComment", "", *params); err != nil || result != nil {
+		comment, _ = result.(*Comment)
+		return
+	}
+
+	comment = new(Comment)
+	if err = placeholder(*params, comment); err == nil {
+		comment.Id = tempId
+	}
+
+	return
+}
+
+func (q *OfflineQueue) UpdateComment(ctx context.Context, commentId string, params *UpdateCommentParams) (err error) {
+	_, _, err = q.do(ctx, "UpdateComment", commentId, *params)
+	return
+}
+
+func (q *OfflineQueue) DeleteComment(ctx context.Context, commentId string) (err error) {
+	_, _, err = q.do(ctx, "DeleteComment", commentId, nil)
+	return
+}
+
+// endregion
+
+// do runs the operation right away when nothing is pending, and queues it when something is
+// or when the network fails. Queued operations that create objects get a temp id.
+func (q *OfflineQueue) do(ctx context.Context, operation string, targetId string, params map[string]interface{}) (result interface{}, tempId string, err error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	op := OfflineOp{
+		Id:        NewUuid(),
+		Operation: operation,
+		TargetId:  targetId,
+		Params:    params,
+		QueuedAt:  time.Now(),
+	}
+
+	if len(q.state.Ops) == 0 {
+		if result, err = q.execute(ctx, op); err == nil || !isNetworkError(ctx, err) {
+			return
+		}
+	}
+
+	if strings.HasPrefix(operation, "Add") {
+		op.TempId = NewUuid()
+	}
+
+	q.state.Ops = append(q.state.Ops, op)
+	if err = q.save(); err != nil {
+		q.state.Ops = q.state.Ops[:len(q.state.Ops)-1]
+		return
+	}
+
+	return nil, op.TempId, nil
+}
+
+func (q *OfflineQueue) execute(ctx context.Context, op OfflineOp) (result interface{}, err error) {
+	ctx = WithIdempotencyKey(ctx, op.Id)
+
+	// Rejected temp ids resolve to nothing, so they are checked before resolving.
+	if q.isRejected(op.TargetId) {
+		return nil, ErrUnresolvedTempId
+	}
+
+	for _, key := range []string{"project_id", "section_id", "parent_id", "task_id"} {
+		if value, ok := op.Params[key].(string); ok && q.isRejected(value) {
+			return nil, ErrUnresolvedTempId
+		}
+	}
+
+	targetId := q.resolve(op.TargetId)
+	args, _ := resolveTempIds(resolveTempIds(op.Params, q.state.TempIds), q.resolved).(map[string]interface{})
+
+	switch op.Operation {
+	case "AddTask":
+		p := AddTaskParams(args)
+		result, err = q.t.AddTask(ctx, &p)
+	case "UpdateTask":
+		p := UpdateTaskParams(args)
+		err = q.t.UpdateTask(ctx, targetId, &p)
+	case "CloseTask":
+		err = q.t.CloseTask(ctx, targetId)
+	case "ReopenTask":
+		err = q.t.ReopenTask(ctx, targetId)
+	case "DeleteTask":
+		err = q.t.DeleteTask(ctx, targetId)
+	case "AddProject":
+		p := AddProjectParams(args)
+		result, err = q.t.AddProject(ctx, &p)
+	case "UpdateProject":
+		p := UpdateProjectParams(args)
+		err = q.t.UpdateProject(ctx, targetId, &p)
+	case "DeleteProject":
+		err = q.t.DeleteProject(ctx, targetId)
+	case "AddSection":
+		p := AddSectionParams(args)
+		result, err = q.t.AddSection(ctx, &p)
+	case "UpdateSection":
+		p := UpdateSectionParams(args)
+		err = q.t.UpdateSection(ctx, targetId, &p)
+	case "DeleteSection":
+		err = q.t.DeleteSection(ctx, targetId)
+	case "AddLabel":
+		p := AddLabelParams(args)
+		result, err = q.t.AddLabel(ctx, &p)
+	case "UpdateLabel":
+		p := UpdateLabelParams(args)
+		err = q.t.UpdateLabel(ctx, targetId, &p)
+	case "DeleteLabel":
+		err = q.t.DeleteLabel(ctx, targetId)
+	case "AddComment":
+		p := AddCommentParams(args)
+		result, err = q.t.AddComment(ctx, &p)
+	case "UpdateComment":
+		p := UpdateCommentParams(args)
+		err = q.t.UpdateComment(ctx, targetId, &p)
+	case "DeleteComment":
+		err = q.t.DeleteComment(ctx, targetId)
+	default:
+		err = errors.New("unknown offline operation: " + op.Operation)
+	}
+
+	if err != nil {
+		result = nil
+	}
+
+	return
+}
+
+// remove drops the operation at i. The temp id of an operation that created nothing is marked as rejected.
+func (q *OfflineQueue) remove(i int, createdId string) error {
+	op := q.state.Ops[i]
+	if op.TempId != "" {
+		q.state.TempIds[op.TempId] = createdId
+	}
+
+	q.state.Ops = append(q.state.Ops[:i:i], q.state.Ops[i+1:]...)
+	q.prune()
+
+	return q.save()
+}
+
+// prune moves the temp ids no queued operation refers to from the file to memory.
+func (q *OfflineQueue) prune() {
+	referenced := make(map[string]bool)
+	for _, op := range q.state.Ops {
+		referenced[op.TargetId] = true
+		collectStrings(op.Params, referenced)
+	}
+
+	for tempId, realId := range q.state.TempIds {
+		if !referenced[tempId] {
+			q.resolved[tempId] = realId
+			delete(q.state.TempIds, tempId)
+		}
+	}
+}
+
+func (q *OfflineQueue) lookup(id string) (realId string, ok bool) {
+	if realId, ok = q.state.TempIds[id]; !ok {
+		realId, ok = q.resolved[id]
+	}
+
+	return
+}
+
+func (q *OfflineQueue) resolve(id string) string {
+	if realId, _ := q.lookup(id); realId != "" {
+		return realId
+	}
+
+	return id
+}
+
+// isRejected reports temp ids of objects whose creation was rejected during replay.
+func (q *OfflineQueue) isRejected(id string) bool {
+	realId, ok := q.lookup(id)
+	return ok && realId == ""
+}
+
+func (q *OfflineQueue) save() (err error) {
+	var data []byte
+	if data, err = json.Marshal(q.state); err != nil {
+		return
+	}
+
+	return writeFile(q.path, data)
+}
+
+func placeholder(params map[string]interface{}, object interface{}) (err error) {
+	var data []byte
+	if data, err = json.Marshal(params); err != nil {
+		return
+	}
+
+	return json.Unmarshal(data, object)
+}
+
+func createdId(result interface{}) string {
+	switch result := result.(type) {
+	case *Task:
+		return result.Id
+	case *Project:
+		return result.Id
+	case *Section:
+		return result.Id
+	case *Label:
+		return result.Id
+	case *Comment:
+		return result.Id
+	default:
+		return ""
+	}
+}
+
+func collectStrings(value interface{}, values map[string]bool) {
+	switch value := value.(type) {
+	case string:
+		values[value] = true
+	case []string:
+		for _, item := range value {
+			values[item] = true
+		}
+	case []interface{}:
+		for _, item := range value {
+			collectStrings(item, values)
+		}
+	case map[string]interface{}:
+		for _, item := range value {
+			collectStrings(item, values)
+		}
+	}
+}
+
+// isNetworkError reports errors that happened before any response was received and may go away once
+// the network is back: the retryable ones, failed lookups and unreachable networks.
+func isNetworkError(ctx context.Context, err error) bool {
+	var apiErr *ApiError
+	var decodeErr *DecodeError
+	if errors.As(err, &apiErr) || errors.As(err, &decodeErr) {
+		return false
+	}
+
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return ctx.Err() == nil
+	}
+
+	return isRetryable(ctx, err) || errors.Is(err, syscall.ENETUNREACH) || errors.Is(err, syscall.EHOSTUNREACH)
+}
@@ -0,0 +1,233 @@
+package todoist_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	todoist "github.com/temoon/todoist-api"
+)
+
+func openQueue(t *testing.T, opts *todoist.Opts, path string) *todoist.OfflineQueue {
+	q, err := todoist.OpenOfflineQueue(todoist.New(opts), path)
+	if err != nil {
+		t.Fatalf("OpenOfflineQueue() error = %v", err)
+	}
+
+	return q
+}
+
+func TestOfflineQueueOnline(t *testing.T) {
+	srv := newServer(t)
+	q := openQueue(t, srv.Opts(), filepath.Join(t.TempDir(), "queue.json"))
+
+	task, err := q.AddTask(context.Background(), todoist.MakeAddTaskParams().WithContent("Buy milk"))
+	if err != nil {
+		t.Fatalf("AddTask() error = %v", err)
+	}
+	if len(q.Pending()) != 0 {
+		t.Errorf("Pending() = %v, want none", q.Pending())
+	}
+	if _, err = srv.Todoist().GetTask(context.Background(), task.Id); err != nil {
+		t.Errorf("GetTask(%q) error = %v", task.Id, err)
+	}
+}
+
+func TestOfflineQueueReplay(t *testing.T) {
+	srv := newServer(t)
+	s := newStub(t, srv.Config.Handler)
+	path := filepath.Join(t.TempDir(), "queue.json")
+	ctx := context.Background()
+
+	q := openQueue(t, s.opts(srv), path)
+
+	s.setDown(true)
+
+	project, err := q.AddProject(ctx, todoist.MakeAddProjectParams().WithName("Groceries"))
+	if err != nil {
+		t.Fatalf("AddProject() error = %v", err)
+	}
+	if project.Name != "Groceries" {
+		t.Errorf("placeholder project = %+v", project)
+	}
+
+	task, err := q.AddTask(ctx, todoist.MakeAddTaskParams().WithContent("Buy milk").WithProjectId(project.Id))
+	if err != nil {
+		t.Fatalf("AddTask() error = %v", err)
+	}
+
+	if err = q.CloseTask(ctx, task.Id); err != nil {
+		t.Fatalf("CloseTask() error = %v", err)
+	}
+
+	if got := len(q.Pending()); got != 3 {
+		t.Fatalf("len(Pending()) = %d, want 3", got)
+	}
+
+	// The file keeps the operations for another process.
+	if got := len(openQueue(t, s.opts(srv), path).Pending()); got != 3 {
+		t.Fatalf("len(Pending()) after reopening = %d, want 3", got)
+	}
+
+	if _, err = q.Flush(ctx); err == nil {
+		t.Fatal("Flush() while down succeeded")
+	}
+	if got := len(q.Pending()); got != 3 {
+		t.Fatalf("len(Pending()) after a failed flush = %d, want 3", got)
+	}
+
+	s.setDown(false)
+
+	replayed, err := q.Flush(ctx)
+	if err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	if replayed != 3 || len(q.Pending()) != 0 {
+		t.Fatalf("Flush() = %d, pending %d, want 3 and none", replayed, len(q.Pending()))
+	}
+
+	projectId := q.ResolveId(project.Id)
+	if projectId == project.Id {
+		t.Fatalf("ResolveId(%q) was not resolved", project.Id)
+	}
+
+	taskId := q.ResolveId(task.Id)
+	created, err := srv.Todoist().GetTask(ctx, taskId)
+	if err != nil {
+		t.Fatalf("GetTask(%q) error = %v", taskId, err)
+	}
+	if created.ProjectId != projectId || !created.IsCompleted {
+		t.Errorf("replayed task = %+v, want completed in %s", created, projectId)
+	}
+}
+
+func TestOfflineQueueConflicts(t *testing.T) {
+	tests := []struct {
+		name      string
+		queue     func(t *testing.T, q *todoist.OfflineQueue) (discard string)
+		replayed  int
+		conflicts []error
+	}{
+		{
+			name: "missing task",
+			queue: func(t *testing.T, q *todoist.OfflineQueue) string {
+				mustQueue(t, q.CloseTask(context.Background(), "404404"))
+				_, err := q.AddTask(context.Background(), todoist.MakeAddTaskParams().WithContent("Buy milk"))
+				mustQueue(t, err)
+				return ""
+			},
+			replayed:  1,
+			conflicts: []error{todoist.ErrNotFound},
+		},
+		{
+			name: "missing project",
+			queue: func(t *testing.T, q *todoist.OfflineQueue) string {
+				_, err := q.AddTask(context.Background(), todoist.MakeAddTaskParams().WithContent("Buy milk").WithProjectId("404404"))
+				mustQueue(t, err)
+				return ""
+			},
+			conflicts: []error{todoist.ErrNotFound},
+		},
+		{
+			name: "discarded parent",
+			queue: func(t *testing.T, q *todoist.OfflineQueue) string {
+				project, err := q.AddProject(context.Background(), todoist.MakeAddProjectParams().WithName("Groceries"))
+				mustQueue(t, err)
+				_, err = q.AddTask(context.Background(), todoist.MakeAddTaskParams().WithContent("Buy milk").WithProjectId(project.Id))
+				mustQueue(t, err)
+				return q.Pending()[0].Id
+			},
+			conflicts: []error{todoist.ErrUnresolvedTempId},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newServer(t)
+			s := newStub(t, srv.Config.Handler)
+			q := openQueue(t, s.opts(srv), filepath.Join(t.TempDir(), "queue.json"))
+
+			var conflicts []error
+			q.OnConflict = func(conflict todoist.Conflict) {
+				conflicts = append(conflicts, conflict.Err)
+			}
+
+			s.setDown(true)
+			discard := tt.queue(t, q)
+			s.setDown(false)
+
+			if discard != "" {
+				if ok, err := q.Discard(discard); !ok || err != nil {
+					t.Fatalf("Discard() = %v, %v", ok, err)
+				}
+			}
+
+			replayed, err := q.Flush(context.Background())
+			if err != nil {
+				t.Fatalf("Flush() error = %v", err)
+			}
+			if replayed != tt.replayed {
+				t.Errorf("Flush() = %d, want %d", replayed, tt.replayed)
+			}
+			if len(q.Pending()) != 0 {
+				t.Errorf("Pending() = %v, want none", q.Pending())
+			}
+
+			if len(conflicts) != len(tt.conflicts) {
+				t.Fatalf("conflicts = %v, want %v", conflicts, tt.conflicts)
+			}
+			for i, want := range tt.conflicts {
+				if !errors.Is(conflicts[i], want) {
+					t.Errorf("conflict %d = %v, want %v", i, conflicts[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestOfflineQueueKeepsOpsOnOtherErrors(t *testing.T) {
+	srv := newServer(t)
+	s := newStub(t, srv.Config.Handler)
+	q := openQueue(t, s.opts(srv), filepath.Join(t.TempDir(), "queue.json"))
+	ctx := context.Background()
+
+	s.setDown(true)
+	_, err := q.AddTask(ctx, todoist.MakeAddTaskParams().WithContent("Buy milk"))
+	mustQueue(t, err)
+	_, err = q.AddTask(ctx, todoist.MakeAddTaskParams().WithContent("Buy bread"))
+	mustQueue(t, err)
+	s.setDown(false)
+
+	s.fail(status(http.StatusUnauthorized))
+	if _, err = q.Flush(ctx); !errors.Is(err, todoist.ErrUnauthorized) {
+		t.Fatalf("Flush() error = %v, want ErrUnauthorized", err)
+	}
+	if got := len(q.Pending()); got != 2 {
+		t.Fatalf("len(Pending()) = %d, want 2", got)
+	}
+
+	if replayed, err := q.Flush(ctx); err != nil || replayed != 2 {
+		t.Fatalf("Flush() = %d, %v, want 2", replayed, err)
+	}
+}
+
+func TestOfflineQueueReturnsPermanentErrors(t *testing.T) {
+	q := openQueue(t, &todoist.Opts{Token: "token", BaseUrl: "ftp://api.todoist.com/rest/v2/"}, filepath.Join(t.TempDir(), "queue.json"))
+
+	if _, err := q.AddTask(context.Background(), todoist.MakeAddTaskParams().WithContent("Buy milk")); err == nil {
+		t.Fatal("AddTask() error = nil")
+	}
+	if len(q.Pending()) != 0 {
+		t.Errorf("Pending() = %v, want none", q.Pending())
+	}
+}
+
+func mustQueue(t *testing.T, err error) {
+	t.Helper()
+
+	if err != nil {
+		t.Fatalf("queueing failed: %v", err)
+	}
+}
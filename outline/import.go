@@ -0,0 +1,382 @@
+package outline
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	todoist "github.com/temoon/todoist-api"
+)
+
+var ErrEmptyContent = errors.New("checklist item has no content")
+
+// Item is a checklist item. Priority is as sent to the API, 4 is p1 and 1 the default.
+// Lines indented under an item that are not items themselves make its description.
+type Item struct {
+	Content     string
+	Description string
+	Priority    int
+	Labels      []string
+	Due         string
+	Checked     bool
+	Children    []Item
+}
+
+type Section struct {
+	Name  string
+	Items []Item
+}
+
+// Checklist is a parsed Markdown document. Items before the first heading have no section.
+type Checklist struct {
+	Title    string
+	Items    []Item
+	Sections []Section
+}
+
+type ImportOpts struct {
+	// DueLang is the language of due strings, English by default.
+	DueLang string
+	// IncludeChecked creates checked items and closes them. They are left out by default, with their subtasks.
+	IncludeChecked bool
+}
+
+// ImportResult lists what was created, sections and tasks in the order of the document.
+type ImportResult struct {
+	Sections []todoist.Section
+	Tasks    []todoist.Task
+}
+
+type line struct {
+	number int
+	indent int
+	text   string
+}
+
+// ParseChecklist reads a Markdown document. Headings become sections, whatever their level, except for
+// a single first level heading at the top when there are other headings, which is the title, like
+// the one Render writes. Items are "- [ ]" or "- [x]" list entries, also with "*" or "+", and nest
+// under the closest item above with a smaller indent. Other lines outside of items are ignored.
+//
+// The words p1 to p4, @label and due:date, or due:"date with spaces", are taken out of the content.
+func ParseChecklist(r io.Reader) (checklist *Checklist, err error) {
+	var headings []line
+	var body [][]line
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1024*1024)
+
+	body = append(body, nil)
+	for number := 1; scanner.Scan(); number++ {
+		text := strings.TrimRight(strings.TrimPrefix(scanner.Text(), "\ufeff"), " \t\r")
+		trimmed := strings.TrimLeft(text, " \t")
+
+		if level := headingLevel(trimmed); level != 0 && len(trimmed) == len(text) {
+			headings = append(headings, line{number: number, indent: level, text: strings.TrimSpace(strings.Trim(trimmed[level:], " #"))})
+			body = append(body, nil)
+			continue
+		}
+
+		body[len(body)-1] = append(body[len(body)-1], line{number: number, indent: indentWidth(text), text: trimmed})
+	}
+
+	if err = scanner.Err(); err != nil {
+		return
+	}
+
+	checklist = new(Checklist)
+
+	// The title is only told apart from sections by being the one top level heading above everything.
+	if len(headings) > 1 && headings[0].indent == 1 && !hasItems(body[0]) {
+		title := true
+		for _, heading := range headings[1:] {
+			title = title && heading.indent > 1
+		}
+
+		if title {
+			checklist.Title = headings[0].text
+			headings = headings[1:]
+			body = append([][]line{append(body[0], body[1]...)}, body[2:]...)
+		}
+	}
+
+	if checklist.Items, err = parseItems(body[0]); err != nil {
+		return nil, err
+	}
+
+	for i, heading := range headings {
+		section := Section{Name: heading.text}
+		if section.Items, err = parseItems(body[i+1]); err != nil {
+			return nil, err
+		}
+		checklist.Sections = append(checklist.Sections, section)
+	}
+
+	return
+}
+
+// Import parses a Markdown checklist and creates it in a project.
+func Import(ctx context.Context, t *todoist.Todoist, projectId string, r io.Reader, opts *ImportOpts) (result *ImportResult, err error) {
+	var checklist *Checklist
+	if checklist, err = ParseChecklist(r); err != nil {
+		return
+	}
+
+	return checklist.Apply(ctx, t, projectId, opts)
+}
+
+// Apply creates the sections and tasks of the checklist in a project, siblings in their order. It stops
+// at the first error and returns what was created so far.
+func (c *Checklist) Apply(ctx context.Context, t *todoist.Todoist, projectId string, opts *ImportOpts) (result *ImportResult, err error) {
+	if opts == nil {
+		opts = &ImportOpts{}
+	}
+
+	result = new(ImportResult)
+
+	var add func(items []Item, sectionId string, parentId string) error
+	add = func(items []Item, sectionId string, parentId string) (err error) {
+		order := 0
+		for _, item := range items {
+			if item.Checked && !opts.IncludeChecked {
+				continue
+			}
+
+			order++
+			params := todoist.MakeAddTaskParams().
+				WithContent(item.Content).
+				WithDescription(item.Description).
+				WithProjectId(projectId).
+				WithSectionId(sectionId).
+				WithParentId(parentId).
+				WithOrder(order).
+				WithPriority(item.Priority).
+				WithDueString(item.Due)
+
+			if item.Due != "" {
+				params.WithDueLang(opts.DueLang)
+			}
+
+			if len(item.Labels) != 0 {
+				params.WithLabels(item.Labels)
+			}
+
+			var task *todoist.Task
+			if task, err = t.AddTask(ctx, params); err != nil {
+				return
+			}
+
+			result.Tasks = append(result.Tasks, *task)
+
+			if err = add(item.Children, sectionId, task.Id); err != nil {
+				return
+			}
+
+			if item.Checked {
+				if err = t.CloseTask(ctx, task.Id); err != nil {
+					return
+				}
+				result.Tasks[len(result.Tasks)-1].IsCompleted = true
+			}
+		}
+
+		return
+	}
+
+	if err = add(c.Items, "", ""); err != nil {
+		return
+	}
+
+	for i, section := range c.Sections {
+		params := todoist.MakeAddSectionParams().
+			WithName(section.Name).
+			WithProjectId(projectId).
+			WithOrder(i + 1)
+
+		var created *todoist.Section
+		if created, err = t.AddSection(ctx, params); err != nil {
+			return
+		}
+
+		result.Sections = append(result.Sections, *created)
+
+		if err = add(section.Items, created.Id, ""); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// parseItems builds the item trees of the lines under a heading.
+func parseItems(lines []line) (items []Item, err error) {
+	type open struct {
+		indent int
+		item   *Item
+	}
+
+	root := &Item{}
+	stack := []open{{indent: -1, item: root}}
+
+	for _, l := range lines {
+		checked, text, ok := checkbox(l.text)
+		if !ok {
+			// Lines indented under an item continue its description, other lines end the items above.
+			for len(stack) > 1 && l.text != "" && stack[len(stack)-1].indent >= l.indent {
+				stack = stack[:len(stack)-1]
+			}
+
+			if top := stack[len(stack)-1]; top.item != root {
+				extra := l.indent - top.indent - 2
+				if extra < 0 || l.text == "" {
+					extra = 0
+				}
+				top.item.Description += "\n" + strings.Repeat(" ", extra) + l.text
+			}
+			continue
+		}
+
+		item := Item{Checked: checked, Priority: 1}
+		if err = item.parseTokens(text); err != nil {
+			return nil, fmt.Errorf("line %d: %w", l.number, err)
+		}
+
+		for len(stack) > 1 && stack[len(stack)-1].indent >= l.indent {
+			stack = stack[:len(stack)-1]
+		}
+
+		parent := stack[len(stack)-1].item
+		parent.Children = append(parent.Children, item)
+		stack = append(stack, open{indent: l.indent, item: &parent.Children[len(parent.Children)-1]})
+	}
+
+	trimDescriptions(root.Children)
+
+	return root.Children, nil
+}
+
+// parseTokens sets the content of the item, without its priority, labels and due.
+func (item *Item) parseTokens(text string) error {
+	var words []string
+	for text = strings.TrimSpace(text); text != ""; text = strings.TrimLeft(text, " \t") {
+		word := text
+		if i := strings.IndexAny(text, " \t"); i != -1 {
+			word = text[:i]
+		}
+
+		switch {
+		case len(word) == 2 && word[0] == 'p' && word[1] >= '1' && word[1] <= '4':
+			item.Priority = 5 - int(word[1]-'0')
+		case len(word) > 1 && word[0] == '@':
+			item.Labels = append(item.Labels, word[1:])
+		case strings.HasPrefix(word, `due:"`):
+			end := closingQuote(text, len(`due:"`))
+			if end == -1 {
+				words = append(words, word)
+				break
+			}
+			due, err := strconv.Unquote(text[len("due:") : end+1])
+			if err != nil {
+				return err
+			}
+			item.Due, word = due, text[:end+1]
+		case strings.HasPrefix(word, "due:") && len(word) > len("due:"):
+			item.Due = word[len("due:"):]
+		default:
+			words = append(words, word)
+		}
+
+		text = text[len(word):]
+	}
+
+	if item.Content = strings.Join(words, " "); item.Content == "" {
+		return ErrEmptyContent
+	}
+
+	return nil
+}
+
+// checkbox returns the text of a task list entry.
+func checkbox(text string) (checked bool, rest string, ok bool) {
+	if len(text) < 5 || !strings.ContainsRune("-*+", rune(text[0])) || text[1] != ' ' {
+		return
+	}
+
+	switch strings.ToLower(text[2:5]) {
+	case "[ ]":
+	case "[x]":
+		checked = true
+	default:
+		return
+	}
+
+	if len(text) > 5 && text[5] != ' ' && text[5] != '\t' {
+		return false, "", false
+	}
+
+	return checked, text[5:], true
+}
+
+func headingLevel(text string) int {
+	level := 0
+	for level < len(text) && level < 7 && text[level] == '#' {
+		level++
+	}
+
+	if level == 0 || level > 6 || level < len(text) && text[level] != ' ' && text[level] != '\t' {
+		return 0
+	}
+
+	return level
+}
+
+// indentWidth counts leading spaces, with tabs up to the next multiple of four.
+func indentWidth(text string) (width int) {
+	for _, r := range text {
+		switch r {
+		case ' ':
+			width++
+		case '\t':
+			width += 4 - width%4
+		default:
+			return
+		}
+	}
+
+	return
+}
+
+// closingQuote returns the index of the quote ending a string that starts before from.
+func closingQuote(text string, from int) int {
+	for i := from; i < len(text); i++ {
+		switch text[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+
+	return -1
+}
+
+func hasItems(lines []line) bool {
+	for _, l := range lines {
+		if _, _, ok := checkbox(l.text); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+func trimDescriptions(items []Item) {
+	for i := range items {
+		items[i].Description = strings.Trim(items[i].Description, "\n")
+		trimDescriptions(items[i].Children)
+	}
+}
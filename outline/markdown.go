@@ -0,0 +1,133 @@
+package outline
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	todoist "github.com/temoon/todoist-api"
+)
+
+// renderMarkdown writes the project as a heading, sections as second level headings and tasks as checklists
+// nested by two spaces. Priority, labels and due follow the content as p1, @label and due: tokens, the ones
+// the checklist import reads back. Descriptions and quoted comments are indented under their task.
+func renderMarkdown(b *strings.Builder, project *Project, opts *Opts) {
+	projectComments, groups := groups(project, opts)
+
+	fmt.Fprintf(b, "# %s\n", inline(project.Project.Name))
+
+	if len(projectComments) != 0 {
+		b.WriteString("\n")
+		markdownComments(b, "", projectComments, opts)
+	}
+
+	for _, group := range groups {
+		if group.section != nil {
+			fmt.Fprintf(b, "\n## %s\n", inline(group.section.Name))
+		} else if len(group.nodes) == 0 {
+			continue
+		}
+
+		if len(group.nodes) != 0 {
+			b.WriteString("\n")
+		}
+
+		for _, n := range group.nodes {
+			markdownTask(b, n, 1, opts)
+		}
+	}
+}
+
+func markdownTask(b *strings.Builder, n *node, depth int, opts *Opts) {
+	indent := strings.Repeat("  ", depth-1)
+
+	check := " "
+	if n.task.IsCompleted {
+		check = "x"
+	}
+
+	fmt.Fprintf(b, "%s- [%s] %s", indent, check, inline(n.task.Content))
+
+	if n.task.Priority >= 2 && n.task.Priority <= 4 {
+		b.WriteString(" p" + strconv.Itoa(5-n.task.Priority))
+	}
+
+	for _, label := range n.task.Labels {
+		b.WriteString(" @" + label)
+	}
+
+	if due := dueText(n.task.Due, opts); due != "" {
+		if strings.ContainsAny(due, " \t\"") {
+			due = strconv.Quote(due)
+		}
+		b.WriteString(" due:" + due)
+	}
+
+	b.WriteString("\n")
+
+	if opts.IncludeDescriptions && strings.TrimSpace(n.task.Description) != "" {
+		for _, line := range lines(n.task.Description) {
+			writeLine(b, indent+"  ", line)
+		}
+	}
+
+	markdownComments(b, indent+"  ", n.comments, opts)
+
+	if opts.MaxDepth == 0 || depth < opts.MaxDepth {
+		for _, child := range n.children {
+			markdownTask(b, child, depth+1, opts)
+		}
+	}
+}
+
+// markdownComments quotes every comment, prefixed by the time it was posted.
+func markdownComments(b *strings.Builder, indent string, comments []todoist.Comment, opts *Opts) {
+	for _, comment := range comments {
+		content := comment.Content
+		if name, url := attachment(comment); url != "" {
+			content = strings.TrimSpace(content + "\n[" + name + "](" + url + ")")
+		}
+
+		for i, line := range lines(content) {
+			if i == 0 {
+				line = postedAt(comment, opts) + ": " + line
+			}
+			writeLine(b, indent+"> ", line)
+		}
+	}
+}
+
+// writeLine writes a prefixed line without trailing spaces.
+func writeLine(b *strings.Builder, prefix string, line string) {
+	b.WriteString(strings.TrimRight(prefix+strings.TrimRight(line, " \t"), " \t") + "\n")
+}
+
+// inline puts multiline text on one line.
+func inline(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+func postedAt(comment todoist.Comment, opts *Opts) string {
+	posted, err := time.Parse(time.RFC3339Nano, comment.PostedAt)
+	if err != nil {
+		return comment.PostedAt
+	}
+
+	loc := opts.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	return posted.In(loc).Format("2006-01-02 15:04")
+}
+
+func attachment(comment todoist.Comment) (name string, url string) {
+	name, _ = comment.Attachment["file_name"].(string)
+	url, _ = comment.Attachment["file_url"].(string)
+	if name == "" {
+		name = url
+	}
+
+	return
+}
@@ -0,0 +1,205 @@
+package outline
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	todoist "github.com/temoon/todoist-api"
+)
+
+var orgPriorities = map[int]string{4: "A", 3: "B", 2: "C"}
+
+// renderOrg writes the project as an Org-mode document. Sections are first level headings, and tasks
+// are headings one level below their section or parent, with the due as a deadline, labels as tags and
+// comments as a list. Recurring dues that have no Org repeater keep their due string in the RECURRENCE property.
+func renderOrg(b *strings.Builder, project *Project, opts *Opts) {
+	projectComments, groups := groups(project, opts)
+
+	fmt.Fprintf(b, "#+TITLE: %s\n", inline(project.Project.Name))
+
+	if len(projectComments) != 0 {
+		b.WriteString("\n")
+		orgComments(b, "", projectComments, opts)
+	}
+
+	for _, group := range groups {
+		level := 1
+		if group.section != nil {
+			fmt.Fprintf(b, "\n* %s\n", inline(group.section.Name))
+			level = 2
+		} else if len(group.nodes) == 0 {
+			continue
+		}
+
+		if len(group.nodes) != 0 && group.section == nil {
+			b.WriteString("\n")
+		}
+
+		for _, n := range group.nodes {
+			orgTask(b, n, level, 1, opts)
+		}
+	}
+}
+
+func orgTask(b *strings.Builder, n *node, level int, depth int, opts *Opts) {
+	keyword := "TODO"
+	if n.task.IsCompleted {
+		keyword = "DONE"
+	}
+
+	heading := strings.Repeat("*", level) + " " + keyword
+	if priority, ok := orgPriorities[n.task.Priority]; ok {
+		heading += " [#" + priority + "]"
+	}
+	heading += " " + inline(n.task.Content)
+
+	if len(n.task.Labels) != 0 {
+		tags := make([]string, 0, len(n.task.Labels))
+		for _, label := range n.task.Labels {
+			tags = append(tags, orgTag(label))
+		}
+		heading += " :" + strings.Join(tags, ":") + ":"
+	}
+
+	b.WriteString(heading + "\n")
+
+	indent := strings.Repeat(" ", level+1)
+
+	if timestamp, recurrence := orgDue(n.task.Due, opts); timestamp != "" {
+		b.WriteString(indent + "DEADLINE: " + timestamp + "\n")
+		if recurrence != "" {
+			b.WriteString(indent + ":PROPERTIES:\n")
+			b.WriteString(indent + ":RECURRENCE: " + recurrence + "\n")
+			b.WriteString(indent + ":END:\n")
+		}
+	}
+
+	if opts.IncludeDescriptions && strings.TrimSpace(n.task.Description) != "" {
+		for _, line := range lines(n.task.Description) {
+			// A line starting with stars would become a heading.
+			if strings.HasPrefix(line, "*") {
+				line = "," + line
+			}
+			writeLine(b, indent, line)
+		}
+	}
+
+	orgComments(b, indent, n.comments, opts)
+
+	if opts.MaxDepth == 0 || depth < opts.MaxDepth {
+		for _, child := range n.children {
+			orgTask(b, child, level+1, depth+1, opts)
+		}
+	}
+}
+
+// orgComments lists every comment, prefixed by the time it was posted.
+func orgComments(b *strings.Builder, indent string, comments []todoist.Comment, opts *Opts) {
+	for _, comment := range comments {
+		content := comment.Content
+		if name, url := attachment(comment); url != "" {
+			content = strings.TrimSpace(content + "\n[[" + url + "][" + name + "]]")
+		}
+
+		for i, line := range lines(content) {
+			prefix := indent + "  "
+			if i == 0 {
+				prefix, line = indent+"- ", postedAt(comment, opts)+": "+line
+			}
+			writeLine(b, prefix, line)
+		}
+	}
+}
+
+// orgDue formats the due as an active timestamp. Recurring dues get a repeater when one fits,
+// otherwise their due string is returned as recurrence.
+func orgDue(due *todoist.Due, opts *Opts) (timestamp string, recurrence string) {
+	if due == nil {
+		return
+	}
+
+	var t time.Time
+	var err error
+	if due.IsAllDay() {
+		t, err = time.Parse(todoist.DateLayout, due.Date)
+	} else {
+		loc := opts.Location
+		if loc == nil {
+			loc = time.UTC
+		}
+		t, err = due.Time(loc)
+	}
+
+	if err != nil {
+		return "", due.String
+	}
+
+	timestamp = t.Format("2006-01-02 Mon")
+	if !due.IsAllDay() {
+		timestamp += t.Format(" 15:04")
+	}
+
+	if due.IsRecurring {
+		if r, err := due.Recurrence(); err == nil && orgRepeater(r) != "" {
+			timestamp += " " + orgRepeater(r)
+		} else {
+			recurrence = due.String
+		}
+	}
+
+	return "<" + timestamp + ">", recurrence
+}
+
+// orgRepeater returns the repeater of recurrences that repeat on the same day of their period, like
+// "+1w" or ".+2d" when counted from completion. Other recurrences have none.
+func orgRepeater(r *todoist.Recurrence) string {
+	if len(r.NthWeekdays) != 0 || r.NthWorkday != 0 || r.End != "" {
+		return ""
+	}
+
+	if len(r.Weekdays) > 1 || len(r.Weekdays) == 1 && r.Frequency != todoist.Weekly {
+		return ""
+	}
+
+	if len(r.MonthDays) > 1 || len(r.MonthDays) == 1 && (r.Frequency == todoist.Hourly || r.Frequency == todoist.Daily || r.Frequency == todoist.Weekly || r.MonthDays[0] < 0) {
+		return ""
+	}
+
+	units := map[todoist.Frequency]string{
+		todoist.Hourly:  "h",
+		todoist.Daily:   "d",
+		todoist.Weekly:  "w",
+		todoist.Monthly: "m",
+		todoist.Yearly:  "y",
+	}
+
+	unit, ok := units[r.Frequency]
+	if !ok {
+		return ""
+	}
+
+	interval := r.Interval
+	if interval < 1 {
+		interval = 1
+	}
+
+	repeater := "+"
+	if r.FromCompletion {
+		repeater = ".+"
+	}
+
+	return repeater + strconv.Itoa(interval) + unit
+}
+
+// orgTag replaces the characters tags can't have.
+func orgTag(label string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("_@#%", r) {
+			return r
+		}
+		return '_'
+	}, label)
+}
@@ -0,0 +1,226 @@
+// Package outline renders projects as Markdown checklists or Org-mode headings and imports Markdown checklists.
+package outline
+
+import (
+	"context"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	todoist "github.com/temoon/todoist-api"
+	"github.com/temoon/todoist-api/internal/ordering"
+)
+
+type Format int
+
+const (
+	Markdown Format = iota
+	Org
+)
+
+type Opts struct {
+	Format Format
+	// IncludeCompleted keeps completed tasks, when the caller has them. GetTasks only returns active ones.
+	IncludeCompleted    bool
+	IncludeDescriptions bool
+	// MaxDepth limits the levels of subtasks, 1 is top level tasks only. Zero means no limit.
+	MaxDepth int
+	// Location of fixed due datetimes, UTC by default, so that the output doesn't depend on the machine.
+	Location *time.Location
+}
+
+// Project is everything rendered for a project. Comments are matched to the project and its tasks by id.
+type Project struct {
+	Project  todoist.Project
+	Sections []todoist.Section
+	Tasks    []todoist.Task
+	Comments []todoist.Comment
+}
+
+type node struct {
+	task     todoist.Task
+	comments []todoist.Comment
+	children []*node
+}
+
+type group struct {
+	section *todoist.Section
+	nodes   []*node
+}
+
+// Fetch reads a project with its sections, active tasks and comments.
+func Fetch(ctx context.Context, t *todoist.Todoist, projectId string) (project *Project, err error) {
+	project = new(Project)
+
+	var p *todoist.Project
+	if p, err = t.GetProject(ctx, projectId); err != nil {
+		return nil, err
+	}
+	project.Project = *p
+
+	if project.Sections, err = t.GetSections(ctx, todoist.MakeGetSectionsParams().WithProjectId(projectId)); err != nil {
+		return nil, err
+	}
+
+	if project.Tasks, err = t.GetTasks(ctx, todoist.MakeGetTasksParams().WithProjectId(projectId)); err != nil {
+		return nil, err
+	}
+
+	if p.CommentCount != 0 {
+		if project.Comments, err = t.GetComments(ctx, todoist.MakeGetCommentsParams().WithProjectId(projectId)); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, task := range project.Tasks {
+		if task.CommentCount != 0 {
+			var comments []todoist.Comment
+			if comments, err = t.GetComments(ctx, todoist.MakeGetCommentsParams().WithTaskId(task.Id)); err != nil {
+				return nil, err
+			}
+			project.Comments = append(project.Comments, comments...)
+		}
+	}
+
+	return
+}
+
+// Export fetches a project and renders it.
+func Export(ctx context.Context, t *todoist.Todoist, projectId string, w io.Writer, opts *Opts) (err error) {
+	var project *Project
+	if project, err = Fetch(ctx, t, projectId); err != nil {
+		return
+	}
+
+	return Render(w, project, opts)
+}
+
+// Render writes the project. Tasks outside of sections come first, then the sections in their order.
+// Siblings are sorted by order and id, and comments by time, so the same data always gives the same output.
+func Render(w io.Writer, project *Project, opts *Opts) error {
+	if opts == nil {
+		opts = &Opts{}
+	}
+
+	var b strings.Builder
+	switch opts.Format {
+	case Org:
+		renderOrg(&b, project, opts)
+	default:
+		renderMarkdown(&b, project, opts)
+	}
+
+	_, err := io.WriteString(w, b.String())
+
+	return err
+}
+
+// groups builds the task trees of a project, grouped by section.
+func groups(project *Project, opts *Opts) (projectComments []todoist.Comment, groups []group) {
+	comments := make(map[string][]todoist.Comment)
+	for _, comment := range project.Comments {
+		if comment.TaskId != "" {
+			comments[comment.TaskId] = append(comments[comment.TaskId], comment)
+		} else if comment.ProjectId == project.Project.Id {
+			projectComments = append(projectComments, comment)
+		}
+	}
+
+	for _, list := range comments {
+		sortComments(list)
+	}
+	sortComments(projectComments)
+
+	nodes := make(map[string]*node)
+	for _, task := range project.Tasks {
+		if task.ProjectId == project.Project.Id && (opts.IncludeCompleted || !task.IsCompleted) {
+			nodes[task.Id] = &node{task: task, comments: comments[task.Id]}
+		}
+	}
+
+	sections := make(map[string]bool)
+	for _, section := range project.Sections {
+		sections[section.Id] = true
+	}
+
+	bySection := make(map[string][]*node)
+	for _, n := range nodes {
+		if parent, ok := nodes[n.task.ParentId]; ok {
+			parent.children = append(parent.children, n)
+		} else if sections[n.task.SectionId] {
+			bySection[n.task.SectionId] = append(bySection[n.task.SectionId], n)
+		} else {
+			bySection[""] = append(bySection[""], n)
+		}
+	}
+
+	for _, n := range nodes {
+		sortNodes(n.children)
+	}
+
+	sortNodes(bySection[""])
+	groups = append(groups, group{nodes: bySection[""]})
+
+	sorted := append([]todoist.Section(nil), project.Sections...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return ordering.LessOrder(sorted[i].Order, sorted[i].Id, sorted[j].Order, sorted[j].Id)
+	})
+
+	for i := range sorted {
+		if sorted[i].ProjectId != project.Project.Id {
+			continue
+		}
+
+		sortNodes(bySection[sorted[i].Id])
+		groups = append(groups, group{section: &sorted[i], nodes: bySection[sorted[i].Id]})
+	}
+
+	return
+}
+
+// dueText formats the due as its recurring string, a date, or a date and a time.
+func dueText(due *todoist.Due, opts *Opts) string {
+	if due == nil {
+		return ""
+	}
+
+	if due.IsRecurring && due.String != "" {
+		return due.String
+	}
+
+	if due.IsAllDay() {
+		return due.Date
+	}
+
+	loc := opts.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	t, err := due.Time(loc)
+	if err != nil {
+		return due.String
+	}
+
+	return t.Format("2006-01-02 15:04")
+}
+
+func sortNodes(nodes []*node) {
+	sort.SliceStable(nodes, func(i, j int) bool {
+		return ordering.LessOrder(nodes[i].task.Order, nodes[i].task.Id, nodes[j].task.Order, nodes[j].task.Id)
+	})
+}
+
+func sortComments(comments []todoist.Comment) {
+	sort.SliceStable(comments, func(i, j int) bool {
+		if comments[i].PostedAt != comments[j].PostedAt {
+			return comments[i].PostedAt < comments[j].PostedAt
+		}
+		return ordering.LessId(comments[i].Id, comments[j].Id)
+	})
+}
+
+func lines(text string) []string {
+	return strings.Split(strings.TrimRight(strings.ReplaceAll(text, "\r\n", "\n"), "\n"), "\n")
+}
@@ -0,0 +1,252 @@
+package outline_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	todoist "github.com/temoon/todoist-api"
+	"github.com/temoon/todoist-api/outline"
+	"github.com/temoon/todoist-api/todoisttest"
+)
+
+func fixture() *outline.Project {
+	return &outline.Project{
+		Project: todoist.Project{Id: "p", Name: "Launch"},
+		Sections: []todoist.Section{
+			{Id: "s2", ProjectId: "p", Name: "Later", Order: 2},
+			{Id: "s1", ProjectId: "p", Name: "Now", Order: 1},
+		},
+		Tasks: []todoist.Task{
+			{Id: "t2", ProjectId: "p", Content: "Write report", Priority: 4, Labels: []string{"work", "q1"}, Order: 2,
+				Description: "Scope first\nthen numbers", Due: &todoist.Due{Date: "2024-03-15", Datetime: "2024-03-15T09:00:00Z", Timezone: "UTC"}},
+			{Id: "t1", ProjectId: "p", Content: "Plan", Priority: 1, Order: 1, Due: &todoist.Due{Date: "2024-03-14"}},
+			{Id: "t3", ProjectId: "p", ParentId: "t2", Content: "Collect numbers", Priority: 2, Order: 1},
+			{Id: "t4", ProjectId: "p", ParentId: "t3", Content: "Ask finance", Priority: 1, Order: 1},
+			{Id: "t5", ProjectId: "p", SectionId: "s1", Content: "Standup", Priority: 1, Order: 1,
+				Due: &todoist.Due{String: "every mon", Date: "2024-03-18", IsRecurring: true}},
+			{Id: "t6", ProjectId: "p", SectionId: "s1", Content: "Old", Priority: 1, Order: 2, IsCompleted: true},
+			{Id: "t7", ProjectId: "other", Content: "Not mine", Priority: 1},
+		},
+		Comments: []todoist.Comment{
+			{Id: "c2", TaskId: "t2", Content: "Use the template", PostedAt: "2024-03-13T10:00:00Z"},
+			{Id: "c1", ProjectId: "p", Content: "Goals", PostedAt: "2024-03-12T08:30:00Z"},
+		},
+	}
+}
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name string
+		opts *outline.Opts
+		want string
+	}{
+		{
+			name: "markdown",
+			want: `# Launch
+
+> 2024-03-12 08:30: Goals
+
+- [ ] Plan due:2024-03-14
+- [ ] Write report p1 @work @q1 due:"2024-03-15 09:00"
+  > 2024-03-13 10:00: Use the template
+  - [ ] Collect numbers p3
+    - [ ] Ask finance
+
+## Now
+
+- [ ] Standup due:"every mon"
+
+## Later
+`,
+		},
+		{
+			name: "markdown with descriptions, completed tasks and a depth limit",
+			opts: &outline.Opts{IncludeDescriptions: true, IncludeCompleted: true, MaxDepth: 2},
+			want: `# Launch
+
+> 2024-03-12 08:30: Goals
+
+- [ ] Plan due:2024-03-14
+- [ ] Write report p1 @work @q1 due:"2024-03-15 09:00"
+  Scope first
+  then numbers
+  > 2024-03-13 10:00: Use the template
+  - [ ] Collect numbers p3
+
+## Now
+
+- [ ] Standup due:"every mon"
+- [x] Old
+
+## Later
+`,
+		},
+		{
+			name: "org",
+			opts: &outline.Opts{Format: outline.Org, IncludeDescriptions: true},
+			want: `#+TITLE: Launch
+
+- 2024-03-12 08:30: Goals
+
+* TODO Plan
+  DEADLINE: <2024-03-14 Thu>
+* TODO [#A] Write report :work:q1:
+  DEADLINE: <2024-03-15 Fri 09:00>
+  Scope first
+  then numbers
+  - 2024-03-13 10:00: Use the template
+** TODO [#C] Collect numbers
+*** TODO Ask finance
+
+* Now
+** TODO Standup
+   DEADLINE: <2024-03-18 Mon +1w>
+
+* Later
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b strings.Builder
+			if err := outline.Render(&b, fixture(), tt.opts); err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+			if b.String() != tt.want {
+				t.Errorf("Render() =\n%s\nwant\n%s", b.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestParseChecklist(t *testing.T) {
+	tests := []struct {
+		name    string
+		doc     string
+		want    *outline.Checklist
+		wantErr error
+	}{
+		{
+			name: "items and tokens",
+			doc: "- [ ] Buy milk p1 @errand due:tomorrow\n" +
+				"* [x] Pay rent\n" +
+				"+ [ ] Call \"mom\" due:\"next friday 5pm\" @family p3\n",
+			want: &outline.Checklist{Items: []outline.Item{
+				{Content: "Buy milk", Priority: 4, Labels: []string{"errand"}, Due: "tomorrow"},
+				{Content: "Pay rent", Priority: 1, Checked: true},
+				{Content: "Call \"mom\"", Priority: 2, Labels: []string{"family"}, Due: "next friday 5pm"},
+			}},
+		},
+		{
+			name: "nesting and descriptions",
+			doc: "- [ ] Report\n" +
+				"  First line\n" +
+				"\n" +
+				"      indented more\n" +
+				"  - [ ] Numbers\n" +
+				"\t- [ ] Finance\n" +
+				"- [ ] Next\n" +
+				"Not an item\n" +
+				"- plain list entry\n",
+			want: &outline.Checklist{Items: []outline.Item{
+				{Content: "Report", Priority: 1, Description: "First line\n\n    indented more", Children: []outline.Item{
+					{Content: "Numbers", Priority: 1, Children: []outline.Item{{Content: "Finance", Priority: 1}}},
+				}},
+				{Content: "Next", Priority: 1},
+			}},
+		},
+		{
+			name: "title and sections",
+			doc: "# Launch\n" +
+				"\n" +
+				"- [ ] Plan\n" +
+				"\n" +
+				"## Now\n" +
+				"- [ ] Standup\n" +
+				"### Later ##\n",
+			want: &outline.Checklist{
+				Title: "Launch",
+				Items: []outline.Item{{Content: "Plan", Priority: 1}},
+				Sections: []outline.Section{
+					{Name: "Now", Items: []outline.Item{{Content: "Standup", Priority: 1}}},
+					{Name: "Later"},
+				},
+			},
+		},
+		{
+			name: "single heading is a section",
+			doc:  "# Groceries\n- [ ] Milk\n",
+			want: &outline.Checklist{Sections: []outline.Section{{Name: "Groceries", Items: []outline.Item{{Content: "Milk", Priority: 1}}}}},
+		},
+		{
+			name: "not a heading or an item",
+			doc:  "#hashtag\n-[ ] Milk\n- [ ]Bread\n",
+			want: &outline.Checklist{},
+		},
+		{name: "only tokens", doc: "- [ ] p1 @errand\n", wantErr: outline.ErrEmptyContent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := outline.ParseChecklist(strings.NewReader(tt.doc))
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("ParseChecklist() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseChecklist() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseChecklist() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestImportRoundTrip(t *testing.T) {
+	srv := todoisttest.NewServer()
+	defer srv.Close()
+
+	client := srv.Todoist()
+	ctx := context.Background()
+
+	// Comments are not imported, and the fake reads due strings that are dates but not datetimes.
+	source := fixture()
+	source.Comments = nil
+	source.Tasks[0].Due = &todoist.Due{Date: "2024-03-15"}
+	opts := &outline.Opts{IncludeDescriptions: true}
+
+	var rendered bytes.Buffer
+	if err := outline.Render(&rendered, source, opts); err != nil {
+		t.Fatal(err)
+	}
+
+	project, err := client.AddProject(ctx, todoist.MakeAddProjectParams().WithName("Launch"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := outline.Import(ctx, client, project.Id, bytes.NewReader(rendered.Bytes()), nil)
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+	if len(result.Sections) != 2 || len(result.Tasks) != 5 {
+		t.Errorf("Import() = %d sections and %d tasks, want 2 and 5", len(result.Sections), len(result.Tasks))
+	}
+
+	var again strings.Builder
+	if err = outline.Export(ctx, client, project.Id, &again, opts); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	if again.String() != rendered.String() {
+		t.Errorf("Export() after Import() =\n%s\nwant\n%s", again.String(), rendered.String())
+	}
+}
@@ -1,7 +1,6 @@
 package todoist
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"net/http"
@@ -34,7 +33,7 @@ type Collaborator struct {
 
 func (t *Todoist) GetProjects(ctx context.Context) (projects []Project, err error) {
 	projects = make([]Project, 0)
-	err = t.request(ctx, http.MethodGet, ProjectsEndpoint, nil, nil, &projects)
+	err = t.request(ctx, "GetProjects", http.MethodGet, ProjectsEndpoint, nil, nil, &projects)
 
 	return
 }
@@ -87,7 +86,7 @@ func (t *Todoist) AddProject(ctx context.Context, params *AddProjectParams) (pro
 	}
 
 	project = new(Project)
-	err = t.request(ctx, http.MethodPost, ProjectsEndpoint, nil, bytes.NewBuffer(payload), project)
+	err = t.request(ctx, "AddProject", http.MethodPost, ProjectsEndpoint, nil, payload, project)
 
 	return
 }
@@ -99,7 +98,7 @@ func (t *Todoist) AddProject(ctx context.Context, params *AddProjectParams) (pro
 func (t *Todoist) GetProject(ctx context.Context, projectId string) (project *Project, err error) {
 	project = new(Project)
 	encodedProjectId := url.PathEscape(projectId)
-	err = t.request(ctx, http.MethodGet, ProjectsEndpoint+"/"+encodedProjectId, nil, nil, project)
+	err = t.request(ctx, "GetProject", http.MethodGet, ProjectsEndpoint+"/"+encodedProjectId, nil, nil, project)
 	return
 }
 
@@ -142,7 +141,7 @@ func (t *Todoist) UpdateProject(ctx context.Context, projectId string, params *U
 		return
 	}
 	encodedProjectId := url.PathEscape(projectId)
-	return t.request(ctx, http.MethodPost, ProjectsEndpoint+"/"+encodedProjectId, nil, bytes.NewBuffer(payload), nil)
+	return t.request(ctx, "UpdateProject", http.MethodPost, ProjectsEndpoint+"/"+encodedProjectId, nil, payload, nil)
 }
 
 // endregion
@@ -151,7 +150,7 @@ func (t *Todoist) UpdateProject(ctx context.Context, projectId string, params *U
 
 func (t *Todoist) DeleteProject(ctx context.Context, projectId string) (err error) {
 	encodedProjectId := url.PathEscape(projectId)
-	return t.request(ctx, http.MethodDelete, ProjectsEndpoint+"/"+encodedProjectId, nil, nil, nil)
+	return t.request(ctx, "DeleteProject", http.MethodDelete, ProjectsEndpoint+"/"+encodedProjectId, nil, nil, nil)
 }
 
 // endregion
@@ -161,7 +160,7 @@ func (t *Todoist) DeleteProject(ctx context.Context, projectId string) (err erro
 func (t *Todoist) GetCollaborators(ctx context.Context, projectId string) (collaborators []Collaborator, err error) {
 	collaborators = make([]Collaborator, 0)
 	encodedProjectId := url.PathEscape(projectId)
-	err = t.request(ctx, http.MethodGet, ProjectsEndpoint+"/"+encodedProjectId+"/collaborators", nil, nil, &collaborators)
+	err = t.request(ctx, "GetCollaborators", http.MethodGet, ProjectsEndpoint+"/"+encodedProjectId+"/collaborators", nil, nil, &collaborators)
 
 	return
 }
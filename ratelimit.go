@@ -0,0 +1,126 @@
+package todoist
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+var ErrRateLimitWait = errors.New("rate limit wait exceeds context deadline")
+
+type RateLimit struct {
+	Requests int
+	Window   time.Duration
+	Burst    int
+}
+
+//goland:noinspection GoUnusedExportedFunction
+func DefaultRateLimit() *RateLimit {
+	return &RateLimit{
+		Requests: 450,
+		Window:   15 * time.Minute,
+	}
+}
+
+type RateLimiterStats struct {
+	Requests  int64
+	Throttled int64
+	Canceled  int64
+	Waited    time.Duration
+}
+
+// RateLimiter is a token bucket. A single limiter may be shared by several Todoist
+// instances that use the same token, since Todoist counts quotas per user.
+type RateLimiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+	stats  RateLimiterStats
+}
+
+// NewRateLimiter creates a limiter that starts with a full bucket. Requests and Window that are not
+// positive fall back to those of DefaultRateLimit, and Burst to Requests.
+func NewRateLimiter(limit RateLimit) *RateLimiter {
+	if limit.Requests <= 0 {
+		limit.Requests = DefaultRateLimit().Requests
+	}
+
+	if limit.Window <= 0 {
+		limit.Window = DefaultRateLimit().Window
+	}
+
+	burst := limit.Burst
+	if burst <= 0 {
+		burst = limit.Requests
+	}
+
+	return &RateLimiter{
+		rate:   float64(limit.Requests) / limit.Window.Seconds(),
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+func (l *RateLimiter) Wait(ctx context.Context) (err error) {
+	wait := l.reserve(time.Now())
+	if wait <= 0 {
+		return
+	}
+
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < wait {
+		l.cancel()
+		return ErrRateLimitWait
+	}
+
+	if err = sleep(ctx, wait); err != nil {
+		l.cancel()
+		return
+	}
+
+	l.mu.Lock()
+	l.stats.Waited += wait
+	l.mu.Unlock()
+
+	return
+}
+
+func (l *RateLimiter) Stats() RateLimiterStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.stats
+}
+
+func (l *RateLimiter) reserve(now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if elapsed := now.Sub(l.last).Seconds(); elapsed > 0 {
+		l.tokens += elapsed * l.rate
+		if l.tokens > l.burst {
+			l.tokens = l.burst
+		}
+		l.last = now
+	}
+
+	l.stats.Requests++
+	l.tokens--
+	if l.tokens >= 0 {
+		return 0
+	}
+
+	l.stats.Throttled++
+	return time.Duration(-l.tokens / l.rate * float64(time.Second))
+}
+
+func (l *RateLimiter) cancel() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.tokens++
+	l.stats.Canceled++
+}
@@ -0,0 +1,137 @@
+package todoist_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	todoist "github.com/temoon/todoist-api"
+)
+
+func TestRateLimiterBurst(t *testing.T) {
+	l := todoist.NewRateLimiter(todoist.RateLimit{Requests: 1, Window: time.Hour, Burst: 3})
+
+	for i := 0; i < 3; i++ {
+		if err := l.Wait(context.Background()); err != nil {
+			t.Fatalf("Wait() #%d error = %v", i+1, err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if err := l.Wait(ctx); !errors.Is(err, todoist.ErrRateLimitWait) {
+		t.Fatalf("Wait() after the burst error = %v, want ErrRateLimitWait", err)
+	}
+
+	want := todoist.RateLimiterStats{Requests: 4, Throttled: 1, Canceled: 1}
+	if got := l.Stats(); got != want {
+		t.Errorf("Stats() = %+v, want %+v", got, want)
+	}
+}
+
+func TestRateLimiterDefaults(t *testing.T) {
+	tests := []struct {
+		name  string
+		limit todoist.RateLimit
+	}{
+		{name: "zero", limit: todoist.RateLimit{}},
+		{name: "negative requests", limit: todoist.RateLimit{Requests: -1, Window: time.Hour}},
+		{name: "negative window", limit: todoist.RateLimit{Requests: 10, Window: -time.Second}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := todoist.NewRateLimiter(tt.limit)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+
+			for i := 0; i < 10; i++ {
+				if err := l.Wait(ctx); err != nil {
+					t.Fatalf("Wait() #%d error = %v", i+1, err)
+				}
+			}
+
+			if got := l.Stats(); got.Throttled != 0 {
+				t.Errorf("Stats() = %+v, want no throttled requests", got)
+			}
+		})
+	}
+}
+
+func TestRateLimiterThrottles(t *testing.T) {
+	l := todoist.NewRateLimiter(todoist.RateLimit{Requests: 20, Window: time.Second, Burst: 1})
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := l.Wait(context.Background()); err != nil {
+			t.Fatalf("Wait() #%d error = %v", i+1, err)
+		}
+	}
+
+	// A token comes every 50ms, and the first one is in the bucket already.
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("three requests took %v, want at least 100ms", elapsed)
+	}
+
+	stats := l.Stats()
+	if stats.Requests != 3 || stats.Throttled != 2 || stats.Canceled != 0 {
+		t.Errorf("Stats() = %+v, want 3 requests with 2 throttled", stats)
+	}
+	if stats.Waited < 90*time.Millisecond {
+		t.Errorf("Stats().Waited = %v, want at least 100ms", stats.Waited)
+	}
+}
+
+func TestRateLimiterCancel(t *testing.T) {
+	l := todoist.NewRateLimiter(todoist.RateLimit{Requests: 10, Window: time.Second, Burst: 1})
+
+	if err := l.Wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := l.Wait(canceled); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Wait() error = %v, want context.Canceled", err)
+	}
+
+	// The canceled wait gave its token back, so the next one waits for a single token, not two.
+	start := time.Now()
+	if err := l.Wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed > 180*time.Millisecond {
+		t.Errorf("Wait() after a canceled wait took %v, want about 100ms", elapsed)
+	}
+
+	if stats := l.Stats(); stats.Requests != 3 || stats.Canceled != 1 {
+		t.Errorf("Stats() = %+v, want 3 requests with 1 canceled", stats)
+	}
+}
+
+func TestRateLimiterShared(t *testing.T) {
+	s := newStub(t, body("application/json", `[]`))
+	limiter := todoist.NewRateLimiter(todoist.RateLimit{Requests: 1, Window: time.Hour})
+
+	first := todoist.New(&todoist.Opts{Token: "token", Client: s.client(), RateLimiter: limiter})
+	second := todoist.New(&todoist.Opts{Token: "token", Client: s.client(), RateLimiter: limiter})
+
+	if _, err := first.GetProjects(context.Background()); err != nil {
+		t.Fatalf("GetProjects() error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	if _, err := second.GetProjects(ctx); !errors.Is(err, todoist.ErrRateLimitWait) {
+		t.Fatalf("GetProjects() on the second client error = %v, want ErrRateLimitWait", err)
+	}
+
+	if got := s.count(); got != 1 {
+		t.Errorf("requests = %d, want 1", got)
+	}
+}
@@ -0,0 +1,736 @@
+package todoist
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const maxRecurrencePeriods = 100000
+
+type Frequency int
+
+const (
+	Hourly Frequency = iota + 1
+	Daily
+	Weekly
+	Monthly
+	Yearly
+)
+
+// NthWeekday is a weekday within a month, like the 3rd friday. Negative N counts from the end of the month.
+type NthWeekday struct {
+	N       int
+	Weekday time.Weekday
+}
+
+// Recurrence is a parsed recurring due string. Occurrences happen every Interval periods of Frequency,
+// on the listed weekdays or days of the month when there are any, and on the day of the anchor otherwise.
+// Negative MonthDays and NthWorkday count from the end of the month. Start and End are inclusive dates.
+type Recurrence struct {
+	Frequency      Frequency
+	Interval       int
+	Weekdays       []time.Weekday
+	MonthDays      []int
+	NthWeekdays    []NthWeekday
+	NthWorkday     int
+	Month          time.Month
+	Hour           int
+	Minute         int
+	HasTime        bool
+	FromCompletion bool
+	Start          string
+	End            string
+
+	// value and yearless let Next resolve dates without a year again from its own anchor.
+	value    string
+	yearless bool
+}
+
+type RecurrenceError struct {
+	String string
+	Near   string
+}
+
+func (e *RecurrenceError) Error() string {
+	if e.Near == "" {
+		return "unsupported recurrence " + strconv.Quote(e.String)
+	}
+
+	return "unsupported recurrence " + strconv.Quote(e.String) + " near " + strconv.Quote(e.Near)
+}
+
+var (
+	frequencyWords = map[string]Frequency{
+		"hour": Hourly, "hours": Hourly, "hourly": Hourly,
+		"day": Daily, "days": Daily, "daily": Daily,
+		"week": Weekly, "weeks": Weekly, "weekly": Weekly,
+		"month": Monthly, "months": Monthly, "monthly": Monthly,
+		"year": Yearly, "years": Yearly, "yearly": Yearly, "annually": Yearly,
+	}
+
+	weekdayWords = map[string]time.Weekday{
+		"mon": time.Monday, "monday": time.Monday,
+		"tue": time.Tuesday, "tues": time.Tuesday, "tuesday": time.Tuesday,
+		"wed": time.Wednesday, "wednesday": time.Wednesday,
+		"thu": time.Thursday, "thur": time.Thursday, "thurs": time.Thursday, "thursday": time.Thursday,
+		"fri": time.Friday, "friday": time.Friday,
+		"sat": time.Saturday, "saturday": time.Saturday,
+		"sun": time.Sunday, "sunday": time.Sunday,
+	}
+
+	monthWords = map[string]time.Month{
+		"jan": time.January, "january": time.January,
+		"feb": time.February, "february": time.February,
+		"mar": time.March, "march": time.March,
+		"apr": time.April, "april": time.April,
+		"may": time.May,
+		"jun": time.June, "june": time.June,
+		"jul": time.July, "july": time.July,
+		"aug": time.August, "august": time.August,
+		"sep": time.September, "sept": time.September, "september": time.September,
+		"oct": time.October, "october": time.October,
+		"nov": time.November, "november": time.November,
+		"dec": time.December, "december": time.December,
+	}
+
+	ordinalWords = map[string]int{
+		"first": 1, "second": 2, "third": 3, "fourth": 4, "fifth": 5, "last": -1,
+	}
+
+	// Times of day as Todoist defines them.
+	partOfDayWords = map[string]int{
+		"morning": 9, "afternoon": 12, "evening": 19, "night": 22,
+	}
+
+	workdays = []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}
+)
+
+// ParseRecurrence parses the English recurrence grammar of Todoist: "every day", "every other week",
+// "every 2 weeks on mon, fri", "every 1st and 15th", "every last workday", "every 3rd friday", "every jan 15",
+// "every! 3 days" (counted from completion), followed by optional "at 9am", "starting 2024-01-01"
+// and "ending jan 31 2025" clauses. Dates without a year are the next ones from today, see ParseRecurrenceAt.
+func ParseRecurrence(s string) (r *Recurrence, err error) {
+	return ParseRecurrenceAt(s, time.Now())
+}
+
+// ParseRecurrenceAt parses like ParseRecurrence. A starting date without a year is the next one on or after
+// the date of anchor, an ending date without a year the next one on or after the starting date, or the anchor.
+func ParseRecurrenceAt(s string, anchor time.Time) (r *Recurrence, err error) {
+	p := &recurrenceParser{
+		value:  s,
+		words:  strings.Fields(strings.ReplaceAll(strings.ToLower(s), ",", " , ")),
+		anchor: anchor.Format(DateLayout),
+	}
+
+	r = &Recurrence{Interval: 1, value: s}
+	if err = p.parse(r); err != nil {
+		return nil, err
+	}
+
+	return
+}
+
+// Next returns up to n occurrences after the given time. The rule is anchored at its start date
+// if it has one, and at after otherwise. Dates without a year are resolved from after.
+func (r *Recurrence) Next(after time.Time, n int) []time.Time {
+	if r.yearless {
+		if resolved, err := ParseRecurrenceAt(r.value, after); err == nil {
+			r = resolved
+		}
+	}
+
+	anchor := after
+	if r.Start != "" && !r.FromCompletion {
+		if start, err := time.ParseInLocation(DateLayout, r.Start, after.Location()); err == nil {
+			anchor = start
+		}
+	}
+
+	return r.occurrences(anchor, after, n)
+}
+
+// Recurrence parses the due string of a recurring due. Dates without a year are resolved from the due date.
+func (d *Due) Recurrence() (*Recurrence, error) {
+	if d == nil {
+		return nil, ErrNoDueDate
+	}
+
+	anchor, err := d.Time(time.UTC)
+	if err != nil {
+		anchor = time.Now()
+	}
+
+	return ParseRecurrenceAt(d.String, anchor)
+}
+
+// Next returns up to n occurrences of a recurring due after the given time. Rules are anchored
+// at the due date, the way Todoist reschedules them, and rules with "every!" at after, as the completion time.
+// Fixed datetimes recur at the same wall clock time in their own timezone.
+func (d *Due) Next(after time.Time, n int) (times []time.Time, err error) {
+	var r *Recurrence
+	if r, err = d.Recurrence(); err != nil {
+		return
+	}
+
+	var loc *time.Location
+	if loc, err = d.Location(); err != nil {
+		return
+	}
+	if loc == nil {
+		loc = after.Location()
+	}
+
+	anchor := after.In(loc)
+	if !r.FromCompletion {
+		if anchor, err = d.Time(loc); err != nil {
+			return
+		}
+	}
+
+	return r.occurrences(anchor, after, n), nil
+}
+
+func (r *Recurrence) occurrences(anchor time.Time, after time.Time, n int) (times []time.Time) {
+	interval := r.Interval
+	if interval < 1 {
+		interval = 1
+	}
+
+	for period := 0; len(times) < n && period < maxRecurrencePeriods; period += interval {
+		for _, t := range r.period(anchor, period) {
+			date := t.Format(DateLayout)
+			if r.End != "" && date > r.End {
+				return
+			}
+
+			if !t.After(after) || r.Start != "" && date < r.Start {
+				continue
+			}
+
+			if times = append(times, t); len(times) == n {
+				return
+			}
+		}
+	}
+
+	return
+}
+
+// period returns the occurrences in the k-th period after the one of the anchor, in chronological order.
+func (r *Recurrence) period(anchor time.Time, k int) (times []time.Time) {
+	loc := anchor.Location()
+	year, month, day := anchor.Date()
+
+	hour, minute, second := anchor.Clock()
+	if r.HasTime {
+		hour, minute, second = r.Hour, r.Minute, 0
+	}
+
+	at := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, hour, minute, second, 0, loc)
+	}
+
+	switch r.Frequency {
+	case Hourly:
+		return []time.Time{anchor.Add(time.Duration(k) * time.Hour)}
+	case Daily:
+		t := at(year, month, day+k)
+		if len(r.Weekdays) != 0 && !hasWeekday(r.Weekdays, t.Weekday()) {
+			return nil
+		}
+		return []time.Time{t}
+	case Weekly:
+		monday := day - daysSinceMonday(anchor.Weekday()) + 7*k
+		weekdays := r.Weekdays
+		if len(weekdays) == 0 {
+			weekdays = []time.Weekday{anchor.Weekday()}
+		}
+
+		offsets := make([]int, 0, len(weekdays))
+		for _, weekday := range weekdays {
+			offsets = append(offsets, daysSinceMonday(weekday))
+		}
+
+		for _, offset := range uniqueSorted(offsets) {
+			times = append(times, at(year, month, monday+offset))
+		}
+	case Monthly:
+		first := time.Date(year, month+time.Month(k), 1, 0, 0, 0, 0, loc)
+		for _, d := range r.monthDays(first.Year(), first.Month(), day) {
+			times = append(times, at(first.Year(), first.Month(), d))
+		}
+	case Yearly:
+		if r.Month != 0 {
+			month = r.Month
+		}
+
+		for _, d := range r.monthDays(year+k, month, day) {
+			times = append(times, at(year+k, month, d))
+		}
+	}
+
+	return
+}
+
+// monthDays resolves the days of the rule in a month. Rules without days fall on the day of the anchor,
+// or on the last day of shorter months.
+func (r *Recurrence) monthDays(year int, month time.Month, anchorDay int) []int {
+	last := daysIn(year, month)
+
+	var days []int
+	for _, day := range r.MonthDays {
+		if day < 0 {
+			day = last + 1 + day
+		}
+		if day >= 1 && day <= last {
+			days = append(days, day)
+		}
+	}
+
+	for _, nth := range r.NthWeekdays {
+		if day := nthWeekday(year, month, nth); day != 0 {
+			days = append(days, day)
+		}
+	}
+
+	if r.NthWorkday != 0 {
+		if day := nthWorkday(year, month, r.NthWorkday); day != 0 {
+			days = append(days, day)
+		}
+	}
+
+	if len(r.MonthDays) == 0 && len(r.NthWeekdays) == 0 && r.NthWorkday == 0 {
+		if anchorDay > last {
+			anchorDay = last
+		}
+		days = append(days, anchorDay)
+	}
+
+	return uniqueSorted(days)
+}
+
+// region Parser
+
+type recurrenceParser struct {
+	value  string
+	words  []string
+	pos    int
+	anchor string
+}
+
+func (p *recurrenceParser) peek(offset int) string {
+	if p.pos+offset < len(p.words) {
+		return p.words[p.pos+offset]
+	}
+
+	return ""
+}
+
+func (p *recurrenceParser) next() string {
+	word := p.peek(0)
+	p.pos++
+
+	return word
+}
+
+func (p *recurrenceParser) fail(word string) error {
+	return &RecurrenceError{String: p.value, Near: word}
+}
+
+func (p *recurrenceParser) parse(r *Recurrence) (err error) {
+	switch word := p.next(); word {
+	case "every", "ev", "every!", "ev!":
+		r.FromCompletion = strings.HasSuffix(word, "!")
+		if err = p.parseRule(r); err != nil {
+			return
+		}
+	case "hourly", "daily", "weekly", "monthly", "yearly", "annually":
+		r.Frequency = frequencyWords[word]
+	default:
+		return p.fail(word)
+	}
+
+	for p.pos < len(p.words) {
+		switch word := p.next(); word {
+		case "at":
+			err = p.parseTime(r)
+		case "starting", "from":
+			r.Start, err = p.parseDate(p.anchor, &r.yearless)
+		case "ending", "until":
+			from := p.anchor
+			if r.Start != "" {
+				from = r.Start
+			}
+			r.End, err = p.parseDate(from, &r.yearless)
+		default:
+			err = p.fail(word)
+		}
+
+		if err != nil {
+			return
+		}
+	}
+
+	return p.validate(r)
+}
+
+func (p *recurrenceParser) parseRule(r *Recurrence) (err error) {
+	if n, convErr := strconv.Atoi(p.peek(0)); convErr == nil && frequencyWords[p.peek(1)] != 0 {
+		if n < 1 {
+			return p.fail(p.peek(0))
+		}
+		r.Interval = n
+		p.pos++
+	} else if p.peek(0) == "other" {
+		r.Interval = 2
+		p.pos++
+	}
+
+	for {
+		word := p.peek(0)
+		switch {
+		case word == "" || word == "at" || word == "starting" || word == "from" || word == "ending" || word == "until":
+			return
+		case word == "," || word == "and" || word == "on" || word == "the" || word == "of":
+			p.pos++
+		case frequencyWords[word] != 0:
+			if r.Frequency != 0 {
+				return p.fail(word)
+			}
+			r.Frequency = frequencyWords[word]
+			p.pos++
+		case word == "workday" || word == "workdays" || word == "weekday" || word == "weekdays":
+			r.Weekdays = append(r.Weekdays, workdays...)
+			p.pos++
+		case word == "weekend" || word == "weekends":
+			r.Weekdays = append(r.Weekdays, time.Saturday, time.Sunday)
+			p.pos++
+		case partOfDayWords[word] != 0:
+			r.Hour, r.Minute, r.HasTime = partOfDayWords[word], 0, true
+			p.pos++
+		default:
+			if weekday, ok := weekdayWords[strings.TrimSuffix(word, "s")]; ok {
+				r.Weekdays = append(r.Weekdays, weekday)
+				p.pos++
+			} else if month, ok := monthWords[word]; ok {
+				p.pos++
+				if err = p.parseMonthDay(r, month, p.next()); err != nil {
+					return
+				}
+			} else if err = p.parseOrdinal(r); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// parseOrdinal handles "15th", "last day", "3rd friday", "last workday" and "15 jan".
+func (p *recurrenceParser) parseOrdinal(r *Recurrence) error {
+	word := p.next()
+
+	n, suffixed := ordinal(word)
+	if n == 0 {
+		return p.fail(word)
+	}
+
+	following := p.peek(0)
+	if weekday, ok := weekdayWords[following]; ok && suffixed {
+		r.NthWeekdays = append(r.NthWeekdays, NthWeekday{N: n, Weekday: weekday})
+		p.pos++
+		return nil
+	}
+
+	if (following == "workday" || following == "weekday") && suffixed {
+		if r.NthWorkday != 0 {
+			return p.fail(following)
+		}
+		r.NthWorkday = n
+		p.pos++
+		return nil
+	}
+
+	if month, ok := monthWords[following]; ok {
+		p.pos++
+		return p.parseMonthDay(r, month, word)
+	}
+
+	if following == "day" && suffixed {
+		p.pos++
+	} else if n < 0 {
+		return p.fail(word)
+	}
+
+	if n > 31 {
+		return p.fail(word)
+	}
+	r.MonthDays = append(r.MonthDays, n)
+
+	return nil
+}
+
+func (p *recurrenceParser) parseMonthDay(r *Recurrence, month time.Month, word string) error {
+	day, _ := ordinal(word)
+	if day < 1 || day > 31 || r.Month != 0 && r.Month != month {
+		return p.fail(word)
+	}
+
+	r.Month = month
+	r.MonthDays = append(r.MonthDays, day)
+	if r.Frequency == 0 {
+		r.Frequency = Yearly
+	}
+
+	return nil
+}
+
+func (p *recurrenceParser) parseTime(r *Recurrence) error {
+	var value string
+	for p.peek(0) != "" && p.peek(0) != "starting" && p.peek(0) != "from" && p.peek(0) != "ending" && p.peek(0) != "until" {
+		value += p.next()
+	}
+
+	hour, minute, ok := parseClock(value)
+	if !ok {
+		return p.fail(value)
+	}
+
+	r.Hour, r.Minute, r.HasTime = hour, minute, true
+
+	return nil
+}
+
+// parseDate reads a date. Without a year, it is the next one on or after from, which is a date
+// in DateLayout, and yearless is set.
+func (p *recurrenceParser) parseDate(from string, yearless *bool) (date string, err error) {
+	var words []string
+	for p.peek(0) != "" && p.peek(0) != "at" && p.peek(0) != "starting" && p.peek(0) != "from" && p.peek(0) != "ending" && p.peek(0) != "until" {
+		if word := p.next(); word != "," {
+			words = append(words, word)
+		}
+	}
+
+	if len(words) == 1 {
+		if t, err := time.Parse(DateLayout, words[0]); err == nil {
+			return t.Format(DateLayout), nil
+		}
+	}
+
+	if len(words) == 2 || len(words) == 3 {
+		month, ok := monthWords[words[0]]
+		dayWord := words[1]
+		if !ok {
+			month, ok = monthWords[words[1]]
+			dayWord = words[0]
+		}
+
+		day, _ := ordinal(dayWord)
+		if ok && len(words) == 2 && day >= 1 && day <= daysIn(2000, month) {
+			*yearless = true
+			return nextDate(from, month, day), nil
+		}
+
+		year := 0
+		if len(words) == 3 {
+			year, err = strconv.Atoi(words[2])
+		}
+
+		if ok && err == nil && day >= 1 && day <= daysIn(year, month) {
+			return time.Date(year, month, day, 0, 0, 0, 0, time.UTC).Format(DateLayout), nil
+		}
+	}
+
+	return "", p.fail(strings.Join(words, " "))
+}
+
+// nextDate returns the first date with the month and day on or after from, skipping years without February 29.
+func nextDate(from string, month time.Month, day int) string {
+	start, err := time.Parse(DateLayout, from)
+	if err != nil {
+		start = time.Now()
+	}
+
+	for year := start.Year(); ; year++ {
+		if day > daysIn(year, month) {
+			continue
+		}
+
+		if date := time.Date(year, month, day, 0, 0, 0, 0, time.UTC).Format(DateLayout); date >= from {
+			return date
+		}
+	}
+}
+
+func (p *recurrenceParser) validate(r *Recurrence) error {
+	hasMonthDays := len(r.MonthDays) != 0 || len(r.NthWeekdays) != 0 || r.NthWorkday != 0
+
+	if r.Frequency == 0 {
+		switch {
+		case hasMonthDays:
+			r.Frequency = Monthly
+		case len(r.Weekdays) != 0:
+			r.Frequency = Weekly
+		case r.HasTime:
+			r.Frequency = Daily
+		default:
+			return p.fail("")
+		}
+	}
+
+	switch r.Frequency {
+	case Hourly:
+		if r.HasTime || hasMonthDays || len(r.Weekdays) != 0 {
+			return p.fail("")
+		}
+	case Daily, Weekly:
+		if hasMonthDays || r.Month != 0 {
+			return p.fail("")
+		}
+	case Monthly:
+		if len(r.Weekdays) != 0 || r.Month != 0 {
+			return p.fail("")
+		}
+	case Yearly:
+		if len(r.Weekdays) != 0 || len(r.NthWeekdays) != 0 || r.NthWorkday != 0 {
+			return p.fail("")
+		}
+	}
+
+	if r.Start != "" && r.End != "" && r.End < r.Start {
+		return p.fail("")
+	}
+
+	return nil
+}
+
+// endregion
+
+// ordinal parses "3", "3rd", "third" and "last". The second result reports a word that is
+// an ordinal rather than a plain number.
+func ordinal(word string) (n int, suffixed bool) {
+	if n, ok := ordinalWords[word]; ok {
+		return n, true
+	}
+
+	for _, suffix := range []string{"st", "nd", "rd", "th"} {
+		if strings.HasSuffix(word, suffix) {
+			word, suffixed = strings.TrimSuffix(word, suffix), true
+			break
+		}
+	}
+
+	if n, err := strconv.Atoi(word); err == nil && n > 0 {
+		return n, suffixed
+	}
+
+	return 0, false
+}
+
+func parseClock(value string) (hour int, minute int, ok bool) {
+	switch value {
+	case "noon":
+		return 12, 0, true
+	case "midnight":
+		return 0, 0, true
+	}
+
+	am, pm := strings.HasSuffix(value, "am"), strings.HasSuffix(value, "pm")
+	if am || pm {
+		value = value[:len(value)-2]
+	}
+
+	hours, minutes := value, "0"
+	if i := strings.IndexByte(value, ':'); i != -1 {
+		hours, minutes = value[:i], value[i+1:]
+	}
+
+	var err error
+	if hour, err = strconv.Atoi(hours); err != nil {
+		return 0, 0, false
+	}
+	if minute, err = strconv.Atoi(minutes); err != nil || minute < 0 || minute > 59 {
+		return 0, 0, false
+	}
+
+	if am || pm {
+		if hour < 1 || hour > 12 {
+			return 0, 0, false
+		}
+		hour %= 12
+		if pm {
+			hour += 12
+		}
+	}
+
+	return hour, minute, hour >= 0 && hour <= 23
+}
+
+func nthWeekday(year int, month time.Month, nth NthWeekday) int {
+	last := daysIn(year, month)
+
+	var day int
+	if nth.N > 0 {
+		first := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC).Weekday()
+		day = 1 + (int(nth.Weekday)-int(first)+7)%7 + 7*(nth.N-1)
+	} else {
+		lastWeekday := time.Date(year, month, last, 0, 0, 0, 0, time.UTC).Weekday()
+		day = last - (int(lastWeekday)-int(nth.Weekday)+7)%7 + 7*(nth.N+1)
+	}
+
+	if day < 1 || day > last {
+		return 0
+	}
+
+	return day
+}
+
+func nthWorkday(year int, month time.Month, n int) int {
+	last := daysIn(year, month)
+
+	day, step := 1, 1
+	if n < 0 {
+		day, step, n = last, -1, -n
+	}
+
+	for ; day >= 1 && day <= last; day += step {
+		if hasWeekday(workdays, time.Date(year, month, day, 0, 0, 0, 0, time.UTC).Weekday()) {
+			if n--; n == 0 {
+				return day
+			}
+		}
+	}
+
+	return 0
+}
+
+func daysIn(year int, month time.Month) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+func daysSinceMonday(weekday time.Weekday) int {
+	return (int(weekday) + 6) % 7
+}
+
+func hasWeekday(weekdays []time.Weekday, weekday time.Weekday) bool {
+	for _, item := range weekdays {
+		if item == weekday {
+			return true
+		}
+	}
+
+	return false
+}
+
+func uniqueSorted(values []int) []int {
+	sort.Ints(values)
+
+	unique := values[:0]
+	for i, value := range values {
+		if i == 0 || value != values[i-1] {
+			unique = append(unique, value)
+		}
+	}
+
+	return unique
+}
@@ -0,0 +1,199 @@
+package todoist_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	todoist "github.com/temoon/todoist-api"
+)
+
+func formatTimes(times []time.Time, layout string) []string {
+	formatted := make([]string, 0, len(times))
+	for _, t := range times {
+		formatted = append(formatted, t.Format(layout))
+	}
+
+	return formatted
+}
+
+func TestParseRecurrence(t *testing.T) {
+	tests := []struct {
+		value     string
+		frequency todoist.Frequency
+		interval  int
+		fromDone  bool
+		start     string
+		end       string
+	}{
+		{value: "every day", frequency: todoist.Daily, interval: 1},
+		{value: "daily", frequency: todoist.Daily, interval: 1},
+		{value: "every other week", frequency: todoist.Weekly, interval: 2},
+		{value: "every 3 months", frequency: todoist.Monthly, interval: 3},
+		{value: "every mon, fri", frequency: todoist.Weekly, interval: 1},
+		{value: "every 1st and 15th", frequency: todoist.Monthly, interval: 1},
+		{value: "every last workday", frequency: todoist.Monthly, interval: 1},
+		{value: "every jan 15", frequency: todoist.Yearly, interval: 1},
+		{value: "every! 3 days", frequency: todoist.Daily, interval: 3, fromDone: true},
+		{value: "every day at 9am starting 2024-01-01 ending jan 31 2024", frequency: todoist.Daily, interval: 1, start: "2024-01-01", end: "2024-01-31"},
+		{value: "Every Week Starting 2024-03-01", frequency: todoist.Weekly, interval: 1, start: "2024-03-01"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			r, err := todoist.ParseRecurrence(tt.value)
+			if err != nil {
+				t.Fatalf("ParseRecurrence() error = %v", err)
+			}
+
+			if r.Frequency != tt.frequency || r.Interval != tt.interval || r.FromCompletion != tt.fromDone || r.Start != tt.start || r.End != tt.end {
+				t.Errorf("ParseRecurrence() = %+v", r)
+			}
+		})
+	}
+}
+
+func TestParseRecurrenceErrors(t *testing.T) {
+	tests := []string{
+		"",
+		"every",
+		"tomorrow",
+		"every day at 25:00",
+		"every day starting someday",
+		"every 2 weeks on mon and the moon",
+		"every day starting 2024-02-01 ending 2024-01-01",
+	}
+
+	for _, value := range tests {
+		t.Run(value, func(t *testing.T) {
+			_, err := todoist.ParseRecurrence(value)
+
+			var recurrenceErr *todoist.RecurrenceError
+			if !errors.As(err, &recurrenceErr) {
+				t.Fatalf("ParseRecurrence() error = %v, want *RecurrenceError", err)
+			}
+		})
+	}
+}
+
+func TestRecurrenceNext(t *testing.T) {
+	// Wednesday.
+	after := time.Date(2024, 3, 13, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		value string
+		want  []string
+	}{
+		{value: "every day", want: []string{"2024-03-14 12:00", "2024-03-15 12:00", "2024-03-16 12:00"}},
+		{value: "every day at 9am", want: []string{"2024-03-14 09:00", "2024-03-15 09:00", "2024-03-16 09:00"}},
+		{value: "every mon, fri", want: []string{"2024-03-15 12:00", "2024-03-18 12:00", "2024-03-22 12:00"}},
+		{value: "every 1st and 15th", want: []string{"2024-03-15 12:00", "2024-04-01 12:00", "2024-04-15 12:00"}},
+		{value: "every last day", want: []string{"2024-03-31 12:00", "2024-04-30 12:00", "2024-05-31 12:00"}},
+		{value: "every 3rd friday", want: []string{"2024-03-15 12:00", "2024-04-19 12:00", "2024-05-17 12:00"}},
+		{value: "every last workday", want: []string{"2024-03-29 12:00", "2024-04-30 12:00", "2024-05-31 12:00"}},
+		{value: "every jan 15", want: []string{"2025-01-15 12:00", "2026-01-15 12:00", "2027-01-15 12:00"}},
+		{value: "every day starting 2024-04-01 ending 2024-04-02", want: []string{"2024-04-01 00:00", "2024-04-02 00:00"}},
+		{value: "every 2 weeks starting 2024-03-01", want: []string{"2024-03-15 00:00", "2024-03-29 00:00", "2024-04-12 00:00"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			r, err := todoist.ParseRecurrence(tt.value)
+			if err != nil {
+				t.Fatalf("ParseRecurrence() error = %v", err)
+			}
+
+			if got := formatTimes(r.Next(after, 3), "2006-01-02 15:04"); !equalStrings(got, tt.want) {
+				t.Errorf("Next() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRecurrenceAtYearlessDates(t *testing.T) {
+	anchor := time.Date(2030, 11, 20, 8, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		value string
+		start string
+		end   string
+	}{
+		{value: "every day starting jan 15", start: "2031-01-15"},
+		{value: "every day starting dec 1", start: "2030-12-01"},
+		{value: "every day starting nov 20", start: "2030-11-20"},
+		{value: "every day ending jan 31", end: "2031-01-31"},
+		{value: "every day starting 2032-03-01 ending jan 31", start: "2032-03-01", end: "2033-01-31"},
+		{value: "every year starting feb 29", start: "2032-02-29"},
+		{value: "every day ending jan 31 2040", end: "2040-01-31"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			r, err := todoist.ParseRecurrenceAt(tt.value, anchor)
+			if err != nil {
+				t.Fatalf("ParseRecurrenceAt() error = %v", err)
+			}
+
+			if r.Start != tt.start || r.End != tt.end {
+				t.Errorf("Start, End = %q, %q, want %q, %q", r.Start, r.End, tt.start, tt.end)
+			}
+		})
+	}
+}
+
+func TestDueNext(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("no tz database:", err)
+	}
+
+	tests := []struct {
+		name   string
+		due    *todoist.Due
+		after  time.Time
+		layout string
+		want   []string
+	}{
+		{
+			name:   "anchored at the due date",
+			due:    &todoist.Due{String: "every 3 days", Date: "2024-03-12", IsRecurring: true},
+			after:  time.Date(2024, 3, 13, 12, 0, 0, 0, time.UTC),
+			layout: "2006-01-02 15:04",
+			want:   []string{"2024-03-15 00:00", "2024-03-18 00:00", "2024-03-21 00:00"},
+		},
+		{
+			name:   "from completion",
+			due:    &todoist.Due{String: "every! 3 days", Date: "2024-03-12", IsRecurring: true},
+			after:  time.Date(2024, 3, 13, 12, 0, 0, 0, time.UTC),
+			layout: "2006-01-02 15:04",
+			want:   []string{"2024-03-16 12:00", "2024-03-19 12:00", "2024-03-22 12:00"},
+		},
+		{
+			name:   "wall clock time across daylight saving time",
+			due:    &todoist.Due{String: "every day at 9am", Date: "2024-03-30", Datetime: "2024-03-30T08:00:00Z", Timezone: "Europe/Berlin", IsRecurring: true},
+			after:  time.Date(2024, 3, 30, 12, 0, 0, 0, berlin),
+			layout: "2006-01-02 15:04 -0700",
+			want:   []string{"2024-03-31 09:00 +0200", "2024-04-01 09:00 +0200", "2024-04-02 09:00 +0200"},
+		},
+		{
+			name:   "ending without a year follows the due date",
+			due:    &todoist.Due{String: "every day ending jan 2", Date: "2024-12-31", IsRecurring: true},
+			after:  time.Date(2024, 12, 31, 12, 0, 0, 0, time.UTC),
+			layout: "2006-01-02 15:04",
+			want:   []string{"2025-01-01 00:00", "2025-01-02 00:00"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			times, err := tt.due.Next(tt.after, 3)
+			if err != nil {
+				t.Fatalf("Next() error = %v", err)
+			}
+
+			if got := formatTimes(times, tt.layout); !equalStrings(got, tt.want) {
+				t.Errorf("Next() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
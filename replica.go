@@ -0,0 +1,535 @@
+package todoist
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/temoon/todoist-api/internal/ordering"
+)
+
+const replicaVersion = 1
+
+var ErrFilterNotSupported = errors.New("filter is not supported by replica")
+
+type replicaState struct {
+	Version   int                `json:"version"`
+	SyncToken string             `json:"sync_token"`
+	Projects  map[string]Project `json:"projects"`
+	Sections  map[string]Section `json:"sections"`
+	Tasks     map[string]Task    `json:"tasks"`
+	Labels    map[string]Label   `json:"labels"`
+	Comments  map[string]Comment `json:"comments"`
+}
+
+// Replica is an in-memory copy of the account kept up to date through incremental sync.
+// Read methods mirror the REST ones, so the replica can stand in for Todoist in read-only code.
+type Replica struct {
+	// UserId is "me" in filters.
+	UserId string
+
+	client *SyncClient
+
+	mu    sync.RWMutex
+	state *replicaState
+}
+
+//goland:noinspection GoUnusedExportedFunction
+func NewReplica(t *Todoist) *Replica {
+	return &Replica{
+		client: NewSyncClient(t, AllResources),
+		state:  newReplicaState(),
+	}
+}
+
+func newReplicaState() *replicaState {
+	return &replicaState{
+		Version:   replicaVersion,
+		SyncToken: FullSyncToken,
+		Projects:  make(map[string]Project),
+		Sections:  make(map[string]Section),
+		Tasks:     make(map[string]Task),
+		Labels:    make(map[string]Label),
+		Comments:  make(map[string]Comment),
+	}
+}
+
+func (r *Replica) SyncToken() string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.state.SyncToken
+}
+
+func (r *Replica) Refresh(ctx context.Context) (res *SyncResponse, err error) {
+	if res, err = r.client.Fetch(ctx); err != nil {
+		return
+	}
+
+	r.Apply(res)
+
+	return
+}
+
+// Apply merges a sync response into the replica. Readers see either the old or the new state.
+func (r *Replica) Apply(res *SyncResponse) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	state := newReplicaState()
+	if !res.FullSync {
+		state = r.state.clone()
+	}
+
+	for _, project := range res.Projects {
+		if project.IsDeleted || project.IsArchived {
+			state.removeProject(project.Id)
+		} else {
+			state.Projects[project.Id] = project.Project
+		}
+	}
+
+	for _, section := range res.Sections {
+		if section.IsDeleted || section.IsArchived {
+			state.removeSection(section.Id)
+		} else {
+			state.Sections[section.Id] = section.Section
+		}
+	}
+
+	for _, task := range res.Tasks {
+		if task.IsDeleted {
+			state.removeTask(task.Id)
+		} else {
+			state.Tasks[task.Id] = task.Task
+		}
+	}
+
+	for _, label := range res.Labels {
+		if label.IsDeleted {
+			delete(state.Labels, label.Id)
+		} else {
+			state.Labels[label.Id] = label.Label
+		}
+	}
+
+	for _, comment := range res.Comments {
+		if comment.IsDeleted {
+			delete(state.Comments, comment.Id)
+		} else {
+			state.Comments[comment.Id] = comment.Comment
+		}
+	}
+
+	if res.SyncToken != "" {
+		state.SyncToken = res.SyncToken
+	}
+
+	r.state = state
+}
+
+// region Persistence
+
+func (r *Replica) Save(path string) (err error) {
+	r.mu.RLock()
+	data, err := json.Marshal(r.state)
+	r.mu.RUnlock()
+
+	if err != nil {
+		return
+	}
+
+	return writeFile(path, data)
+}
+
+// Load restores a replica saved with Save. The next Refresh continues from the saved sync token.
+func (r *Replica) Load(path string) (err error) {
+	var data []byte
+	if data, err = os.ReadFile(path); err != nil {
+		return
+	}
+
+	state := newReplicaState()
+	if err = json.Unmarshal(data, state); err != nil {
+		return
+	}
+
+	if state.Version != replicaVersion {
+		return errors.New("unsupported replica version")
+	}
+
+	r.mu.Lock()
+	r.state = state
+	r.client.SetSyncToken(state.SyncToken)
+	r.mu.Unlock()
+
+	return
+}
+
+// endregion
+
+// region Projects
+
+func (r *Replica) GetProjects(_ context.Context) (projects []Project, err error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	projects = make([]Project, 0, len(r.state.Projects))
+	for _, project := range r.state.Projects {
+		project.CommentCount = r.state.projectCommentCount(project.Id)
+		projects = append(projects, project)
+	}
+
+	sort.Slice(projects, func(i, j int) bool { return ordering.LessId(projects[i].Id, projects[j].Id) })
+
+	return
+}
+
+func (r *Replica) GetProject(_ context.Context, projectId string) (project *Project, err error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	value, ok := r.state.Projects[projectId]
+	if !ok {
+		return nil, replicaNotFound(ProjectsEndpoint, projectId)
+	}
+
+	value.CommentCount = r.state.projectCommentCount(projectId)
+
+	return &value, nil
+}
+
+// endregion
+
+// region Sections
+
+func (r *Replica) GetSections(_ context.Context, params *GetSectionsParams) (sections []Section, err error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	projectId := (*params)["project_id"]
+
+	sections = make([]Section, 0)
+	for _, section := range r.state.Sections {
+		if projectId == "" || section.ProjectId == projectId {
+			sections = append(sections, section)
+		}
+	}
+
+	sort.Slice(sections, func(i, j int) bool { return ordering.LessId(sections[i].Id, sections[j].Id) })
+
+	return
+}
+
+func (r *Replica) GetSection(_ context.Context, sectionId string) (section *Section, err error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	value, ok := r.state.Sections[sectionId]
+	if !ok {
+		return nil, replicaNotFound(SectionsEndpoint, sectionId)
+	}
+
+	return &value, nil
+}
+
+// endregion
+
+// region Tasks
+
+// GetTasks evaluates filters locally with ParseFilter. Filters it can't parse fail with ErrFilterNotSupported.
+func (r *Replica) GetTasks(_ context.Context, params *GetTasksParams) (tasks []Task, err error) {
+	var filter *Filter
+	if value := (*params)["filter"]; value != "" {
+		if filter, err = ParseFilter(value); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrFilterNotSupported, err)
+		}
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var ids map[string]bool
+	if value := (*params)["ids"]; value != "" {
+		ids = make(map[string]bool)
+		for _, id := range strings.Split(value, ",") {
+			ids[id] = true
+		}
+	}
+
+	projectId := (*params)["project_id"]
+	sectionId := (*params)["section_id"]
+	label := (*params)["label"]
+
+	var scope *filterScope
+	if filter != nil {
+		scope = newFilterScope(r.state.filterEnv(r.UserId))
+	}
+
+	tasks = make([]Task, 0)
+	for _, task := range r.state.Tasks {
+		switch {
+		case task.IsCompleted:
+		case projectId != "" && task.ProjectId != projectId:
+		case sectionId != "" && task.SectionId != sectionId:
+		case label != "" && !containsFold(task.Labels, label):
+		case ids != nil && !ids[task.Id]:
+		case filter != nil && !filter.matches(&task, scope):
+		default:
+			task.CommentCount = r.state.taskCommentCount(task.Id)
+			tasks = append(tasks, task)
+		}
+	}
+
+	sort.Slice(tasks, func(i, j int) bool { return ordering.LessId(tasks[i].Id, tasks[j].Id) })
+
+	return
+}
+
+func (r *Replica) GetTask(_ context.Context, taskId string) (task *Task, err error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	value, ok := r.state.Tasks[taskId]
+	if !ok {
+		return nil, replicaNotFound(TasksEndpoint, taskId)
+	}
+
+	value.CommentCount = r.state.taskCommentCount(taskId)
+
+	return &value, nil
+}
+
+// endregion
+
+// region Labels
+
+func (r *Replica) GetLabels(_ context.Context) (labels []Label, err error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	labels = make([]Label, 0, len(r.state.Labels))
+	for _, label := range r.state.Labels {
+		labels = append(labels, label)
+	}
+
+	sort.Slice(labels, func(i, j int) bool { return ordering.LessId(labels[i].Id, labels[j].Id) })
+
+	return
+}
+
+func (r *Replica) GetLabel(_ context.Context, labelId string) (label *Label, err error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	value, ok := r.state.Labels[labelId]
+	if !ok {
+		return nil, replicaNotFound(LabelsEndpoint, labelId)
+	}
+
+	return &value, nil
+}
+
+// endregion
+
+// region Comments
+
+func (r *Replica) GetComments(_ context.Context, params *GetCommentsParams) (comments []Comment, err error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	projectId := (*params)["project_id"]
+	taskId := (*params)["task_id"]
+
+	comments = make([]Comment, 0)
+	for _, comment := range r.state.Comments {
+		if taskId != "" && comment.TaskId == taskId || taskId == "" && comment.TaskId == "" && comment.ProjectId == projectId {
+			comments = append(comments, comment)
+		}
+	}
+
+	sort.Slice(comments, func(i, j int) bool { return ordering.LessId(comments[i].Id, comments[j].Id) })
+
+	return
+}
+
+func (r *Replica) GetComment(_ context.Context, commentId string) (comment *Comment, err error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	value, ok := r.state.Comments[commentId]
+	if !ok {
+		return nil, replicaNotFound(CommentsEndpoint, commentId)
+	}
+
+	return &value, nil
+}
+
+// endregion
+
+func (s *replicaState) clone() *replicaState {
+	state := newReplicaState()
+	state.SyncToken = s.SyncToken
+
+	for id, project := range s.Projects {
+		state.Projects[id] = project
+	}
+
+	for id, section := range s.Sections {
+		state.Sections[id] = section
+	}
+
+	for id, task := range s.Tasks {
+		state.Tasks[id] = task
+	}
+
+	for id, label := range s.Labels {
+		state.Labels[id] = label
+	}
+
+	for id, comment := range s.Comments {
+		state.Comments[id] = comment
+	}
+
+	return state
+}
+
+func (s *replicaState) removeProject(projectId string) {
+	delete(s.Projects, projectId)
+
+	for id, project := range s.Projects {
+		if project.ParentId == projectId {
+			s.removeProject(id)
+		}
+	}
+
+	for id, section := range s.Sections {
+		if section.ProjectId == projectId {
+			s.removeSection(id)
+		}
+	}
+
+	for id, task := range s.Tasks {
+		if task.ProjectId == projectId {
+			s.removeTask(id)
+		}
+	}
+
+	for id, comment := range s.Comments {
+		if comment.ProjectId == projectId {
+			delete(s.Comments, id)
+		}
+	}
+}
+
+func (s *replicaState) removeSection(sectionId string) {
+	delete(s.Sections, sectionId)
+
+	for id, task := range s.Tasks {
+		if task.SectionId == sectionId {
+			s.removeTask(id)
+		}
+	}
+}
+
+func (s *replicaState) removeTask(taskId string) {
+	delete(s.Tasks, taskId)
+
+	for id, task := range s.Tasks {
+		if task.ParentId == taskId {
+			s.removeTask(id)
+		}
+	}
+
+	for id, comment := range s.Comments {
+		if comment.TaskId == taskId {
+			delete(s.Comments, id)
+		}
+	}
+}
+
+func (s *replicaState) projectCommentCount(projectId string) (count int) {
+	for _, comment := range s.Comments {
+		if comment.TaskId == "" && comment.ProjectId == projectId {
+			count++
+		}
+	}
+
+	return
+}
+
+func (s *replicaState) filterEnv(userId string) *FilterEnv {
+	env := &FilterEnv{UserId: userId}
+	for _, project := range s.Projects {
+		env.Projects = append(env.Projects, project)
+	}
+
+	for _, section := range s.Sections {
+		env.Sections = append(env.Sections, section)
+	}
+
+	return env
+}
+
+func (s *replicaState) taskCommentCount(taskId string) (count int) {
+	for _, comment := range s.Comments {
+		if comment.TaskId == taskId {
+			count++
+		}
+	}
+
+	return
+}
+
+func replicaNotFound(endpoint string, id string) *ApiError {
+	return &ApiError{
+		StatusCode: http.StatusNotFound,
+		Status:     "404 Not Found",
+		Method:     http.MethodGet,
+		Endpoint:   endpoint + "/" + id,
+		Message:    "not found in replica",
+	}
+}
+
+func containsFold(values []string, value string) bool {
+	for _, item := range values {
+		if strings.EqualFold(item, value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// writeFile replaces the file atomically, so that a crash never leaves it half-written.
+func writeFile(path string, data []byte) (err error) {
+	var file *os.File
+	if file, err = os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp"); err != nil {
+		return
+	}
+	//goland:noinspection GoUnhandledErrorResult
+	defer os.Remove(file.Name())
+
+	if _, err = file.Write(data); err != nil {
+		_ = file.Close()
+		return
+	}
+
+	if err = file.Sync(); err != nil {
+		_ = file.Close()
+		return
+	}
+
+	if err = file.Close(); err != nil {
+		return
+	}
+
+	return os.Rename(file.Name(), path)
+}
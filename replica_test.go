@@ -0,0 +1,169 @@
+package todoist_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	todoist "github.com/temoon/todoist-api"
+)
+
+func TestReplica(t *testing.T) {
+	srv := newServer(t)
+	client := srv.Todoist()
+	ctx := context.Background()
+
+	project, err := client.AddProject(ctx, todoist.MakeAddProjectParams().WithName("Groceries"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	milk, err := client.AddTask(ctx, todoist.MakeAddTaskParams().WithContent("Buy milk").WithProjectId(project.Id).WithLabels([]string{"errand"}).WithPriority(4))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := client.AddTask(ctx, todoist.MakeAddTaskParams().WithContent("Write report").WithDueString("today"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	replica := todoist.NewReplica(client)
+	replica.UserId = srv.User.Id
+
+	res, err := replica.Refresh(ctx)
+	if err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+	if !res.FullSync {
+		t.Errorf("first Refresh() was not a full sync")
+	}
+
+	tests := []struct {
+		name    string
+		params  *todoist.GetTasksParams
+		want    []string
+		wantErr error
+	}{
+		{name: "all", params: todoist.MakeGetTasksParams(), want: []string{milk.Id, report.Id}},
+		{name: "project", params: todoist.MakeGetTasksParams().WithProjectId(project.Id), want: []string{milk.Id}},
+		{name: "label", params: todoist.MakeGetTasksParams().WithLabel("Errand"), want: []string{milk.Id}},
+		{name: "ids", params: todoist.MakeGetTasksParams().WithIds([]string{report.Id}), want: []string{report.Id}},
+		{name: "filter", params: todoist.MakeGetTasksParams().WithFilter("today | #Groceries & p1"), want: []string{milk.Id, report.Id}},
+		{name: "filter no date", params: todoist.MakeGetTasksParams().WithFilter("no date"), want: []string{milk.Id}},
+		{name: "unsupported filter", params: todoist.MakeGetTasksParams().WithFilter("#Groceries &"), wantErr: todoist.ErrFilterNotSupported},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tasks, err := replica.GetTasks(ctx, tt.params)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("GetTasks() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetTasks() error = %v", err)
+			}
+
+			if got := taskIds(tasks); !equalStrings(got, tt.want) {
+				t.Errorf("GetTasks() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if err = client.CloseTask(ctx, report.Id); err != nil {
+		t.Fatal(err)
+	}
+	if err = client.DeleteProject(ctx, project.Id); err != nil {
+		t.Fatal(err)
+	}
+
+	if res, err = replica.Refresh(ctx); err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+	if res.FullSync {
+		t.Errorf("second Refresh() was a full sync")
+	}
+
+	tasks, err := replica.GetTasks(ctx, todoist.MakeGetTasksParams())
+	if err != nil || len(tasks) != 0 {
+		t.Errorf("GetTasks() after close and delete = %v, %v, want none", taskIds(tasks), err)
+	}
+
+	if _, err = replica.GetProject(ctx, project.Id); !errors.Is(err, todoist.ErrNotFound) {
+		t.Errorf("GetProject() of a deleted project error = %v, want ErrNotFound", err)
+	}
+	if _, err = replica.GetTask(ctx, milk.Id); !errors.Is(err, todoist.ErrNotFound) {
+		t.Errorf("GetTask() of a task of a deleted project error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestReplicaSaveLoad(t *testing.T) {
+	srv := newServer(t)
+	client := srv.Todoist()
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "replica.json")
+
+	replica := todoist.NewReplica(client)
+	if _, err := replica.Refresh(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := replica.Save(path); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	task, err := client.AddTask(ctx, todoist.MakeAddTaskParams().WithContent("Buy milk"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loaded := todoist.NewReplica(client)
+	if err = loaded.Load(path); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if loaded.SyncToken() != replica.SyncToken() {
+		t.Errorf("SyncToken() = %q, want %q", loaded.SyncToken(), replica.SyncToken())
+	}
+
+	projects, err := loaded.GetProjects(ctx)
+	if err != nil || len(projects) != 1 {
+		t.Fatalf("GetProjects() = %v, %v, want the inbox", projects, err)
+	}
+
+	res, err := loaded.Refresh(ctx)
+	if err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+	if res.FullSync {
+		t.Errorf("Refresh() after Load() was a full sync")
+	}
+	if _, err = loaded.GetTask(ctx, task.Id); err != nil {
+		t.Errorf("GetTask() error = %v", err)
+	}
+}
+
+func taskIds(tasks []todoist.Task) []string {
+	ids := make([]string, 0, len(tasks))
+	for _, task := range tasks {
+		ids = append(ids, task.Id)
+	}
+
+	return ids
+}
+
+func equalStrings(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
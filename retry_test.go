@@ -0,0 +1,210 @@
+package todoist_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	todoist "github.com/temoon/todoist-api"
+)
+
+// hangUp closes the connection without a response, like a dropped network.
+func hangUp(w http.ResponseWriter, _ *http.Request) {
+	conn, _, err := w.(http.Hijacker).Hijack()
+	if err == nil {
+		_ = conn.Close()
+	}
+}
+
+func TestRetry(t *testing.T) {
+	tests := []struct {
+		name     string
+		faults   []http.HandlerFunc
+		policy   *todoist.RetryPolicy
+		wantErr  error
+		requests int
+		minWait  time.Duration
+	}{
+		{
+			name:     "server error then success",
+			faults:   []http.HandlerFunc{status(http.StatusBadGateway), status(http.StatusServiceUnavailable)},
+			policy:   &todoist.RetryPolicy{MaxAttempts: 3, BaseBackoff: time.Millisecond},
+			requests: 3,
+		},
+		{
+			name:     "dropped connection then success",
+			faults:   []http.HandlerFunc{hangUp},
+			policy:   &todoist.RetryPolicy{MaxAttempts: 2, BaseBackoff: time.Millisecond},
+			requests: 2,
+		},
+		{
+			name:     "attempts exhausted",
+			faults:   []http.HandlerFunc{status(http.StatusInternalServerError), status(http.StatusInternalServerError)},
+			policy:   &todoist.RetryPolicy{MaxAttempts: 2, BaseBackoff: time.Millisecond},
+			wantErr:  todoist.ErrServer,
+			requests: 2,
+		},
+		{
+			name:     "client errors are not retried",
+			faults:   []http.HandlerFunc{status(http.StatusBadRequest)},
+			policy:   &todoist.RetryPolicy{MaxAttempts: 3, BaseBackoff: time.Millisecond},
+			wantErr:  &todoist.ApiError{},
+			requests: 1,
+		},
+		{
+			name:     "not implemented is not retried",
+			faults:   []http.HandlerFunc{status(http.StatusNotImplemented)},
+			policy:   &todoist.RetryPolicy{MaxAttempts: 3, BaseBackoff: time.Millisecond},
+			wantErr:  todoist.ErrServer,
+			requests: 1,
+		},
+		{
+			name:     "rate limited waits for Retry-After",
+			faults:   []http.HandlerFunc{status(http.StatusTooManyRequests, "Retry-After", "1")},
+			policy:   &todoist.RetryPolicy{MaxAttempts: 2, BaseBackoff: time.Millisecond, MaxBackoff: 2 * time.Second},
+			requests: 2,
+			minWait:  time.Second,
+		},
+		{
+			name:     "Retry-After beyond MaxBackoff stops",
+			faults:   []http.HandlerFunc{status(http.StatusTooManyRequests, "Retry-After", "3600")},
+			policy:   &todoist.RetryPolicy{MaxAttempts: 3, BaseBackoff: time.Millisecond, MaxBackoff: time.Second},
+			wantErr:  todoist.ErrRateLimited,
+			requests: 1,
+		},
+		{
+			name:     "no policy",
+			faults:   []http.HandlerFunc{status(http.StatusServiceUnavailable)},
+			wantErr:  todoist.ErrServer,
+			requests: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newStub(t, body("application/json", `[]`), tt.faults...)
+
+			start := time.Now()
+			_, err := todoist.New(&todoist.Opts{Token: "token", Client: s.client(), Retry: tt.policy}).GetProjects(context.Background())
+
+			switch want := tt.wantErr.(type) {
+			case nil:
+				if err != nil {
+					t.Fatalf("GetProjects() error = %v", err)
+				}
+			case *todoist.ApiError:
+				var apiErr *todoist.ApiError
+				if !errors.As(err, &apiErr) {
+					t.Fatalf("GetProjects() error = %v, want %T", err, want)
+				}
+			default:
+				if !errors.Is(err, want) {
+					t.Fatalf("GetProjects() error = %v, want %v", err, want)
+				}
+			}
+
+			if got := s.count(); got != tt.requests {
+				t.Errorf("requests = %d, want %d", got, tt.requests)
+			}
+
+			if waited := time.Since(start); waited < tt.minWait {
+				t.Errorf("retried after %v, want at least %v", waited, tt.minWait)
+			}
+		})
+	}
+}
+
+// roundTrips counts the requests that reach the transport, including those that get no response.
+type roundTrips struct {
+	mu    sync.Mutex
+	count int
+	next  http.RoundTripper
+}
+
+func (r *roundTrips) RoundTrip(req *http.Request) (*http.Response, error) {
+	r.mu.Lock()
+	r.count++
+	r.mu.Unlock()
+
+	return r.next.RoundTrip(req)
+}
+
+func TestRetryNetworkErrors(t *testing.T) {
+	tlsSrv := httptest.NewTLSServer(body("application/json", `[]`))
+	tlsSrv.Config.ErrorLog = log.New(io.Discard, "", 0)
+	t.Cleanup(tlsSrv.Close)
+
+	closed := httptest.NewServer(body("application/json", `[]`))
+	closed.Close()
+
+	tests := []struct {
+		name     string
+		baseUrl  string
+		requests int
+	}{
+		{name: "unknown certificate", baseUrl: tlsSrv.URL + "/", requests: 1},
+		{name: "unsupported scheme", baseUrl: "ftp://api.todoist.com/rest/v2/", requests: 1},
+		{name: "connection refused", baseUrl: closed.URL + "/", requests: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport := &roundTrips{next: &http.Transport{}}
+			_, err := todoist.New(&todoist.Opts{
+				Token:   "token",
+				BaseUrl: tt.baseUrl,
+				Client:  &http.Client{Transport: transport},
+				Retry:   &todoist.RetryPolicy{MaxAttempts: 3, BaseBackoff: time.Millisecond},
+			}).GetProjects(context.Background())
+			if err == nil {
+				t.Fatal("GetProjects() error = nil")
+			}
+
+			if transport.count != tt.requests {
+				t.Errorf("requests = %d, want %d (%v)", transport.count, tt.requests, err)
+			}
+		})
+	}
+}
+
+func TestRetryUnsafeMethods(t *testing.T) {
+	tests := []struct {
+		name     string
+		key      string
+		requests int
+	}{
+		{name: "without idempotency key", requests: 1},
+		{name: "with idempotency key", key: "6c5e1b6a", requests: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var keys []string
+			record := func(w http.ResponseWriter, r *http.Request) {
+				keys = append(keys, r.Header.Get("X-Request-Id"))
+				status(http.StatusServiceUnavailable)(w, r)
+			}
+			s := newStub(t, body("application/json", `{"id": "1"}`), record)
+
+			ctx := context.Background()
+			if tt.key != "" {
+				ctx = todoist.WithIdempotencyKey(ctx, tt.key)
+			}
+
+			opts := &todoist.Opts{Token: "token", Client: s.client(), Retry: &todoist.RetryPolicy{MaxAttempts: 3, BaseBackoff: time.Millisecond}}
+			_, _ = todoist.New(opts).AddTask(ctx, todoist.MakeAddTaskParams().WithContent("Buy milk"))
+			if got := s.count(); got != tt.requests {
+				t.Errorf("requests = %d, want %d", got, tt.requests)
+			}
+			if len(keys) != 1 || keys[0] != tt.key {
+				t.Errorf("X-Request-Id = %q, want %q", keys, tt.key)
+			}
+		})
+	}
+}
@@ -1,7 +1,6 @@
 package todoist
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"net/http"
@@ -37,7 +36,7 @@ func (p *GetSectionsParams) WithProjectId(projectId string) *GetSectionsParams {
 
 func (t *Todoist) GetSections(ctx context.Context, params *GetSectionsParams) (sections []Section, err error) {
 	sections = make([]Section, 0)
-	err = t.request(ctx, http.MethodGet, SectionsEndpoint, *params, nil, &sections)
+	err = t.request(ctx, "GetSections", http.MethodGet, SectionsEndpoint, *params, nil, &sections)
 
 	return
 }
@@ -85,7 +84,7 @@ func (t *Todoist) AddSection(ctx context.Context, params *AddSectionParams) (sec
 	}
 
 	section = new(Section)
-	err = t.request(ctx, http.MethodPost, SectionsEndpoint, nil, bytes.NewBuffer(payload), section)
+	err = t.request(ctx, "AddSection", http.MethodPost, SectionsEndpoint, nil, payload, section)
 
 	return
 }
@@ -97,7 +96,7 @@ func (t *Todoist) AddSection(ctx context.Context, params *AddSectionParams) (sec
 func (t *Todoist) GetSection(ctx context.Context, sectionId string) (section *Section, err error) {
 	section = new(Section)
 	encodedSectionId := url.PathEscape(sectionId)
-	err = t.request(ctx, http.MethodGet, SectionsEndpoint+"/"+encodedSectionId, nil, nil, section)
+	err = t.request(ctx, "GetSection", http.MethodGet, SectionsEndpoint+"/"+encodedSectionId, nil, nil, section)
 	return
 }
 
@@ -127,7 +126,7 @@ func (t *Todoist) UpdateSection(ctx context.Context, sectionId string, params *U
 		return
 	}
 	encodedSectionId := url.PathEscape(sectionId)
-	return t.request(ctx, http.MethodPost, SectionsEndpoint+"/"+encodedSectionId, nil, bytes.NewBuffer(payload), nil)
+	return t.request(ctx, "UpdateSection", http.MethodPost, SectionsEndpoint+"/"+encodedSectionId, nil, payload, nil)
 }
 
 // endregion
@@ -136,7 +135,7 @@ func (t *Todoist) UpdateSection(ctx context.Context, sectionId string, params *U
 
 func (t *Todoist) DeleteSection(ctx context.Context, sectionId string) (err error) {
 	encodedSectionId := url.PathEscape(sectionId)
-	return t.request(ctx, http.MethodDelete, SectionsEndpoint+"/"+encodedSectionId, nil, nil, nil)
+	return t.request(ctx, "DeleteSection", http.MethodDelete, SectionsEndpoint+"/"+encodedSectionId, nil, nil, nil)
 }
 
 // endregion
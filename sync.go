@@ -0,0 +1,397 @@
+package todoist
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"sync"
+)
+
+const SyncBaseUrl = "https://api.todoist.com/sync/v9/"
+
+const SyncEndpoint = "sync"
+
+const FullSyncToken = "*"
+
+const (
+	AllResources          = "all"
+	ProjectsResource      = "projects"
+	ItemsResource         = "items"
+	SectionsResource      = "sections"
+	LabelsResource        = "labels"
+	NotesResource         = "notes"
+	ProjectNotesResource  = "project_notes"
+	CollaboratorsResource = "collaborators"
+)
+
+type SyncProject struct {
+	Project
+
+	IsDeleted  bool
+	IsArchived bool
+}
+
+type SyncTask struct {
+	Task
+
+	IsDeleted bool
+}
+
+type SyncSection struct {
+	Section
+
+	IsDeleted  bool
+	IsArchived bool
+}
+
+type SyncLabel struct {
+	Label
+
+	IsDeleted bool
+}
+
+type SyncComment struct {
+	Comment
+
+	IsDeleted bool
+}
+
+type SyncResponse struct {
+	SyncToken     string
+	FullSync      bool
+	Projects      []SyncProject
+	Tasks         []SyncTask
+	Sections      []SyncSection
+	Labels        []SyncLabel
+	Comments      []SyncComment
+	TempIdMapping map[string]string
+	SyncStatus    map[string]json.RawMessage
+}
+
+// region Sync
+
+type SyncParams map[string]string
+
+//goland:noinspection GoUnusedExportedFunction
+func MakeSyncParams() *SyncParams {
+	params := make(SyncParams)
+	return &params
+}
+
+func (p *SyncParams) WithSyncToken(syncToken string) *SyncParams {
+	if syncToken != "" {
+		(*p)["sync_token"] = syncToken
+	}
+
+	return p
+}
+
+func (p *SyncParams) WithResourceTypes(resourceTypes []string) *SyncParams {
+	if resourceTypes != nil && len(resourceTypes) != 0 {
+		encoded, _ := json.Marshal(resourceTypes)
+		(*p)["resource_types"] = string(encoded)
+	}
+
+	return p
+}
+
+func (t *Todoist) Sync(ctx context.Context, params *SyncParams) (res *SyncResponse, err error) {
+	form := make(url.Values)
+	for key, value := range *params {
+		form.Set(key, value)
+	}
+
+	call := &Call{
+		Operation:  "Sync",
+		Method:     http.MethodPost,
+		BaseUrl:    t.opts.SyncBaseUrl,
+		Endpoint:   SyncEndpoint,
+		Payload:    []byte(form.Encode()),
+		Header:     make(http.Header),
+		Idempotent: true, // Reads are safe to repeat, and Todoist applies a command uuid only once.
+	}
+	call.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	res = new(SyncResponse)
+	err = t.call(ctx, call, res)
+
+	return
+}
+
+// endregion
+
+// region SyncClient
+
+// SyncClient remembers the sync token between calls, so that only the first Fetch
+// does a full sync and the following ones return changes since the previous call.
+type SyncClient struct {
+	t             *Todoist
+	resourceTypes []string
+
+	mu        sync.Mutex
+	syncToken string
+}
+
+//goland:noinspection GoUnusedExportedFunction
+func NewSyncClient(t *Todoist, resourceTypes ...string) *SyncClient {
+	if len(resourceTypes) == 0 {
+		resourceTypes = []string{AllResources}
+	}
+
+	return &SyncClient{
+		t:             t,
+		resourceTypes: resourceTypes,
+		syncToken:     FullSyncToken,
+	}
+}
+
+func (c *SyncClient) SyncToken() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.syncToken
+}
+
+func (c *SyncClient) SetSyncToken(syncToken string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if syncToken == "" {
+		syncToken = FullSyncToken
+	}
+	c.syncToken = syncToken
+}
+
+func (c *SyncClient) Reset() {
+	c.SetSyncToken(FullSyncToken)
+}
+
+func (c *SyncClient) Fetch(ctx context.Context) (res *SyncResponse, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	params := MakeSyncParams().WithSyncToken(c.syncToken).WithResourceTypes(c.resourceTypes)
+	if res, err = c.t.Sync(ctx, params); err != nil {
+		return
+	}
+
+	if res.SyncToken != "" {
+		c.syncToken = res.SyncToken
+	}
+
+	return
+}
+
+// endregion
+
+// region Decoding
+
+func (r *SyncResponse) UnmarshalJSON(data []byte) (err error) {
+	var raw struct {
+		SyncToken     string                     `json:"sync_token"`
+		FullSync      bool                       `json:"full_sync"`
+		Projects      []SyncProject              `json:"projects"`
+		Items         []SyncTask                 `json:"items"`
+		Sections      []SyncSection              `json:"sections"`
+		Labels        []SyncLabel                `json:"labels"`
+		Notes         []SyncComment              `json:"notes"`
+		ProjectNotes  []SyncComment              `json:"project_notes"`
+		TempIdMapping map[string]string          `json:"temp_id_mapping"`
+		SyncStatus    map[string]json.RawMessage `json:"sync_status"`
+	}
+
+	if err = json.Unmarshal(data, &raw); err != nil {
+		return
+	}
+
+	*r = SyncResponse{
+		SyncToken:     raw.SyncToken,
+		FullSync:      raw.FullSync,
+		Projects:      raw.Projects,
+		Tasks:         raw.Items,
+		Sections:      raw.Sections,
+		Labels:        raw.Labels,
+		Comments:      append(raw.Notes, raw.ProjectNotes...),
+		TempIdMapping: raw.TempIdMapping,
+		SyncStatus:    raw.SyncStatus,
+	}
+
+	return
+}
+
+func (p *SyncProject) UnmarshalJSON(data []byte) (err error) {
+	var raw struct {
+		Id           string `json:"id"`
+		Name         string `json:"name"`
+		Color        string `json:"color"`
+		ParentId     string `json:"parent_id"`
+		ChildOrder   int    `json:"child_order"`
+		Shared       bool   `json:"shared"`
+		IsFavorite   bool   `json:"is_favorite"`
+		InboxProject bool   `json:"inbox_project"`
+		TeamInbox    bool   `json:"team_inbox"`
+		IsDeleted    bool   `json:"is_deleted"`
+		IsArchived   bool   `json:"is_archived"`
+	}
+
+	if err = json.Unmarshal(data, &raw); err != nil {
+		return
+	}
+
+	*p = SyncProject{
+		Project: Project{
+			Id:             raw.Id,
+			Name:           raw.Name,
+			Color:          raw.Color,
+			ParentId:       raw.ParentId,
+			Order:          raw.ChildOrder,
+			IsShared:       raw.Shared,
+			IsFavorite:     raw.IsFavorite,
+			IsInboxProject: raw.InboxProject,
+			IsTeamInbox:    raw.TeamInbox,
+			Url:            "https://todoist.com/showProject?id=" + url.QueryEscape(raw.Id),
+		},
+		IsDeleted:  raw.IsDeleted,
+		IsArchived: raw.IsArchived,
+	}
+
+	return
+}
+
+func (t *SyncTask) UnmarshalJSON(data []byte) (err error) {
+	var raw struct {
+		Id             string   `json:"id"`
+		ProjectId      string   `json:"project_id"`
+		SectionId      string   `json:"section_id"`
+		Content        string   `json:"content"`
+		Description    string   `json:"description"`
+		Checked        bool     `json:"checked"`
+		Labels         []string `json:"labels"`
+		ParentId       string   `json:"parent_id"`
+		ChildOrder     int      `json:"child_order"`
+		Priority       int      `json:"priority"`
+		Due            *Due     `json:"due"`
+		ResponsibleUid string   `json:"responsible_uid"`
+		AssignedByUid  string   `json:"assigned_by_uid"`
+		IsDeleted      bool     `json:"is_deleted"`
+	}
+
+	if err = json.Unmarshal(data, &raw); err != nil {
+		return
+	}
+
+	if raw.Due != nil {
+		raw.Due.normalize()
+	}
+
+	*t = SyncTask{
+		Task: Task{
+			Id:          raw.Id,
+			ProjectId:   raw.ProjectId,
+			SectionId:   raw.SectionId,
+			Content:     raw.Content,
+			Description: raw.Description,
+			IsCompleted: raw.Checked,
+			Labels:      raw.Labels,
+			ParentId:    raw.ParentId,
+			Order:       raw.ChildOrder,
+			Priority:    raw.Priority,
+			Due:         raw.Due,
+			Url:         "https://todoist.com/showTask?id=" + url.QueryEscape(raw.Id),
+			AssigneeId:  raw.ResponsibleUid,
+			AssignerId:  raw.AssignedByUid,
+		},
+		IsDeleted: raw.IsDeleted,
+	}
+
+	return
+}
+
+func (s *SyncSection) UnmarshalJSON(data []byte) (err error) {
+	var raw struct {
+		Id           string `json:"id"`
+		ProjectId    string `json:"project_id"`
+		SectionOrder int    `json:"section_order"`
+		Name         string `json:"name"`
+		IsDeleted    bool   `json:"is_deleted"`
+		IsArchived   bool   `json:"is_archived"`
+	}
+
+	if err = json.Unmarshal(data, &raw); err != nil {
+		return
+	}
+
+	*s = SyncSection{
+		Section: Section{
+			Id:        raw.Id,
+			ProjectId: raw.ProjectId,
+			Order:     raw.SectionOrder,
+			Name:      raw.Name,
+		},
+		IsDeleted:  raw.IsDeleted,
+		IsArchived: raw.IsArchived,
+	}
+
+	return
+}
+
+func (l *SyncLabel) UnmarshalJSON(data []byte) (err error) {
+	var raw struct {
+		Id         string `json:"id"`
+		Name       string `json:"name"`
+		Color      string `json:"color"`
+		ItemOrder  int    `json:"item_order"`
+		IsFavorite bool   `json:"is_favorite"`
+		IsDeleted  bool   `json:"is_deleted"`
+	}
+
+	if err = json.Unmarshal(data, &raw); err != nil {
+		return
+	}
+
+	*l = SyncLabel{
+		Label: Label{
+			Id:         raw.Id,
+			Name:       raw.Name,
+			Color:      raw.Color,
+			Order:      raw.ItemOrder,
+			IsFavorite: raw.IsFavorite,
+		},
+		IsDeleted: raw.IsDeleted,
+	}
+
+	return
+}
+
+func (c *SyncComment) UnmarshalJSON(data []byte) (err error) {
+	var raw struct {
+		Id             string                 `json:"id"`
+		ItemId         string                 `json:"item_id"`
+		ProjectId      string                 `json:"project_id"`
+		PostedAt       string                 `json:"posted_at"`
+		Content        string                 `json:"content"`
+		FileAttachment map[string]interface{} `json:"file_attachment"`
+		IsDeleted      bool                   `json:"is_deleted"`
+	}
+
+	if err = json.Unmarshal(data, &raw); err != nil {
+		return
+	}
+
+	*c = SyncComment{
+		Comment: Comment{
+			Id:         raw.Id,
+			TaskId:     raw.ItemId,
+			ProjectId:  raw.ProjectId,
+			PostedAt:   raw.PostedAt,
+			Content:    raw.Content,
+			Attachment: raw.FileAttachment,
+		},
+		IsDeleted: raw.IsDeleted,
+	}
+
+	return
+}
+
+// endregion
@@ -0,0 +1,63 @@
+package todoist_test
+
+import (
+	"context"
+	"testing"
+
+	todoist "github.com/temoon/todoist-api"
+	"github.com/temoon/todoist-api/todoisttest"
+)
+
+func newServer(t *testing.T) *todoisttest.Server {
+	srv := todoisttest.NewServer()
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func TestSyncIncremental(t *testing.T) {
+	srv := newServer(t)
+	client := srv.Todoist()
+	ctx := context.Background()
+
+	sync := todoist.NewSyncClient(client)
+
+	res, err := sync.Fetch(ctx)
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if !res.FullSync || len(res.Projects) != 1 || !res.Projects[0].IsInboxProject {
+		t.Fatalf("full sync = %+v", res)
+	}
+
+	task, err := client.AddTask(ctx, todoist.MakeAddTaskParams().WithContent("Buy milk"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if res, err = sync.Fetch(ctx); err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if res.FullSync || len(res.Tasks) != 1 || res.Tasks[0].Id != task.Id || len(res.Projects) != 0 {
+		t.Fatalf("incremental sync = %+v", res)
+	}
+
+	if err = client.DeleteTask(ctx, task.Id); err != nil {
+		t.Fatal(err)
+	}
+
+	if res, err = sync.Fetch(ctx); err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if len(res.Tasks) != 1 || !res.Tasks[0].IsDeleted {
+		t.Fatalf("sync after delete = %+v", res.Tasks)
+	}
+
+	sync.Reset()
+	if res, err = sync.Fetch(ctx); err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if !res.FullSync || len(res.Tasks) != 0 {
+		t.Fatalf("sync after reset = %+v", res)
+	}
+}
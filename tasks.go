@@ -1,7 +1,6 @@
 package todoist
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"net/http"
@@ -22,21 +21,13 @@ type Task struct {
 	ParentId     string   `json:"parent_id"`
 	Order        int      `json:"order"`
 	Priority     int      `json:"priority"`
-	Due          Due      `json:"due"`
+	Due          *Due     `json:"due"`
 	Url          string   `json:"url"`
 	CommentCount int      `json:"comment_count"`
 	AssigneeId   string   `json:"assignee_id"`
 	AssignerId   string   `json:"assigner_id"`
 }
 
-type Due struct {
-	String      string `json:"string"`
-	Date        string `json:"date"`
-	IsRecurring bool   `json:"is_recurring"`
-	Datetime    string `json:"datetime"`
-	Timezone    string `json:"timezone"`
-}
-
 // region GetTasks
 
 type GetTasksParams map[string]string
@@ -79,6 +70,15 @@ func (p *GetTasksParams) WithFilter(filter string) *GetTasksParams {
 	return p
 }
 
+// WithFilterQuery sets the filter from a query of the filter package, or a parsed Filter.
+func (p *GetTasksParams) WithFilterQuery(query FilterQuery) *GetTasksParams {
+	if query != nil {
+		p.WithFilter(query.String())
+	}
+
+	return p
+}
+
 func (p *GetTasksParams) WithLang(lang string) *GetTasksParams {
 	if lang != "" {
 		(*p)["lang"] = lang
@@ -97,7 +97,7 @@ func (p *GetTasksParams) WithIds(ids []string) *GetTasksParams {
 
 func (t *Todoist) GetTasks(ctx context.Context, params *GetTasksParams) (tasks []Task, err error) {
 	tasks = make([]Task, 0)
-	err = t.request(ctx, http.MethodGet, TasksEndpoint, *params, nil, &tasks)
+	err = t.request(ctx, "GetTasks", http.MethodGet, TasksEndpoint, *params, nil, &tasks)
 
 	return
 }
@@ -225,7 +225,7 @@ func (t *Todoist) AddTask(ctx context.Context, params *AddTaskParams) (task *Tas
 	}
 
 	task = new(Task)
-	err = t.request(ctx, http.MethodPost, TasksEndpoint, nil, bytes.NewBuffer(payload), task)
+	err = t.request(ctx, "AddTask", http.MethodPost, TasksEndpoint, nil, payload, task)
 
 	return
 }
@@ -237,7 +237,7 @@ func (t *Todoist) AddTask(ctx context.Context, params *AddTaskParams) (task *Tas
 func (t *Todoist) GetTask(ctx context.Context, taskId string) (task *Task, err error) {
 	task = new(Task)
 	encodedTaskId := url.PathEscape(taskId)
-	err = t.request(ctx, http.MethodGet, TasksEndpoint+"/"+encodedTaskId, nil, nil, task)
+	err = t.request(ctx, "GetTask", http.MethodGet, TasksEndpoint+"/"+encodedTaskId, nil, nil, task)
 
 	return
 }
@@ -332,7 +332,7 @@ func (t *Todoist) UpdateTask(ctx context.Context, taskId string, params *UpdateT
 		return
 	}
 	encodedTaskId := url.PathEscape(taskId)
-	return t.request(ctx, http.MethodPost, TasksEndpoint+"/"+encodedTaskId, nil, bytes.NewBuffer(payload), nil)
+	return t.request(ctx, "UpdateTask", http.MethodPost, TasksEndpoint+"/"+encodedTaskId, nil, payload, nil)
 }
 
 // endregion
@@ -341,7 +341,7 @@ func (t *Todoist) UpdateTask(ctx context.Context, taskId string, params *UpdateT
 
 func (t *Todoist) CloseTask(ctx context.Context, taskId string) (err error) {
 	encodedTaskId := url.PathEscape(taskId)
-	return t.request(ctx, http.MethodPost, TasksEndpoint+"/"+encodedTaskId+"/close", nil, nil, nil)
+	return t.request(ctx, "CloseTask", http.MethodPost, TasksEndpoint+"/"+encodedTaskId+"/close", nil, nil, nil)
 }
 
 // endregion
@@ -350,7 +350,7 @@ func (t *Todoist) CloseTask(ctx context.Context, taskId string) (err error) {
 
 func (t *Todoist) ReopenTask(ctx context.Context, taskId string) (err error) {
 	encodedTaskId := url.PathEscape(taskId)
-	return t.request(ctx, http.MethodPost, TasksEndpoint+"/"+encodedTaskId+"/reopen", nil, nil, nil)
+	return t.request(ctx, "ReopenTask", http.MethodPost, TasksEndpoint+"/"+encodedTaskId+"/reopen", nil, nil, nil)
 }
 
 // endregion
@@ -359,7 +359,7 @@ func (t *Todoist) ReopenTask(ctx context.Context, taskId string) (err error) {
 
 func (t *Todoist) DeleteTask(ctx context.Context, taskId string) (err error) {
 	encodedTaskId := url.PathEscape(taskId)
-	return t.request(ctx, http.MethodDelete, TasksEndpoint+"/"+encodedTaskId, nil, nil, nil)
+	return t.request(ctx, "DeleteTask", http.MethodDelete, TasksEndpoint+"/"+encodedTaskId, nil, nil, nil)
 }
 
 // endregion
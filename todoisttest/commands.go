@@ -0,0 +1,149 @@
+package todoisttest
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	todoist "github.com/temoon/todoist-api"
+)
+
+// commandHandlers translate Sync API commands into the REST handlers with REST argument names.
+var commandHandlers = map[string]func(s *Server, req *request) (int, interface{}){
+	"project_add":     func(s *Server, req *request) (int, interface{}) { return s.addProject(req) },
+	"project_update":  func(s *Server, req *request) (int, interface{}) { return s.updateProject(req.string("id"), req) },
+	"project_delete":  func(s *Server, req *request) (int, interface{}) { return s.deleteProject(req.string("id")) },
+	"section_add":     func(s *Server, req *request) (int, interface{}) { return s.addSection(req) },
+	"section_update":  func(s *Server, req *request) (int, interface{}) { return s.updateSection(req.string("id"), req) },
+	"section_delete":  func(s *Server, req *request) (int, interface{}) { return s.deleteSection(req.string("id")) },
+	"item_add":        func(s *Server, req *request) (int, interface{}) { return s.addTask(req) },
+	"item_update":     func(s *Server, req *request) (int, interface{}) { return s.updateTask(req.string("id"), req) },
+	"item_close":      func(s *Server, req *request) (int, interface{}) { return s.closeTask(req.string("id")) },
+	"item_complete":   func(s *Server, req *request) (int, interface{}) { return s.closeTask(req.string("id")) },
+	"item_uncomplete": func(s *Server, req *request) (int, interface{}) { return s.reopenTask(req.string("id")) },
+	"item_delete":     func(s *Server, req *request) (int, interface{}) { return s.deleteTask(req.string("id")) },
+	"label_add":       func(s *Server, req *request) (int, interface{}) { return s.addLabel(req) },
+	"label_update":    func(s *Server, req *request) (int, interface{}) { return s.updateLabel(req.string("id"), req) },
+	"label_delete":    func(s *Server, req *request) (int, interface{}) { return s.deleteLabel(req.string("id")) },
+	"note_add":        func(s *Server, req *request) (int, interface{}) { return s.addComment(req) },
+	"note_update":     func(s *Server, req *request) (int, interface{}) { return s.updateComment(req.string("id"), req) },
+	"note_delete":     func(s *Server, req *request) (int, interface{}) { return s.deleteComment(req.string("id")) },
+}
+
+var commandArgs = map[string]string{
+	"child_order":     "order",
+	"section_order":   "order",
+	"item_order":      "order",
+	"responsible_uid": "assignee_id",
+	"item_id":         "task_id",
+	"file_attachment": "attachment",
+}
+
+func (s *Server) runCommands(value string, res map[string]interface{}) bool {
+	var commands []todoist.Command
+	if err := json.Unmarshal([]byte(value), &commands); err != nil {
+		return false
+	}
+
+	mapping := res["temp_id_mapping"].(map[string]string)
+	statuses := res["sync_status"].(map[string]interface{})
+
+	for _, command := range commands {
+		handler, ok := commandHandlers[command.Type]
+		if !ok {
+			statuses[command.Uuid] = commandError(http.StatusBadRequest, "Unknown command")
+			continue
+		}
+
+		req := &request{
+			method: http.MethodPost,
+			params: s.commandParams(command.Args),
+		}
+
+		status, data := handler(s, req)
+		if status >= 400 {
+			statuses[command.Uuid] = commandError(status, data.(string))
+			continue
+		}
+
+		statuses[command.Uuid] = "ok"
+
+		if command.TempId != "" {
+			if id := objectId(data); id != "" {
+				mapping[command.TempId] = id
+				s.tempIds[command.TempId] = id
+			}
+		}
+	}
+
+	return true
+}
+
+func (s *Server) commandParams(args map[string]interface{}) map[string]interface{} {
+	params := make(map[string]interface{}, len(args))
+	for key, value := range args {
+		if id, ok := value.(string); ok && s.tempIds[id] != "" {
+			value = s.tempIds[id]
+		}
+
+		if renamed, ok := commandArgs[key]; ok {
+			key = renamed
+		}
+
+		params[key] = value
+	}
+
+	if due, ok := params["due"].(map[string]interface{}); ok {
+		delete(params, "due")
+
+		date, _ := due["date"].(string)
+		switch {
+		case due["string"] != nil:
+			params["due_string"] = due["string"]
+		case strings.Contains(date, "T"):
+			params["due_datetime"] = date
+		case date != "":
+			params["due_date"] = date
+		}
+	} else if _, ok := params["due"]; ok {
+		delete(params, "due")
+		params["due_string"] = "no date"
+	}
+
+	return params
+}
+
+func objectId(data interface{}) string {
+	switch data := data.(type) {
+	case todoist.Project:
+		return data.Id
+	case todoist.Section:
+		return data.Id
+	case todoist.Task:
+		return data.Id
+	case todoist.Label:
+		return data.Id
+	case todoist.Comment:
+		return data.Id
+	default:
+		return ""
+	}
+}
+
+func commandError(status int, message string) map[string]interface{} {
+	code, tag := 20, "INVALID_ARGUMENT_VALUE"
+	switch status {
+	case http.StatusNotFound:
+		code, tag = 22, "NOT_FOUND"
+	case http.StatusConflict:
+		code, tag = 36, "ALREADY_EXISTS"
+	}
+
+	return map[string]interface{}{
+		"error":       message,
+		"error_code":  code,
+		"error_tag":   tag,
+		"http_code":   status,
+		"error_extra": map[string]interface{}{},
+	}
+}
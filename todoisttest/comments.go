@@ -0,0 +1,148 @@
+package todoisttest
+
+import (
+	"net/http"
+
+	todoist "github.com/temoon/todoist-api"
+)
+
+func (s *Server) routeComments(req *request) (int, interface{}) {
+	switch {
+	case req.is(http.MethodGet, 1):
+		return s.getComments(req)
+	case req.is(http.MethodPost, 1):
+		return s.addComment(req)
+	case req.is(http.MethodGet, 2):
+		return s.getComment(req.path[1])
+	case req.is(http.MethodPost, 2):
+		return s.updateComment(req.path[1], req)
+	case req.is(http.MethodDelete, 2):
+		return s.deleteComment(req.path[1])
+	default:
+		return http.StatusNotFound, "Not found"
+	}
+}
+
+func (s *Server) getComments(req *request) (int, interface{}) {
+	projectId := req.queryValue("project_id")
+	taskId := req.queryValue("task_id")
+
+	switch {
+	case taskId != "":
+		if s.task(taskId) == nil {
+			return notFound("Task")
+		}
+	case projectId != "":
+		if s.project(projectId) == nil {
+			return notFound("Project")
+		}
+	default:
+		return missing("project_id or task_id")
+	}
+
+	comments := make([]todoist.Comment, 0)
+	for _, comment := range s.comments {
+		if taskId != "" && comment.TaskId == taskId || taskId == "" && comment.TaskId == "" && comment.ProjectId == projectId {
+			comments = append(comments, *comment)
+		}
+	}
+
+	return http.StatusOK, comments
+}
+
+func (s *Server) addComment(req *request) (int, interface{}) {
+	if req.string("content") == "" && !req.has("attachment") {
+		return missing("content")
+	}
+
+	comment := &todoist.Comment{
+		Id:       s.newId(),
+		PostedAt: s.timestamp(),
+		Content:  req.string("content"),
+	}
+
+	if attachment, ok := req.params["attachment"].(map[string]interface{}); ok {
+		comment.Attachment = attachment
+	}
+
+	if taskId := req.string("task_id"); taskId != "" {
+		task := s.task(taskId)
+		if task == nil {
+			return notFound("Task")
+		}
+		comment.TaskId = task.Id
+		task.CommentCount++
+	} else if projectId := req.string("project_id"); projectId != "" {
+		project := s.project(projectId)
+		if project == nil {
+			return notFound("Project")
+		}
+		comment.ProjectId = project.Id
+		project.CommentCount++
+	} else {
+		return missing("project_id or task_id")
+	}
+
+	s.comments = append(s.comments, comment)
+
+	return http.StatusOK, *comment
+}
+
+func (s *Server) getComment(id string) (int, interface{}) {
+	comment := s.comment(id)
+	if comment == nil {
+		return notFound("Comment")
+	}
+
+	return http.StatusOK, *comment
+}
+
+func (s *Server) updateComment(id string, req *request) (int, interface{}) {
+	comment := s.comment(id)
+	if comment == nil {
+		return notFound("Comment")
+	}
+
+	if content := req.string("content"); content != "" {
+		comment.Content = content
+	}
+
+	return http.StatusOK, *comment
+}
+
+func (s *Server) deleteComment(id string) (int, interface{}) {
+	if s.comment(id) == nil {
+		return notFound("Comment")
+	}
+
+	s.removeComment(id)
+
+	return http.StatusNoContent, nil
+}
+
+func (s *Server) comment(id string) *todoist.Comment {
+	for _, comment := range s.comments {
+		if comment.Id == id {
+			return comment
+		}
+	}
+
+	return nil
+}
+
+func (s *Server) removeComment(id string) {
+	comments := s.comments[:0]
+	for _, comment := range s.comments {
+		if comment.Id != id {
+			comments = append(comments, comment)
+			continue
+		}
+
+		if task := s.task(comment.TaskId); task != nil {
+			task.CommentCount--
+		} else if project := s.project(comment.ProjectId); project != nil {
+			project.CommentCount--
+		}
+	}
+	s.comments = comments
+}
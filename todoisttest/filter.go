@@ -0,0 +1,30 @@
+package todoisttest
+
+import (
+	todoist "github.com/temoon/todoist-api"
+)
+
+// compileFilter evaluates filters with todoist.ParseFilter, at the time of the server and as its user.
+// Tasks match when they match any view.
+func (s *Server) compileFilter(filter string) (func(*todoist.Task) bool, bool) {
+	f, err := todoist.ParseFilter(filter)
+	if err != nil {
+		return nil, false
+	}
+
+	env := &todoist.FilterEnv{
+		Now:    s.Now(),
+		UserId: s.User.Id,
+	}
+
+	for _, project := range s.projects {
+		env.Projects = append(env.Projects, *project)
+		env.Collaborators = append(env.Collaborators, s.collaborators[project.Id]...)
+	}
+
+	for _, section := range s.sections {
+		env.Sections = append(env.Sections, *section)
+	}
+
+	return func(task *todoist.Task) bool { return f.Match(*task, env) }, true
+}
@@ -0,0 +1,162 @@
+package todoisttest
+
+import (
+	"net/http"
+	"strings"
+
+	todoist "github.com/temoon/todoist-api"
+)
+
+func (s *Server) routeLabels(req *request) (int, interface{}) {
+	switch {
+	case req.is(http.MethodGet, 1):
+		return s.getLabels()
+	case req.is(http.MethodPost, 1):
+		return s.addLabel(req)
+	case req.is(http.MethodGet, 2):
+		return s.getLabel(req.path[1])
+	case req.is(http.MethodPost, 2):
+		return s.updateLabel(req.path[1], req)
+	case req.is(http.MethodDelete, 2):
+		return s.deleteLabel(req.path[1])
+	default:
+		return http.StatusNotFound, "Not found"
+	}
+}
+
+func (s *Server) getLabels() (int, interface{}) {
+	labels := make([]todoist.Label, 0, len(s.labels))
+	for _, label := range s.labels {
+		labels = append(labels, *label)
+	}
+
+	return http.StatusOK, labels
+}
+
+func (s *Server) addLabel(req *request) (int, interface{}) {
+	name := req.string("name")
+	if name == "" {
+		return missing("name")
+	}
+
+	if s.labelByName(name) != nil {
+		return http.StatusConflict, "Label already exists"
+	}
+
+	label := &todoist.Label{
+		Id:         s.newId(),
+		Name:       name,
+		Color:      req.string("color"),
+		Order:      req.int("order"),
+		IsFavorite: req.bool("is_favorite"),
+	}
+
+	if label.Color == "" {
+		label.Color = todoist.CharcoalColor
+	}
+
+	if label.Order == 0 {
+		label.Order = 1
+		for _, other := range s.labels {
+			if other.Order >= label.Order {
+				label.Order = other.Order + 1
+			}
+		}
+	}
+
+	s.labels = append(s.labels, label)
+
+	return http.StatusOK, *label
+}
+
+func (s *Server) getLabel(id string) (int, interface{}) {
+	label := s.label(id)
+	if label == nil {
+		return notFound("Label")
+	}
+
+	return http.StatusOK, *label
+}
+
+func (s *Server) updateLabel(id string, req *request) (int, interface{}) {
+	label := s.label(id)
+	if label == nil {
+		return notFound("Label")
+	}
+
+	if name := req.string("name"); name != "" && name != label.Name {
+		if s.labelByName(name) != nil {
+			return http.StatusConflict, "Label already exists"
+		}
+
+		for _, task := range s.tasks {
+			for i := range task.Labels {
+				if strings.EqualFold(task.Labels[i], label.Name) {
+					task.Labels[i] = name
+				}
+			}
+		}
+		label.Name = name
+	}
+
+	if order := req.int("order"); order != 0 {
+		label.Order = order
+	}
+
+	if color := req.string("color"); color != "" {
+		label.Color = color
+	}
+
+	if req.has("is_favorite") {
+		label.IsFavorite = req.bool("is_favorite")
+	}
+
+	return http.StatusOK, *label
+}
+
+func (s *Server) deleteLabel(id string) (int, interface{}) {
+	label := s.label(id)
+	if label == nil {
+		return notFound("Label")
+	}
+
+	for _, task := range s.tasks {
+		names := task.Labels[:0]
+		for _, name := range task.Labels {
+			if !strings.EqualFold(name, label.Name) {
+				names = append(names, name)
+			}
+		}
+		task.Labels = names
+	}
+
+	labels := s.labels[:0]
+	for _, other := range s.labels {
+		if other.Id != id {
+			labels = append(labels, other)
+		}
+	}
+	s.labels = labels
+
+	return http.StatusNoContent, nil
+}
+
+func (s *Server) label(id string) *todoist.Label {
+	for _, label := range s.labels {
+		if label.Id == id {
+			return label
+		}
+	}
+
+	return nil
+}
+
+func (s *Server) labelByName(name string) *todoist.Label {
+	for _, label := range s.labels {
+		if strings.EqualFold(label.Name, name) {
+			return label
+		}
+	}
+
+	return nil
+}
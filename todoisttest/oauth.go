@@ -0,0 +1,149 @@
+package todoisttest
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/temoon/todoist-api/oauth"
+)
+
+const (
+	DefaultClientId     = "todoisttest-client"
+	DefaultClientSecret = "todoisttest-secret"
+)
+
+const (
+	authorizePath   = "/oauth/authorize"
+	accessTokenPath = "/oauth/access_token"
+	revokePath      = "/sync/v9/access_tokens/revoke"
+)
+
+func (s *Server) OAuthConfig(scopes ...string) *oauth.Config {
+	return &oauth.Config{
+		ClientId:     s.ClientId,
+		ClientSecret: s.ClientSecret,
+		Scopes:       scopes,
+		AuthorizeUrl: s.URL + authorizePath,
+		TokenUrl:     s.URL + accessTokenPath,
+		RevokeUrl:    s.URL + revokePath,
+		Client:       s.Client(),
+	}
+}
+
+// AuthorizationCode issues a code as if the user had approved the authorization request.
+func (s *Server) AuthorizationCode() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	code := randomString()
+	s.codes[code] = true
+
+	return code
+}
+
+func (s *Server) authorized(r *http.Request) bool {
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.Token == "" || token == s.Token || s.tokens[token]
+}
+
+func (s *Server) serveAuthorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if query.Get("client_id") != s.ClientId {
+		writeError(w, http.StatusBadRequest, "invalid_client")
+		return
+	}
+
+	if query.Get("scope") == "" {
+		writeError(w, http.StatusBadRequest, "invalid_scope")
+		return
+	}
+
+	code := s.AuthorizationCode()
+
+	if s.RedirectUrl == "" {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]string{"code": code, "state": query.Get("state")})
+		return
+	}
+
+	redirect := url.Values{}
+	redirect.Set("code", code)
+	redirect.Set("state", query.Get("state"))
+	http.Redirect(w, r, s.RedirectUrl+"?"+redirect.Encode(), http.StatusFound)
+}
+
+func (s *Server) serveAccessToken(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || r.ParseForm() != nil {
+		writeOAuthError(w, "invalid_request")
+		return
+	}
+
+	if r.PostForm.Get("client_id") != s.ClientId || r.PostForm.Get("client_secret") != s.ClientSecret {
+		writeOAuthError(w, "bad_client_credentials")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	code := r.PostForm.Get("code")
+	if !s.codes[code] {
+		writeOAuthError(w, "bad_authorization_code")
+		return
+	}
+	delete(s.codes, code)
+
+	token := &oauth.Token{
+		AccessToken: randomString(),
+		TokenType:   "Bearer",
+	}
+	s.tokens[token.AccessToken] = true
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(token)
+}
+
+func (s *Server) serveRevoke(w http.ResponseWriter, r *http.Request) {
+	var params struct {
+		ClientId     string `json:"client_id"`
+		ClientSecret string `json:"client_secret"`
+		AccessToken  string `json:"access_token"`
+	}
+
+	if r.Method != http.MethodPost || json.NewDecoder(r.Body).Decode(&params) != nil {
+		writeOAuthError(w, "invalid_request")
+		return
+	}
+
+	if params.ClientId != s.ClientId || params.ClientSecret != s.ClientSecret {
+		writeError(w, http.StatusForbidden, "Forbidden")
+		return
+	}
+
+	s.mu.Lock()
+	delete(s.tokens, params.AccessToken)
+	s.mu.Unlock()
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeOAuthError(w http.ResponseWriter, code string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": code})
+}
+
+func randomString() string {
+	buf := make([]byte, 20)
+	_, _ = rand.Read(buf)
+
+	return hex.EncodeToString(buf)
+}
@@ -0,0 +1,178 @@
+package todoisttest
+
+import (
+	"net/http"
+
+	todoist "github.com/temoon/todoist-api"
+)
+
+func (s *Server) routeProjects(req *request) (int, interface{}) {
+	switch {
+	case req.is(http.MethodGet, 1):
+		return s.getProjects()
+	case req.is(http.MethodPost, 1):
+		return s.addProject(req)
+	case req.is(http.MethodGet, 2):
+		return s.getProject(req.path[1])
+	case req.is(http.MethodPost, 2):
+		return s.updateProject(req.path[1], req)
+	case req.is(http.MethodDelete, 2):
+		return s.deleteProject(req.path[1])
+	case req.is(http.MethodGet, 3) && req.path[2] == "collaborators":
+		return s.getCollaborators(req.path[1])
+	default:
+		return http.StatusNotFound, "Not found"
+	}
+}
+
+func (s *Server) getProjects() (int, interface{}) {
+	projects := make([]todoist.Project, 0, len(s.projects))
+	for _, project := range s.projects {
+		projects = append(projects, *project)
+	}
+
+	return http.StatusOK, projects
+}
+
+func (s *Server) addProject(req *request) (int, interface{}) {
+	if req.string("name") == "" {
+		return missing("name")
+	}
+
+	parentId := req.string("parent_id")
+	if parentId != "" && s.project(parentId) == nil {
+		return notFound("Parent project")
+	}
+
+	project := &todoist.Project{
+		Id:         s.newId(),
+		Name:       req.string("name"),
+		Color:      req.string("color"),
+		ParentId:   parentId,
+		IsFavorite: req.bool("is_favorite"),
+	}
+	project.Url = projectUrl(project.Id)
+
+	if project.Color == "" {
+		project.Color = todoist.CharcoalColor
+	}
+
+	project.Order = 1
+	for _, sibling := range s.projects {
+		if sibling.ParentId == parentId && sibling.Order >= project.Order {
+			project.Order = sibling.Order + 1
+		}
+	}
+
+	s.projects = append(s.projects, project)
+
+	return http.StatusOK, *project
+}
+
+func (s *Server) getProject(id string) (int, interface{}) {
+	project := s.project(id)
+	if project == nil {
+		return notFound("Project")
+	}
+
+	return http.StatusOK, *project
+}
+
+func (s *Server) updateProject(id string, req *request) (int, interface{}) {
+	project := s.project(id)
+	if project == nil {
+		return notFound("Project")
+	}
+
+	if name := req.string("name"); name != "" {
+		if project.IsInboxProject {
+			return http.StatusBadRequest, "Inbox project can't be renamed"
+		}
+		project.Name = name
+	}
+
+	if color := req.string("color"); color != "" {
+		project.Color = color
+	}
+
+	if req.has("is_favorite") {
+		project.IsFavorite = req.bool("is_favorite")
+	}
+
+	return http.StatusOK, *project
+}
+
+func (s *Server) deleteProject(id string) (int, interface{}) {
+	project := s.project(id)
+	if project == nil {
+		return notFound("Project")
+	}
+
+	if project.IsInboxProject {
+		return http.StatusBadRequest, "Inbox project can't be deleted"
+	}
+
+	s.removeProject(id)
+
+	return http.StatusNoContent, nil
+}
+
+func (s *Server) getCollaborators(id string) (int, interface{}) {
+	if s.project(id) == nil {
+		return notFound("Project")
+	}
+
+	collaborators := make([]todoist.Collaborator, 0)
+	if len(s.collaborators[id]) != 0 {
+		collaborators = append(collaborators, s.User)
+		collaborators = append(collaborators, s.collaborators[id]...)
+	}
+
+	return http.StatusOK, collaborators
+}
+
+func (s *Server) project(id string) *todoist.Project {
+	for _, project := range s.projects {
+		if project.Id == id {
+			return project
+		}
+	}
+
+	return nil
+}
+
+func (s *Server) removeProject(id string) {
+	for _, child := range append([]*todoist.Project(nil), s.projects...) {
+		if child.ParentId == id {
+			s.removeProject(child.Id)
+		}
+	}
+
+	for _, section := range append([]*todoist.Section(nil), s.sections...) {
+		if section.ProjectId == id {
+			s.removeSection(section.Id)
+		}
+	}
+
+	for _, task := range append([]*todoist.Task(nil), s.tasks...) {
+		if task.ProjectId == id && task.ParentId == "" {
+			s.removeTask(task.Id)
+		}
+	}
+
+	for _, comment := range append([]*todoist.Comment(nil), s.comments...) {
+		if comment.ProjectId == id {
+			s.removeComment(comment.Id)
+		}
+	}
+
+	projects := s.projects[:0]
+	for _, project := range s.projects {
+		if project.Id != id {
+			projects = append(projects, project)
+		}
+	}
+	s.projects = projects
+
+	delete(s.collaborators, id)
+}
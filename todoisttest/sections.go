@@ -0,0 +1,129 @@
+package todoisttest
+
+import (
+	"net/http"
+
+	todoist "github.com/temoon/todoist-api"
+)
+
+func (s *Server) routeSections(req *request) (int, interface{}) {
+	switch {
+	case req.is(http.MethodGet, 1):
+		return s.getSections(req)
+	case req.is(http.MethodPost, 1):
+		return s.addSection(req)
+	case req.is(http.MethodGet, 2):
+		return s.getSection(req.path[1])
+	case req.is(http.MethodPost, 2):
+		return s.updateSection(req.path[1], req)
+	case req.is(http.MethodDelete, 2):
+		return s.deleteSection(req.path[1])
+	default:
+		return http.StatusNotFound, "Not found"
+	}
+}
+
+func (s *Server) getSections(req *request) (int, interface{}) {
+	projectId := req.queryValue("project_id")
+
+	sections := make([]todoist.Section, 0)
+	for _, section := range s.sections {
+		if projectId == "" || section.ProjectId == projectId {
+			sections = append(sections, *section)
+		}
+	}
+
+	return http.StatusOK, sections
+}
+
+func (s *Server) addSection(req *request) (int, interface{}) {
+	if req.string("name") == "" {
+		return missing("name")
+	}
+
+	if req.string("project_id") == "" {
+		return missing("project_id")
+	}
+
+	if s.project(req.string("project_id")) == nil {
+		return notFound("Project")
+	}
+
+	section := &todoist.Section{
+		Id:        s.newId(),
+		ProjectId: req.string("project_id"),
+		Order:     req.int("order"),
+		Name:      req.string("name"),
+	}
+
+	if section.Order == 0 {
+		section.Order = 1
+		for _, sibling := range s.sections {
+			if sibling.ProjectId == section.ProjectId && sibling.Order >= section.Order {
+				section.Order = sibling.Order + 1
+			}
+		}
+	}
+
+	s.sections = append(s.sections, section)
+
+	return http.StatusOK, *section
+}
+
+func (s *Server) getSection(id string) (int, interface{}) {
+	section := s.section(id)
+	if section == nil {
+		return notFound("Section")
+	}
+
+	return http.StatusOK, *section
+}
+
+func (s *Server) updateSection(id string, req *request) (int, interface{}) {
+	section := s.section(id)
+	if section == nil {
+		return notFound("Section")
+	}
+
+	if name := req.string("name"); name != "" {
+		section.Name = name
+	}
+
+	return http.StatusOK, *section
+}
+
+func (s *Server) deleteSection(id string) (int, interface{}) {
+	if s.section(id) == nil {
+		return notFound("Section")
+	}
+
+	s.removeSection(id)
+
+	return http.StatusNoContent, nil
+}
+
+func (s *Server) section(id string) *todoist.Section {
+	for _, section := range s.sections {
+		if section.Id == id {
+			return section
+		}
+	}
+
+	return nil
+}
+
+func (s *Server) removeSection(id string) {
+	for _, task := range append([]*todoist.Task(nil), s.tasks...) {
+		if task.SectionId == id && task.ParentId == "" {
+			s.removeTask(task.Id)
+		}
+	}
+
+	sections := s.sections[:0]
+	for _, section := range s.sections {
+		if section.Id != id {
+			sections = append(sections, section)
+		}
+	}
+	s.sections = sections
+}
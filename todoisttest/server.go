@@ -0,0 +1,281 @@
+// Package todoisttest provides a stateful in-memory fake of the Todoist REST and Sync APIs for tests.
+package todoisttest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	todoist "github.com/temoon/todoist-api"
+)
+
+const DefaultToken = "todoisttest"
+
+const (
+	restPrefix = "/rest/v2/"
+	syncPrefix = "/sync/v9/"
+)
+
+type Server struct {
+	*httptest.Server
+
+	Token        string
+	User         todoist.Collaborator
+	Now          func() time.Time
+	ClientId     string
+	ClientSecret string
+	RedirectUrl  string
+
+	mu            sync.Mutex
+	lastId        int
+	projects      []*todoist.Project
+	sections      []*todoist.Section
+	tasks         []*todoist.Task
+	labels        []*todoist.Label
+	comments      []*todoist.Comment
+	collaborators map[string][]todoist.Collaborator
+	codes         map[string]bool
+	tokens        map[string]bool
+	snapshots     map[string]snapshot
+	lastSyncToken int
+	tempIds       map[string]string
+}
+
+//goland:noinspection GoUnusedExportedFunction
+func NewServer() *Server {
+	s := &Server{
+		Token: DefaultToken,
+		User: todoist.Collaborator{
+			Id:    "1",
+			Name:  "Test User",
+			Email: "test@example.com",
+		},
+		Now:           time.Now,
+		ClientId:      DefaultClientId,
+		ClientSecret:  DefaultClientSecret,
+		lastId:        1,
+		collaborators: make(map[string][]todoist.Collaborator),
+		codes:         make(map[string]bool),
+		tokens:        make(map[string]bool),
+		snapshots:     make(map[string]snapshot),
+		tempIds:       make(map[string]string),
+	}
+
+	s.projects = append(s.projects, &todoist.Project{
+		Id:             s.newId(),
+		Name:           "Inbox",
+		Color:          todoist.GreyColor,
+		Order:          0,
+		IsInboxProject: true,
+	})
+	s.projects[0].Url = projectUrl(s.projects[0].Id)
+
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+
+	return s
+}
+
+func (s *Server) Opts() *todoist.Opts {
+	return &todoist.Opts{
+		Token:       s.Token,
+		BaseUrl:     s.URL + restPrefix,
+		SyncBaseUrl: s.URL + syncPrefix,
+		Client:      s.Client(),
+	}
+}
+
+func (s *Server) Todoist() *todoist.Todoist {
+	return todoist.New(s.Opts())
+}
+
+func (s *Server) InboxProjectId() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.projects[0].Id
+}
+
+func (s *Server) AddCollaborator(projectId string, collaborator todoist.Collaborator) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	project := s.project(projectId)
+	if project == nil {
+		return false
+	}
+
+	s.collaborators[projectId] = append(s.collaborators[projectId], collaborator)
+	project.IsShared = true
+
+	return true
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case authorizePath:
+		s.serveAuthorize(w, r)
+		return
+	case accessTokenPath:
+		s.serveAccessToken(w, r)
+		return
+	case revokePath:
+		s.serveRevoke(w, r)
+		return
+	}
+
+	if !s.authorized(r) {
+		writeError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	if r.URL.Path == syncPath {
+		s.serveSync(w, r)
+		return
+	}
+
+	if !strings.HasPrefix(r.URL.Path, restPrefix) {
+		writeError(w, http.StatusNotFound, "Not found")
+		return
+	}
+
+	var params map[string]interface{}
+	if r.Method == http.MethodPost && r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid JSON")
+			return
+		}
+	}
+	if params == nil {
+		params = make(map[string]interface{})
+	}
+
+	req := &request{
+		method: r.Method,
+		path:   strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, restPrefix), "/"), "/"),
+		query:  r.URL.Query(),
+		params: params,
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch status, data := s.route(req); {
+	case status == http.StatusNoContent:
+		w.WriteHeader(status)
+	case status >= 400:
+		writeError(w, status, data.(string))
+	default:
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(data)
+	}
+}
+
+func (s *Server) route(req *request) (int, interface{}) {
+	switch req.path[0] {
+	case todoist.ProjectsEndpoint:
+		return s.routeProjects(req)
+	case todoist.SectionsEndpoint:
+		return s.routeSections(req)
+	case todoist.TasksEndpoint:
+		return s.routeTasks(req)
+	case todoist.LabelsEndpoint:
+		return s.routeLabels(req)
+	case todoist.CommentsEndpoint:
+		return s.routeComments(req)
+	default:
+		return http.StatusNotFound, "Not found"
+	}
+}
+
+func (s *Server) newId() string {
+	s.lastId++
+	return strconv.Itoa(s.lastId)
+}
+
+func (s *Server) timestamp() string {
+	return s.Now().UTC().Format("2006-01-02T15:04:05.000000Z")
+}
+
+type request struct {
+	method string
+	path   []string
+	query  map[string][]string
+	params map[string]interface{}
+}
+
+func (r *request) is(method string, segments int) bool {
+	return r.method == method && len(r.path) == segments
+}
+
+func (r *request) queryValue(key string) string {
+	if values := r.query[key]; len(values) != 0 {
+		return values[0]
+	}
+
+	return ""
+}
+
+func (r *request) has(key string) bool {
+	_, ok := r.params[key]
+	return ok
+}
+
+func (r *request) string(key string) string {
+	value, _ := r.params[key].(string)
+	return value
+}
+
+func (r *request) int(key string) int {
+	switch value := r.params[key].(type) {
+	case float64:
+		return int(value)
+	case string:
+		i, _ := strconv.Atoi(value)
+		return i
+	default:
+		return 0
+	}
+}
+
+func (r *request) bool(key string) bool {
+	value, _ := r.params[key].(bool)
+	return value
+}
+
+func (r *request) strings(key string) (values []string) {
+	items, _ := r.params[key].([]interface{})
+	for _, item := range items {
+		if value, ok := item.(string); ok {
+			values = append(values, value)
+		}
+	}
+
+	return
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(status)
+	_, _ = w.Write([]byte(message))
+}
+
+func missing(argument string) (int, interface{}) {
+	return http.StatusBadRequest, "Required argument is missing: " + argument
+}
+
+func notFound(resource string) (int, interface{}) {
+	return http.StatusNotFound, resource + " not found"
+}
+
+func projectUrl(id string) string {
+	return "https://todoist.com/showProject?id=" + id
+}
+
+func taskUrl(id string) string {
+	return "https://todoist.com/showTask?id=" + id
+}
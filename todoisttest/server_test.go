@@ -0,0 +1,247 @@
+package todoisttest_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	todoist "github.com/temoon/todoist-api"
+	"github.com/temoon/todoist-api/todoisttest"
+)
+
+func TestIds(t *testing.T) {
+	srv := todoisttest.NewServer()
+	defer srv.Close()
+
+	client := srv.Todoist()
+	ctx := context.Background()
+
+	project, err := client.AddProject(ctx, todoist.MakeAddProjectParams().WithName("Work"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	section, err := client.AddSection(ctx, todoist.MakeAddSectionParams().WithName("Drafts").WithProjectId(project.Id))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var tasks []string
+	for _, content := range []string{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j"} {
+		task, err := client.AddTask(ctx, todoist.MakeAddTaskParams().WithContent(content).WithSectionId(section.Id))
+		if err != nil {
+			t.Fatal(err)
+		}
+		tasks = append(tasks, task.Id)
+	}
+
+	// Ids are shared by every kind of object and count up from the inbox.
+	want := []string{"3", "4", "5", "6", "7", "8", "9", "10", "11", "12", "13", "14"}
+	got := append([]string{project.Id, section.Id}, tasks...)
+	if srv.InboxProjectId() != "2" || !equalStrings(got, want) {
+		t.Fatalf("inbox %s and ids %v, want 2 and %v", srv.InboxProjectId(), got, want)
+	}
+
+	listed, err := client.GetTasks(ctx, todoist.MakeGetTasksParams().WithProjectId(project.Id))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var ids []string
+	for i, task := range listed {
+		ids = append(ids, task.Id)
+		if task.ProjectId != project.Id || task.Order != i+1 {
+			t.Errorf("task %s = project %s order %d, want %s and %d", task.Id, task.ProjectId, task.Order, project.Id, i+1)
+		}
+	}
+	if !equalStrings(ids, tasks) {
+		t.Errorf("GetTasks() = %v, want %v", ids, tasks)
+	}
+}
+
+func TestCloseReopen(t *testing.T) {
+	srv := todoisttest.NewServer()
+	defer srv.Close()
+
+	client := srv.Todoist()
+	ctx := context.Background()
+
+	parent, err := client.AddTask(ctx, todoist.MakeAddTaskParams().WithContent("Report"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	child, err := client.AddTask(ctx, todoist.MakeAddTaskParams().WithContent("Numbers").WithParentId(parent.Id))
+	if err != nil {
+		t.Fatal(err)
+	}
+	recurring, err := client.AddTask(ctx, todoist.MakeAddTaskParams().WithContent("Standup").WithDueString("every day"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = client.CloseTask(ctx, parent.Id); err != nil {
+		t.Fatalf("CloseTask() error = %v", err)
+	}
+	if err = client.CloseTask(ctx, recurring.Id); err != nil {
+		t.Fatalf("CloseTask() error = %v", err)
+	}
+
+	// Closing a task closes its subtasks, and a recurring task stays open for its next date.
+	assertOpen(t, client, map[string]bool{parent.Id: false, child.Id: false, recurring.Id: true})
+
+	// Reopening a subtask reopens its parents too.
+	if err = client.ReopenTask(ctx, child.Id); err != nil {
+		t.Fatalf("ReopenTask() error = %v", err)
+	}
+	assertOpen(t, client, map[string]bool{parent.Id: true, child.Id: true, recurring.Id: true})
+
+	if err = client.CloseTask(ctx, "404"); !errors.Is(err, todoist.ErrNotFound) {
+		t.Errorf("CloseTask() of a missing task error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestCascadeDelete(t *testing.T) {
+	srv := todoisttest.NewServer()
+	defer srv.Close()
+
+	client := srv.Todoist()
+	ctx := context.Background()
+
+	work, err := client.AddProject(ctx, todoist.MakeAddProjectParams().WithName("Work"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	office, err := client.AddProject(ctx, todoist.MakeAddProjectParams().WithName("Office").WithParentId(work.Id))
+	if err != nil {
+		t.Fatal(err)
+	}
+	drafts, err := client.AddSection(ctx, todoist.MakeAddSectionParams().WithName("Drafts").WithProjectId(office.Id))
+	if err != nil {
+		t.Fatal(err)
+	}
+	done, err := client.AddSection(ctx, todoist.MakeAddSectionParams().WithName("Done").WithProjectId(office.Id))
+	if err != nil {
+		t.Fatal(err)
+	}
+	draft, err := client.AddTask(ctx, todoist.MakeAddTaskParams().WithContent("Draft").WithSectionId(drafts.Id))
+	if err != nil {
+		t.Fatal(err)
+	}
+	subtask, err := client.AddTask(ctx, todoist.MakeAddTaskParams().WithContent("Outline").WithParentId(draft.Id))
+	if err != nil {
+		t.Fatal(err)
+	}
+	finished, err := client.AddTask(ctx, todoist.MakeAddTaskParams().WithContent("Finished").WithSectionId(done.Id))
+	if err != nil {
+		t.Fatal(err)
+	}
+	comment, err := client.AddComment(ctx, todoist.MakeAddCommentParams().WithTaskId(finished.Id).WithContent("Shipped"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	note, err := client.AddComment(ctx, todoist.MakeAddCommentParams().WithProjectId(work.Id).WithContent("Goals"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Deleting a section deletes its tasks with their subtasks, but nothing else.
+	if err = client.DeleteSection(ctx, drafts.Id); err != nil {
+		t.Fatalf("DeleteSection() error = %v", err)
+	}
+	for _, id := range []string{draft.Id, subtask.Id} {
+		if _, err = client.GetTask(ctx, id); !errors.Is(err, todoist.ErrNotFound) {
+			t.Errorf("GetTask(%s) after DeleteSection() error = %v, want ErrNotFound", id, err)
+		}
+	}
+	if _, err = client.GetTask(ctx, finished.Id); err != nil {
+		t.Errorf("GetTask(%s) of another section error = %v", finished.Id, err)
+	}
+
+	// Deleting a project deletes its subprojects and everything in them.
+	if err = client.DeleteProject(ctx, work.Id); err != nil {
+		t.Fatalf("DeleteProject() error = %v", err)
+	}
+	if _, err = client.GetProject(ctx, office.Id); !errors.Is(err, todoist.ErrNotFound) {
+		t.Errorf("GetProject() of a subproject error = %v, want ErrNotFound", err)
+	}
+	if _, err = client.GetSection(ctx, done.Id); !errors.Is(err, todoist.ErrNotFound) {
+		t.Errorf("GetSection() error = %v, want ErrNotFound", err)
+	}
+	if _, err = client.GetTask(ctx, finished.Id); !errors.Is(err, todoist.ErrNotFound) {
+		t.Errorf("GetTask() error = %v, want ErrNotFound", err)
+	}
+	for _, id := range []string{comment.Id, note.Id} {
+		if _, err = client.GetComment(ctx, id); !errors.Is(err, todoist.ErrNotFound) {
+			t.Errorf("GetComment(%s) error = %v, want ErrNotFound", id, err)
+		}
+	}
+
+	projects, err := client.GetProjects(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(projects) != 1 || !projects[0].IsInboxProject {
+		t.Errorf("GetProjects() = %+v, want only the inbox", projects)
+	}
+
+	if err = client.DeleteProject(ctx, srv.InboxProjectId()); err == nil {
+		t.Errorf("DeleteProject() of the inbox succeeded")
+	}
+}
+
+func TestErrors(t *testing.T) {
+	srv := todoisttest.NewServer()
+	defer srv.Close()
+
+	if _, err := srv.Todoist().GetTask(context.Background(), "404404"); !errors.Is(err, todoist.ErrNotFound) {
+		t.Errorf("GetTask() error = %v, want ErrNotFound", err)
+	}
+
+	opts := srv.Opts()
+	opts.Token = "wrong"
+	if _, err := todoist.New(opts).GetProjects(context.Background()); !errors.Is(err, todoist.ErrUnauthorized) {
+		t.Errorf("GetProjects() error = %v, want ErrUnauthorized", err)
+	}
+}
+
+func assertOpen(t *testing.T, client *todoist.Todoist, want map[string]bool) {
+	t.Helper()
+
+	for id, open := range want {
+		task, err := client.GetTask(context.Background(), id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if task.IsCompleted == open {
+			t.Errorf("task %s IsCompleted = %t, want %t", id, task.IsCompleted, !open)
+		}
+	}
+
+	tasks, err := client.GetTasks(context.Background(), todoist.MakeGetTasksParams())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	listed := make(map[string]bool)
+	for _, task := range tasks {
+		listed[task.Id] = true
+	}
+	for id, open := range want {
+		if listed[id] != open {
+			t.Errorf("GetTasks() lists %s = %t, want %t", id, listed[id], open)
+		}
+	}
+}
+
+func equalStrings(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,260 @@
+package todoisttest
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+
+	todoist "github.com/temoon/todoist-api"
+	"github.com/temoon/todoist-api/internal/ordering"
+)
+
+const syncPath = syncPrefix + todoist.SyncEndpoint
+
+// snapshot holds the encoded sync representation of every object, by resource type and id.
+// Incremental syncs are computed by diffing the current state against the snapshot
+// that was taken when the sync token was issued.
+type snapshot map[string]map[string][]byte
+
+var syncResources = []string{
+	todoist.ProjectsResource,
+	todoist.ItemsResource,
+	todoist.SectionsResource,
+	todoist.LabelsResource,
+	todoist.NotesResource,
+	todoist.ProjectNotesResource,
+}
+
+func (s *Server) serveSync(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || r.ParseForm() != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request")
+		return
+	}
+
+	resourceTypes := make(map[string]bool)
+	if value := r.PostForm.Get("resource_types"); value != "" {
+		var types []string
+		if err := json.Unmarshal([]byte(value), &types); err != nil {
+			writeSyncError(w, http.StatusBadRequest, "INVALID_ARGUMENT_VALUE", "Invalid argument value")
+			return
+		}
+
+		for _, resourceType := range types {
+			resourceTypes[resourceType] = true
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	res := map[string]interface{}{
+		"temp_id_mapping": map[string]string{},
+		"sync_status":     map[string]interface{}{},
+	}
+
+	if value := r.PostForm.Get("commands"); value != "" {
+		if !s.runCommands(value, res) {
+			writeSyncError(w, http.StatusBadRequest, "INVALID_ARGUMENT_VALUE", "Invalid argument value")
+			return
+		}
+	}
+
+	current := s.snapshot()
+	previous, incremental := s.snapshots[r.PostForm.Get("sync_token")]
+
+	for _, resource := range syncResources {
+		if !resourceTypes[todoist.AllResources] && !resourceTypes[resource] {
+			continue
+		}
+
+		objects := make([]json.RawMessage, 0)
+		for _, id := range sortedIds(current[resource]) {
+			object := current[resource][id]
+			switch {
+			case incremental && bytes.Equal(previous[resource][id], object):
+			case !incremental && resource == todoist.ItemsResource && bytes.Contains(object, []byte(`"checked":true`)):
+			default:
+				objects = append(objects, object)
+			}
+		}
+
+		if incremental {
+			for _, id := range sortedIds(previous[resource]) {
+				if _, ok := current[resource][id]; !ok {
+					objects = append(objects, tombstone(previous[resource][id]))
+				}
+			}
+		}
+
+		res[resource] = objects
+	}
+
+	s.lastSyncToken++
+	token := "todoisttest-" + strconv.Itoa(s.lastSyncToken)
+	s.snapshots[token] = current
+
+	res["sync_token"] = token
+	res["full_sync"] = !incremental
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(res)
+}
+
+func (s *Server) snapshot() snapshot {
+	snap := make(snapshot)
+	for _, resource := range syncResources {
+		snap[resource] = make(map[string][]byte)
+	}
+
+	for _, project := range s.projects {
+		snap[todoist.ProjectsResource][project.Id] = encode(syncProject(project))
+	}
+
+	for _, task := range s.tasks {
+		snap[todoist.ItemsResource][task.Id] = encode(s.syncItem(task))
+	}
+
+	for _, section := range s.sections {
+		snap[todoist.SectionsResource][section.Id] = encode(syncSection(section))
+	}
+
+	for _, label := range s.labels {
+		snap[todoist.LabelsResource][label.Id] = encode(syncLabel(label))
+	}
+
+	for _, comment := range s.comments {
+		if comment.TaskId != "" {
+			snap[todoist.NotesResource][comment.Id] = encode(s.syncNote(comment))
+		} else {
+			snap[todoist.ProjectNotesResource][comment.Id] = encode(s.syncNote(comment))
+		}
+	}
+
+	return snap
+}
+
+func syncProject(project *todoist.Project) map[string]interface{} {
+	return map[string]interface{}{
+		"id":            project.Id,
+		"name":          project.Name,
+		"color":         project.Color,
+		"parent_id":     nullable(project.ParentId),
+		"child_order":   project.Order,
+		"collapsed":     false,
+		"shared":        project.IsShared,
+		"is_favorite":   project.IsFavorite,
+		"inbox_project": project.IsInboxProject,
+		"team_inbox":    project.IsTeamInbox,
+		"view_style":    "list",
+		"is_deleted":    false,
+		"is_archived":   false,
+	}
+}
+
+func (s *Server) syncItem(task *todoist.Task) map[string]interface{} {
+	return map[string]interface{}{
+		"id":              task.Id,
+		"user_id":         s.User.Id,
+		"project_id":      task.ProjectId,
+		"section_id":      nullable(task.SectionId),
+		"parent_id":       nullable(task.ParentId),
+		"content":         task.Content,
+		"description":     task.Description,
+		"priority":        task.Priority,
+		"due":             task.Due,
+		"child_order":     task.Order,
+		"collapsed":       false,
+		"labels":          task.Labels,
+		"added_by_uid":    s.User.Id,
+		"assigned_by_uid": nullable(task.AssignerId),
+		"responsible_uid": nullable(task.AssigneeId),
+		"checked":         task.IsCompleted,
+		"is_deleted":      false,
+	}
+}
+
+func syncSection(section *todoist.Section) map[string]interface{} {
+	return map[string]interface{}{
+		"id":            section.Id,
+		"name":          section.Name,
+		"project_id":    section.ProjectId,
+		"section_order": section.Order,
+		"collapsed":     false,
+		"is_deleted":    false,
+		"is_archived":   false,
+	}
+}
+
+func syncLabel(label *todoist.Label) map[string]interface{} {
+	return map[string]interface{}{
+		"id":          label.Id,
+		"name":        label.Name,
+		"color":       label.Color,
+		"item_order":  label.Order,
+		"is_favorite": label.IsFavorite,
+		"is_deleted":  false,
+	}
+}
+
+func (s *Server) syncNote(comment *todoist.Comment) map[string]interface{} {
+	note := map[string]interface{}{
+		"id":              comment.Id,
+		"posted_uid":      s.User.Id,
+		"content":         comment.Content,
+		"file_attachment": comment.Attachment,
+		"posted_at":       comment.PostedAt,
+		"is_deleted":      false,
+	}
+
+	if comment.TaskId != "" {
+		note["item_id"] = comment.TaskId
+	} else {
+		note["project_id"] = comment.ProjectId
+	}
+
+	return note
+}
+
+func tombstone(object []byte) json.RawMessage {
+	var data map[string]interface{}
+	_ = json.Unmarshal(object, &data)
+	data["is_deleted"] = true
+
+	return encode(data)
+}
+
+func encode(data interface{}) []byte {
+	encoded, _ := json.Marshal(data)
+	return encoded
+}
+
+func nullable(value string) interface{} {
+	if value == "" {
+		return nil
+	}
+
+	return value
+}
+
+func sortedIds(objects map[string][]byte) []string {
+	ids := make([]string, 0, len(objects))
+	for id := range objects {
+		ids = append(ids, id)
+	}
+
+	sort.Slice(ids, func(i, j int) bool { return ordering.LessId(ids[i], ids[j]) })
+
+	return ids
+}
+
+func writeSyncError(w http.ResponseWriter, status int, tag string, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"error":     message,
+		"error_tag": tag,
+		"http_code": status,
+	})
+}
@@ -0,0 +1,72 @@
+package todoisttest_test
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	todoist "github.com/temoon/todoist-api"
+	"github.com/temoon/todoist-api/todoisttest"
+)
+
+func TestSyncIdOrder(t *testing.T) {
+	srv := todoisttest.NewServer()
+	defer srv.Close()
+
+	client := srv.Todoist()
+	ctx := context.Background()
+
+	var ids []string
+	for i := 0; i < 11; i++ {
+		task, err := client.AddTask(ctx, todoist.MakeAddTaskParams().WithContent("Task "+strconv.Itoa(i)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, task.Id)
+	}
+
+	sync := todoist.NewSyncClient(client, todoist.ItemsResource)
+	res, err := sync.Fetch(ctx)
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+
+	// Ids are ordered by their numeric value, so "10" comes after "9".
+	if got := syncTaskIds(res.Tasks); !equalStrings(got, ids) {
+		t.Errorf("full sync = %v, want %v", got, ids)
+	}
+	if len(res.Projects) != 0 {
+		t.Errorf("full sync of items = %d projects, want none", len(res.Projects))
+	}
+
+	for _, id := range []string{ids[10], ids[7]} {
+		if err = client.DeleteTask(ctx, id); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err = client.CloseTask(ctx, ids[0]); err != nil {
+		t.Fatal(err)
+	}
+
+	if res, err = sync.Fetch(ctx); err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+
+	// Changed objects come first and deleted ones after them, each in id order.
+	want := []string{ids[0], ids[7], ids[10]}
+	if got := syncTaskIds(res.Tasks); !equalStrings(got, want) {
+		t.Errorf("incremental sync = %v, want %v", got, want)
+	}
+	if len(res.Tasks) == 3 && (!res.Tasks[0].IsCompleted || res.Tasks[0].IsDeleted || !res.Tasks[1].IsDeleted || !res.Tasks[2].IsDeleted) {
+		t.Errorf("incremental sync = %+v, want a completed task and two deleted ones", res.Tasks)
+	}
+}
+
+func syncTaskIds(tasks []todoist.SyncTask) (ids []string) {
+	for _, task := range tasks {
+		ids = append(ids, task.Id)
+	}
+
+	return
+}
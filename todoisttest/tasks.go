@@ -0,0 +1,356 @@
+package todoisttest
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	todoist "github.com/temoon/todoist-api"
+)
+
+func (s *Server) routeTasks(req *request) (int, interface{}) {
+	switch {
+	case req.is(http.MethodGet, 1):
+		return s.getTasks(req)
+	case req.is(http.MethodPost, 1):
+		return s.addTask(req)
+	case req.is(http.MethodGet, 2):
+		return s.getTask(req.path[1])
+	case req.is(http.MethodPost, 2):
+		return s.updateTask(req.path[1], req)
+	case req.is(http.MethodDelete, 2):
+		return s.deleteTask(req.path[1])
+	case req.is(http.MethodPost, 3) && req.path[2] == "close":
+		return s.closeTask(req.path[1])
+	case req.is(http.MethodPost, 3) && req.path[2] == "reopen":
+		return s.reopenTask(req.path[1])
+	default:
+		return http.StatusNotFound, "Not found"
+	}
+}
+
+func (s *Server) getTasks(req *request) (int, interface{}) {
+	var ids map[string]bool
+	if value := req.queryValue("ids"); value != "" {
+		ids = make(map[string]bool)
+		for _, id := range strings.Split(value, ",") {
+			ids[strings.TrimSpace(id)] = true
+		}
+	}
+
+	var match func(*todoist.Task) bool
+	if filter := req.queryValue("filter"); filter != "" {
+		var ok bool
+		if match, ok = s.compileFilter(filter); !ok {
+			return http.StatusBadRequest, "Invalid filter: " + filter
+		}
+	}
+
+	projectId := req.queryValue("project_id")
+	sectionId := req.queryValue("section_id")
+	label := req.queryValue("label")
+
+	tasks := make([]todoist.Task, 0)
+	for _, task := range s.tasks {
+		switch {
+		case task.IsCompleted:
+		case projectId != "" && task.ProjectId != projectId:
+		case sectionId != "" && task.SectionId != sectionId:
+		case label != "" && !hasLabel(task, label):
+		case ids != nil && !ids[task.Id]:
+		case match != nil && !match(task):
+		default:
+			tasks = append(tasks, *task)
+		}
+	}
+
+	return http.StatusOK, tasks
+}
+
+func (s *Server) addTask(req *request) (int, interface{}) {
+	if req.string("content") == "" {
+		return missing("content")
+	}
+
+	task := &todoist.Task{
+		Id:          s.newId(),
+		ProjectId:   req.string("project_id"),
+		SectionId:   req.string("section_id"),
+		Content:     req.string("content"),
+		Description: req.string("description"),
+		Labels:      req.strings("labels"),
+		ParentId:    req.string("parent_id"),
+		Order:       req.int("order"),
+		Priority:    req.int("priority"),
+		AssigneeId:  req.string("assignee_id"),
+	}
+	task.Url = taskUrl(task.Id)
+
+	if task.Labels == nil {
+		task.Labels = make([]string, 0)
+	}
+
+	if task.ParentId != "" {
+		parent := s.task(task.ParentId)
+		if parent == nil {
+			return notFound("Parent task")
+		}
+		task.ProjectId = parent.ProjectId
+		task.SectionId = parent.SectionId
+	}
+
+	if task.SectionId != "" {
+		section := s.section(task.SectionId)
+		if section == nil {
+			return notFound("Section")
+		}
+		if task.ProjectId != "" && task.ProjectId != section.ProjectId {
+			return http.StatusBadRequest, "Section does not belong to project"
+		}
+		task.ProjectId = section.ProjectId
+	}
+
+	if task.ProjectId == "" {
+		task.ProjectId = s.projects[0].Id
+	} else if s.project(task.ProjectId) == nil {
+		return notFound("Project")
+	}
+
+	if task.Priority == 0 {
+		task.Priority = 1
+	} else if task.Priority < 1 || task.Priority > 4 {
+		return http.StatusBadRequest, "Invalid argument value: priority"
+	}
+
+	if due, ok, valid := s.parseDue(req); !valid {
+		return http.StatusBadRequest, "Date is in invalid format"
+	} else if ok {
+		task.Due = due
+	}
+
+	if task.AssigneeId != "" {
+		task.AssignerId = s.User.Id
+	}
+
+	if task.Order == 0 {
+		task.Order = 1
+		for _, sibling := range s.tasks {
+			if isSibling(sibling, task) && sibling.Order >= task.Order {
+				task.Order = sibling.Order + 1
+			}
+		}
+	}
+
+	s.tasks = append(s.tasks, task)
+
+	return http.StatusOK, *task
+}
+
+func (s *Server) getTask(id string) (int, interface{}) {
+	task := s.task(id)
+	if task == nil {
+		return notFound("Task")
+	}
+
+	return http.StatusOK, *task
+}
+
+func (s *Server) updateTask(id string, req *request) (int, interface{}) {
+	task := s.task(id)
+	if task == nil {
+		return notFound("Task")
+	}
+
+	if content := req.string("content"); content != "" {
+		task.Content = content
+	}
+
+	if req.has("description") {
+		task.Description = req.string("description")
+	}
+
+	for _, key := range []string{"labels", "label_ids"} {
+		if req.has(key) {
+			task.Labels = req.strings(key)
+			if task.Labels == nil {
+				task.Labels = make([]string, 0)
+			}
+		}
+	}
+
+	if req.has("priority") {
+		priority := req.int("priority")
+		if priority < 1 || priority > 4 {
+			return http.StatusBadRequest, "Invalid argument value: priority"
+		}
+		task.Priority = priority
+	}
+
+	if due, ok, valid := s.parseDue(req); !valid {
+		return http.StatusBadRequest, "Date is in invalid format"
+	} else if ok {
+		task.Due = due
+	}
+
+	if req.has("assignee_id") {
+		task.AssigneeId = req.string("assignee_id")
+		task.AssignerId = ""
+		if task.AssigneeId != "" {
+			task.AssignerId = s.User.Id
+		}
+	}
+
+	return http.StatusOK, *task
+}
+
+func (s *Server) closeTask(id string) (int, interface{}) {
+	task := s.task(id)
+	if task == nil {
+		return notFound("Task")
+	}
+
+	if task.Due != nil && task.Due.IsRecurring {
+		return http.StatusNoContent, nil
+	}
+
+	s.completeTask(task)
+
+	return http.StatusNoContent, nil
+}
+
+func (s *Server) reopenTask(id string) (int, interface{}) {
+	task := s.task(id)
+	if task == nil {
+		return notFound("Task")
+	}
+
+	for task != nil {
+		task.IsCompleted = false
+		task = s.task(task.ParentId)
+	}
+
+	return http.StatusNoContent, nil
+}
+
+func (s *Server) deleteTask(id string) (int, interface{}) {
+	if s.task(id) == nil {
+		return notFound("Task")
+	}
+
+	s.removeTask(id)
+
+	return http.StatusNoContent, nil
+}
+
+func (s *Server) task(id string) *todoist.Task {
+	if id == "" {
+		return nil
+	}
+
+	for _, task := range s.tasks {
+		if task.Id == id {
+			return task
+		}
+	}
+
+	return nil
+}
+
+func (s *Server) completeTask(task *todoist.Task) {
+	task.IsCompleted = true
+
+	for _, child := range s.tasks {
+		if child.ParentId == task.Id {
+			s.completeTask(child)
+		}
+	}
+}
+
+func (s *Server) removeTask(id string) {
+	for _, child := range append([]*todoist.Task(nil), s.tasks...) {
+		if child.ParentId == id {
+			s.removeTask(child.Id)
+		}
+	}
+
+	for _, comment := range append([]*todoist.Comment(nil), s.comments...) {
+		if comment.TaskId == id {
+			s.removeComment(comment.Id)
+		}
+	}
+
+	tasks := s.tasks[:0]
+	for _, task := range s.tasks {
+		if task.Id != id {
+			tasks = append(tasks, task)
+		}
+	}
+	s.tasks = tasks
+}
+
+// parseDue understands the subset of due strings that doesn't need a natural language parser:
+// dates, "today", "tomorrow", "no date" and recurring strings, which are anchored at today.
+func (s *Server) parseDue(req *request) (due *todoist.Due, ok bool, valid bool) {
+	now := s.Now()
+
+	switch {
+	case req.has("due_datetime"):
+		datetime, err := time.Parse(time.RFC3339, req.string("due_datetime"))
+		if err != nil {
+			return due, false, false
+		}
+
+		due = &todoist.Due{
+			String:   datetime.In(now.Location()).Format("2006-01-02 15:04"),
+			Date:     datetime.In(now.Location()).Format("2006-01-02"),
+			Datetime: datetime.UTC().Format("2006-01-02T15:04:05Z"),
+		}
+	case req.has("due_date"):
+		date, err := time.Parse("2006-01-02", req.string("due_date"))
+		if err != nil {
+			return due, false, false
+		}
+
+		due = &todoist.Due{
+			String: date.Format("2006-01-02"),
+			Date:   date.Format("2006-01-02"),
+		}
+	case req.has("due_string"):
+		value := strings.TrimSpace(req.string("due_string"))
+		due = &todoist.Due{
+			String: value,
+			Date:   now.Format("2006-01-02"),
+		}
+
+		switch lower := strings.ToLower(value); {
+		case lower == "no date" || lower == "no due date" || lower == "":
+			due = nil
+		case lower == "tomorrow":
+			due.Date = now.AddDate(0, 0, 1).Format("2006-01-02")
+		case strings.HasPrefix(lower, "every"):
+			due.IsRecurring = true
+		default:
+			if date, err := time.Parse("2006-01-02", value); err == nil {
+				due.Date = date.Format("2006-01-02")
+			}
+		}
+	default:
+		return due, false, true
+	}
+
+	return due, true, true
+}
+
+func isSibling(a *todoist.Task, b *todoist.Task) bool {
+	return a.ProjectId == b.ProjectId && a.SectionId == b.SectionId && a.ParentId == b.ParentId
+}
+
+func hasLabel(task *todoist.Task, label string) bool {
+	for _, name := range task.Labels {
+		if strings.EqualFold(name, label) {
+			return true
+		}
+	}
+
+	return false
+}
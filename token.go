@@ -0,0 +1,23 @@
+package todoist
+
+import (
+	"context"
+)
+
+// TokenSource is asked for a token before every attempt, so that it may rotate or
+// pick per-user credentials based on the context.
+type TokenSource interface {
+	Token(ctx context.Context) (string, error)
+}
+
+type StaticToken string
+
+func (s StaticToken) Token(_ context.Context) (string, error) {
+	return string(s), nil
+}
+
+type TokenSourceFunc func(ctx context.Context) (string, error)
+
+func (f TokenSourceFunc) Token(ctx context.Context) (string, error) {
+	return f(ctx)
+}
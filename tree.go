@@ -0,0 +1,478 @@
+package todoist
+
+import (
+	"sort"
+
+	"github.com/temoon/todoist-api/internal/ordering"
+)
+
+// forest links items to their parents by index. Items whose parent is missing are roots marked as orphans,
+// and so are the ones that would close a cycle of parents. Items are linked in the order of less, which
+// is also the order of roots and children.
+type forest struct {
+	parents  []int
+	children [][]int
+	roots    []int
+	orphans  []bool
+}
+
+func newForest(ids []string, parentIds []string, less func(i, j int) bool) *forest {
+	f := &forest{
+		parents:  make([]int, len(ids)),
+		children: make([][]int, len(ids)),
+		orphans:  make([]bool, len(ids)),
+	}
+
+	index := make(map[string]int, len(ids))
+	for i, id := range ids {
+		index[id] = i
+	}
+
+	sorted := make([]int, len(ids))
+	for i := range sorted {
+		sorted[i] = i
+	}
+	sort.SliceStable(sorted, func(a, b int) bool { return less(sorted[a], sorted[b]) })
+
+	for _, i := range sorted {
+		f.parents[i] = -1
+	}
+
+	for _, i := range sorted {
+		if parentIds[i] == "" {
+			f.roots = append(f.roots, i)
+			continue
+		}
+
+		parent, ok := index[parentIds[i]]
+		for p := parent; ok && p != -1; p = f.parents[p] {
+			ok = p != i
+		}
+
+		if !ok {
+			f.roots = append(f.roots, i)
+			f.orphans[i] = true
+			continue
+		}
+
+		f.parents[i] = parent
+		f.children[parent] = append(f.children[parent], i)
+	}
+
+	return f
+}
+
+// region TaskTree
+
+type TaskNode struct {
+	Task     Task
+	Parent   *TaskNode
+	Children []*TaskNode
+	// Depth is 0 for top level tasks.
+	Depth int
+	// Orphan is set on subtasks whose parent is not in the tree, like when it was filtered out.
+	// They are placed at the top level.
+	Orphan bool
+}
+
+// TaskTree is the hierarchy of a flat list of tasks. Siblings are sorted by order, then id.
+// With sections, top level tasks are grouped by section: tasks without one first, then the
+// sections in their order.
+type TaskTree struct {
+	roots    []*TaskNode
+	nodes    map[string]*TaskNode
+	sections []Section
+}
+
+//goland:noinspection GoUnusedExportedFunction
+func NewTaskTree(tasks []Task, sections []Section) *TaskTree {
+	t := &TaskTree{
+		nodes:    make(map[string]*TaskNode, len(tasks)),
+		sections: append([]Section(nil), sections...),
+	}
+
+	sort.SliceStable(t.sections, func(i, j int) bool {
+		return ordering.LessOrder(t.sections[i].Order, t.sections[i].Id, t.sections[j].Order, t.sections[j].Id)
+	})
+
+	ranks := make(map[string]int, len(t.sections))
+	for i, section := range t.sections {
+		ranks[section.Id] = i + 1
+	}
+
+	ids, parentIds := make([]string, len(tasks)), make([]string, len(tasks))
+	for i, task := range tasks {
+		ids[i], parentIds[i] = task.Id, task.ParentId
+	}
+
+	f := newForest(ids, parentIds, func(i, j int) bool {
+		if ranks[tasks[i].SectionId] != ranks[tasks[j].SectionId] {
+			return ranks[tasks[i].SectionId] < ranks[tasks[j].SectionId]
+		}
+		return ordering.LessOrder(tasks[i].Order, tasks[i].Id, tasks[j].Order, tasks[j].Id)
+	})
+
+	nodes := make([]*TaskNode, len(tasks))
+	for i, task := range tasks {
+		nodes[i] = &TaskNode{Task: task, Orphan: f.orphans[i]}
+		t.nodes[task.Id] = nodes[i]
+	}
+
+	var link func(node *TaskNode, i int, depth int)
+	link = func(node *TaskNode, i int, depth int) {
+		node.Depth = depth
+		for _, child := range f.children[i] {
+			nodes[child].Parent = node
+			node.Children = append(node.Children, nodes[child])
+			link(nodes[child], child, depth+1)
+		}
+	}
+
+	for _, i := range f.roots {
+		t.roots = append(t.roots, nodes[i])
+		link(nodes[i], i, 0)
+	}
+
+	return t
+}
+
+// Roots returns the top level tasks, including orphans.
+func (t *TaskTree) Roots() []*TaskNode {
+	return t.roots
+}
+
+// Sections returns the sections the tree was built with, in their order.
+func (t *TaskTree) Sections() []Section {
+	return t.sections
+}
+
+// SectionRoots returns the top level tasks of a section, or the ones without a section for an empty id.
+func (t *TaskTree) SectionRoots(sectionId string) (roots []*TaskNode) {
+	for _, node := range t.roots {
+		if node.Task.SectionId == sectionId {
+			roots = append(roots, node)
+		}
+	}
+
+	return
+}
+
+// Node returns nil when the task is not in the tree.
+func (t *TaskTree) Node(id string) *TaskNode {
+	return t.nodes[id]
+}
+
+func (t *TaskTree) Len() int {
+	return len(t.nodes)
+}
+
+// Walk visits the tasks depth-first, parents before their children. Returning false skips the children.
+func (t *TaskTree) Walk(fn func(node *TaskNode) bool) {
+	for _, node := range t.roots {
+		node.Walk(fn)
+	}
+}
+
+// Tasks returns the tasks in the order of Walk.
+func (t *TaskTree) Tasks() []Task {
+	tasks := make([]Task, 0, len(t.nodes))
+	t.Walk(func(node *TaskNode) bool {
+		tasks = append(tasks, node.Task)
+		return true
+	})
+
+	return tasks
+}
+
+// Ancestors returns the parents of a task, the closest first.
+func (t *TaskTree) Ancestors(id string) []*TaskNode {
+	if node := t.nodes[id]; node != nil {
+		return node.Ancestors()
+	}
+
+	return nil
+}
+
+// Descendants returns the subtasks of a task at all levels, in the order of Walk.
+func (t *TaskTree) Descendants(id string) []*TaskNode {
+	if node := t.nodes[id]; node != nil {
+		return node.Descendants()
+	}
+
+	return nil
+}
+
+// Orphans returns the tasks whose parent is not in the tree.
+func (t *TaskTree) Orphans() (orphans []*TaskNode) {
+	for _, node := range t.roots {
+		if node.Orphan {
+			orphans = append(orphans, node)
+		}
+	}
+
+	return
+}
+
+// Subtree returns the tree of a task and its subtasks, with the task at the top level.
+// It returns nil when the task is not in the tree.
+func (t *TaskTree) Subtree(id string) *TaskTree {
+	node := t.nodes[id]
+	if node == nil {
+		return nil
+	}
+
+	tasks := []Task{node.Task}
+	for _, descendant := range node.Descendants() {
+		tasks = append(tasks, descendant.Task)
+	}
+
+	subtree := NewTaskTree(tasks, t.sections)
+	subtree.nodes[id].Orphan = false
+
+	return subtree
+}
+
+// Filter returns the tree of the matching tasks and their ancestors, so that matches keep their place.
+func (t *TaskTree) Filter(match func(node *TaskNode) bool) *TaskTree {
+	keep := make(map[string]bool)
+	t.Walk(func(node *TaskNode) bool {
+		if match(node) {
+			for n := node; n != nil && !keep[n.Task.Id]; n = n.Parent {
+				keep[n.Task.Id] = true
+			}
+		}
+		return true
+	})
+
+	var tasks []Task
+	t.Walk(func(node *TaskNode) bool {
+		if keep[node.Task.Id] {
+			tasks = append(tasks, node.Task)
+		}
+		return true
+	})
+
+	return NewTaskTree(tasks, t.sections)
+}
+
+func (n *TaskNode) Walk(fn func(node *TaskNode) bool) {
+	if !fn(n) {
+		return
+	}
+
+	for _, child := range n.Children {
+		child.Walk(fn)
+	}
+}
+
+func (n *TaskNode) Ancestors() (ancestors []*TaskNode) {
+	for parent := n.Parent; parent != nil; parent = parent.Parent {
+		ancestors = append(ancestors, parent)
+	}
+
+	return
+}
+
+func (n *TaskNode) Descendants() (descendants []*TaskNode) {
+	for _, child := range n.Children {
+		child.Walk(func(node *TaskNode) bool {
+			descendants = append(descendants, node)
+			return true
+		})
+	}
+
+	return
+}
+
+// endregion
+
+// region ProjectTree
+
+type ProjectNode struct {
+	Project  Project
+	Parent   *ProjectNode
+	Children []*ProjectNode
+	// Depth is 0 for top level projects.
+	Depth int
+	// Orphan is set on subprojects whose parent is not in the tree. They are placed at the top level.
+	Orphan bool
+}
+
+// ProjectTree is the hierarchy of a flat list of projects. Siblings are sorted by order, then id.
+type ProjectTree struct {
+	roots []*ProjectNode
+	nodes map[string]*ProjectNode
+}
+
+//goland:noinspection GoUnusedExportedFunction
+func NewProjectTree(projects []Project) *ProjectTree {
+	t := &ProjectTree{
+		nodes: make(map[string]*ProjectNode, len(projects)),
+	}
+
+	ids, parentIds := make([]string, len(projects)), make([]string, len(projects))
+	for i, project := range projects {
+		ids[i], parentIds[i] = project.Id, project.ParentId
+	}
+
+	f := newForest(ids, parentIds, func(i, j int) bool {
+		return ordering.LessOrder(projects[i].Order, projects[i].Id, projects[j].Order, projects[j].Id)
+	})
+
+	nodes := make([]*ProjectNode, len(projects))
+	for i, project := range projects {
+		nodes[i] = &ProjectNode{Project: project, Orphan: f.orphans[i]}
+		t.nodes[project.Id] = nodes[i]
+	}
+
+	var link func(node *ProjectNode, i int, depth int)
+	link = func(node *ProjectNode, i int, depth int) {
+		node.Depth = depth
+		for _, child := range f.children[i] {
+			nodes[child].Parent = node
+			node.Children = append(node.Children, nodes[child])
+			link(nodes[child], child, depth+1)
+		}
+	}
+
+	for _, i := range f.roots {
+		t.roots = append(t.roots, nodes[i])
+		link(nodes[i], i, 0)
+	}
+
+	return t
+}
+
+// Roots returns the top level projects, including orphans.
+func (t *ProjectTree) Roots() []*ProjectNode {
+	return t.roots
+}
+
+// Node returns nil when the project is not in the tree.
+func (t *ProjectTree) Node(id string) *ProjectNode {
+	return t.nodes[id]
+}
+
+func (t *ProjectTree) Len() int {
+	return len(t.nodes)
+}
+
+// Walk visits the projects depth-first, parents before their children. Returning false skips the children.
+func (t *ProjectTree) Walk(fn func(node *ProjectNode) bool) {
+	for _, node := range t.roots {
+		node.Walk(fn)
+	}
+}
+
+// Projects returns the projects in the order of Walk.
+func (t *ProjectTree) Projects() []Project {
+	projects := make([]Project, 0, len(t.nodes))
+	t.Walk(func(node *ProjectNode) bool {
+		projects = append(projects, node.Project)
+		return true
+	})
+
+	return projects
+}
+
+// Ancestors returns the parents of a project, the closest first.
+func (t *ProjectTree) Ancestors(id string) []*ProjectNode {
+	if node := t.nodes[id]; node != nil {
+		return node.Ancestors()
+	}
+
+	return nil
+}
+
+// Descendants returns the subprojects of a project at all levels, in the order of Walk.
+func (t *ProjectTree) Descendants(id string) []*ProjectNode {
+	if node := t.nodes[id]; node != nil {
+		return node.Descendants()
+	}
+
+	return nil
+}
+
+// Orphans returns the projects whose parent is not in the tree.
+func (t *ProjectTree) Orphans() (orphans []*ProjectNode) {
+	for _, node := range t.roots {
+		if node.Orphan {
+			orphans = append(orphans, node)
+		}
+	}
+
+	return
+}
+
+// Subtree returns the tree of a project and its subprojects, with the project at the top level.
+// It returns nil when the project is not in the tree.
+func (t *ProjectTree) Subtree(id string) *ProjectTree {
+	node := t.nodes[id]
+	if node == nil {
+		return nil
+	}
+
+	projects := []Project{node.Project}
+	for _, descendant := range node.Descendants() {
+		projects = append(projects, descendant.Project)
+	}
+
+	subtree := NewProjectTree(projects)
+	subtree.nodes[id].Orphan = false
+
+	return subtree
+}
+
+// Filter returns the tree of the matching projects and their ancestors, so that matches keep their place.
+func (t *ProjectTree) Filter(match func(node *ProjectNode) bool) *ProjectTree {
+	keep := make(map[string]bool)
+	t.Walk(func(node *ProjectNode) bool {
+		if match(node) {
+			for n := node; n != nil && !keep[n.Project.Id]; n = n.Parent {
+				keep[n.Project.Id] = true
+			}
+		}
+		return true
+	})
+
+	var projects []Project
+	t.Walk(func(node *ProjectNode) bool {
+		if keep[node.Project.Id] {
+			projects = append(projects, node.Project)
+		}
+		return true
+	})
+
+	return NewProjectTree(projects)
+}
+
+func (n *ProjectNode) Walk(fn func(node *ProjectNode) bool) {
+	if !fn(n) {
+		return
+	}
+
+	for _, child := range n.Children {
+		child.Walk(fn)
+	}
+}
+
+func (n *ProjectNode) Ancestors() (ancestors []*ProjectNode) {
+	for parent := n.Parent; parent != nil; parent = parent.Parent {
+		ancestors = append(ancestors, parent)
+	}
+
+	return
+}
+
+func (n *ProjectNode) Descendants() (descendants []*ProjectNode) {
+	for _, child := range n.Children {
+		child.Walk(func(node *ProjectNode) bool {
+			descendants = append(descendants, node)
+			return true
+		})
+	}
+
+	return
+}
+
+// endregion
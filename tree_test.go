@@ -0,0 +1,259 @@
+package todoist_test
+
+import (
+	"strings"
+	"testing"
+
+	todoist "github.com/temoon/todoist-api"
+)
+
+func TestTaskTree(t *testing.T) {
+	sections := []todoist.Section{
+		{Id: "s2", Name: "Later", Order: 2},
+		{Id: "s1", Name: "Now", Order: 1},
+	}
+	tasks := []todoist.Task{
+		{Id: "20", Content: "Ship", SectionId: "s2", Order: 1},
+		{Id: "10", Content: "Report", Order: 2},
+		{Id: "9", Content: "Plan", Order: 2},
+		{Id: "3", Content: "Email", Order: 1},
+		{Id: "12", Content: "Numbers", ParentId: "10", Order: 2},
+		{Id: "11", Content: "Draft", ParentId: "10", Order: 1},
+		{Id: "13", Content: "Finance", ParentId: "12", Order: 1},
+		{Id: "21", Content: "Standup", SectionId: "s1", Order: 1},
+		{Id: "30", Content: "Stray", ParentId: "404", SectionId: "s1", Order: 2},
+	}
+
+	tree := todoist.NewTaskTree(tasks, sections)
+
+	want := []string{
+		"Email",
+		"Plan",
+		"Report",
+		"  Draft",
+		"  Numbers",
+		"    Finance",
+		"Standup",
+		"Stray (orphan)",
+		"Ship",
+	}
+	if got := describeTasks(tree); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("tree =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	if tree.Len() != len(tasks) {
+		t.Errorf("Len() = %d, want %d", tree.Len(), len(tasks))
+	}
+	if got := tree.Sections(); len(got) != 2 || got[0].Id != "s1" || got[1].Id != "s2" {
+		t.Errorf("Sections() = %+v, want s1, s2", got)
+	}
+	if got := taskNodeIds(tree.SectionRoots("")); got != "3 9 10" {
+		t.Errorf("SectionRoots(\"\") = %s, want 3 9 10", got)
+	}
+	if got := taskNodeIds(tree.SectionRoots("s1")); got != "21 30" {
+		t.Errorf("SectionRoots(s1) = %s, want 21 30", got)
+	}
+	if got := taskNodeIds(tree.Orphans()); got != "30" {
+		t.Errorf("Orphans() = %s, want 30", got)
+	}
+
+	if got := taskNodeIds(tree.Ancestors("13")); got != "12 10" {
+		t.Errorf("Ancestors(13) = %s, want 12 10", got)
+	}
+	if got := taskNodeIds(tree.Descendants("10")); got != "11 12 13" {
+		t.Errorf("Descendants(10) = %s, want 11 12 13", got)
+	}
+	if tree.Ancestors("404") != nil || tree.Descendants("404") != nil || tree.Node("404") != nil {
+		t.Errorf("unknown task has ancestors, descendants or a node")
+	}
+
+	var walked []string
+	tree.Walk(func(node *todoist.TaskNode) bool {
+		walked = append(walked, node.Task.Id)
+		return node.Task.Id != "12"
+	})
+	if got := strings.Join(walked, " "); got != "3 9 10 11 12 21 30 20" {
+		t.Errorf("Walk() = %s, want the children of 12 skipped", got)
+	}
+}
+
+func TestTaskTreeCycles(t *testing.T) {
+	tests := []struct {
+		name  string
+		tasks []todoist.Task
+		want  []string
+	}{
+		{
+			name: "own parent",
+			tasks: []todoist.Task{
+				{Id: "1", Content: "Self", ParentId: "1"},
+			},
+			want: []string{"Self (orphan)"},
+		},
+		{
+			name: "two tasks",
+			tasks: []todoist.Task{
+				{Id: "2", Content: "B", ParentId: "1", Order: 2},
+				{Id: "1", Content: "A", ParentId: "2", Order: 1},
+			},
+			want: []string{"B (orphan)", "  A"},
+		},
+		{
+			name: "cycle below a root",
+			tasks: []todoist.Task{
+				{Id: "1", Content: "Root"},
+				{Id: "2", Content: "A", ParentId: "4", Order: 1},
+				{Id: "3", Content: "B", ParentId: "2", Order: 2},
+				{Id: "4", Content: "C", ParentId: "3", Order: 3},
+				{Id: "5", Content: "Child", ParentId: "1"},
+			},
+			want: []string{"Root", "  Child", "C (orphan)", "  A", "    B"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tree := todoist.NewTaskTree(tt.tasks, nil)
+
+			if got := describeTasks(tree); strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("tree =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+			if got := len(tree.Tasks()); got != len(tt.tasks) {
+				t.Errorf("Tasks() = %d tasks, want %d", got, len(tt.tasks))
+			}
+		})
+	}
+}
+
+func TestTaskTreeSubtreeAndFilter(t *testing.T) {
+	tasks := []todoist.Task{
+		{Id: "1", Content: "Report", Order: 1},
+		{Id: "2", Content: "Draft", ParentId: "1", Order: 1},
+		{Id: "3", Content: "Numbers", ParentId: "1", Order: 2},
+		{Id: "4", Content: "Finance", ParentId: "3", Order: 1, Labels: []string{"waiting"}},
+		{Id: "5", Content: "Plan", Order: 2, Labels: []string{"waiting"}},
+		{Id: "6", Content: "Email", Order: 3},
+	}
+
+	tree := todoist.NewTaskTree(tasks, nil)
+
+	subtree := tree.Subtree("3")
+	if got := describeTasks(subtree); strings.Join(got, "|") != "Numbers|  Finance" {
+		t.Errorf("Subtree(3) = %q, want Numbers with Finance", got)
+	}
+	if node := subtree.Node("3"); node.Parent != nil || node.Depth != 0 || node.Orphan {
+		t.Errorf("Subtree(3) root = %+v, want a top level task that is not an orphan", node)
+	}
+	if tree.Subtree("404") != nil {
+		t.Errorf("Subtree(404) != nil")
+	}
+
+	filtered := tree.Filter(func(node *todoist.TaskNode) bool {
+		for _, label := range node.Task.Labels {
+			if label == "waiting" {
+				return true
+			}
+		}
+		return false
+	})
+
+	want := []string{"Report", "  Numbers", "    Finance", "Plan"}
+	if got := describeTasks(filtered); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Filter() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if tree.Len() != len(tasks) {
+		t.Errorf("Filter() changed the tree")
+	}
+}
+
+func TestProjectTree(t *testing.T) {
+	projects := []todoist.Project{
+		{Id: "10", Name: "Work", Order: 2},
+		{Id: "9", Name: "Home", Order: 2},
+		{Id: "2", Name: "Inbox", Order: 1},
+		{Id: "12", Name: "Clients", ParentId: "10", Order: 2},
+		{Id: "11", Name: "Office", ParentId: "10", Order: 1},
+		{Id: "13", Name: "Acme", ParentId: "12", Order: 1},
+		{Id: "14", Name: "Archived", ParentId: "404", Order: 1},
+		{Id: "15", Name: "Loop", ParentId: "15", Order: 3},
+	}
+
+	tree := todoist.NewProjectTree(projects)
+
+	want := []string{
+		"Inbox",
+		"Archived (orphan)",
+		"Home",
+		"Work",
+		"  Office",
+		"  Clients",
+		"    Acme",
+		"Loop (orphan)",
+	}
+	if got := describeProjects(tree); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("tree =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	if got := projectNodeIds(tree.Orphans()); got != "14 15" {
+		t.Errorf("Orphans() = %s, want 14 15", got)
+	}
+	if got := projectNodeIds(tree.Ancestors("13")); got != "12 10" {
+		t.Errorf("Ancestors(13) = %s, want 12 10", got)
+	}
+	if got := projectNodeIds(tree.Descendants("10")); got != "11 12 13" {
+		t.Errorf("Descendants(10) = %s, want 11 12 13", got)
+	}
+
+	if got := describeProjects(tree.Subtree("12")); strings.Join(got, "|") != "Clients|  Acme" {
+		t.Errorf("Subtree(12) = %q, want Clients with Acme", got)
+	}
+
+	filtered := tree.Filter(func(node *todoist.ProjectNode) bool { return node.Project.Name == "Acme" })
+	if got := describeProjects(filtered); strings.Join(got, "|") != "Work|  Clients|    Acme" {
+		t.Errorf("Filter() = %q, want Acme with its ancestors", got)
+	}
+}
+
+func describeTasks(tree *todoist.TaskTree) (lines []string) {
+	tree.Walk(func(node *todoist.TaskNode) bool {
+		line := strings.Repeat("  ", node.Depth) + node.Task.Content
+		if node.Orphan {
+			line += " (orphan)"
+		}
+		lines = append(lines, line)
+		return true
+	})
+
+	return
+}
+
+func describeProjects(tree *todoist.ProjectTree) (lines []string) {
+	tree.Walk(func(node *todoist.ProjectNode) bool {
+		line := strings.Repeat("  ", node.Depth) + node.Project.Name
+		if node.Orphan {
+			line += " (orphan)"
+		}
+		lines = append(lines, line)
+		return true
+	})
+
+	return
+}
+
+func taskNodeIds(nodes []*todoist.TaskNode) string {
+	ids := make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		ids = append(ids, node.Task.Id)
+	}
+
+	return strings.Join(ids, " ")
+}
+
+func projectNodeIds(nodes []*todoist.ProjectNode) string {
+	ids := make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		ids = append(ids, node.Project.Id)
+	}
+
+	return strings.Join(ids, " ")
+}